@@ -61,7 +61,8 @@ func runPluginCommand(command func(commandLine utils.CommandLine) error) func(co
 	}
 }
 
-func runCueCommand(command func(commandLine utils.CommandLine) error) func(context *cli.Context) error {
+// runCommand runs a command that needs neither the database nor a restart of Grafana.
+func runCommand(command func(commandLine utils.CommandLine) error) func(context *cli.Context) error {
 	return func(context *cli.Context) error {
 		return command(&utils.ContextCommandLine{Context: context})
 	}
@@ -141,7 +142,7 @@ var cueCommands = []*cli.Command{
 	{
 		Name:   "validate-schema",
 		Usage:  "validate known *.cue files in the Grafana project",
-		Action: runCueCommand(cmd.validateScuemata),
+		Action: runCommand(cmd.validateScuemata),
 		Description: `validate-schema checks that all CUE schema files are valid with respect
 to basic standards - valid CUE, valid scuemata, etc. Note that this
 command checks only paths that existed when grafana-cli was compiled,
@@ -156,7 +157,7 @@ so must be recompiled to validate newly-added CUE files.`,
 	{
 		Name:   "validate-resource",
 		Usage:  "validate resource files (e.g. dashboard JSON) against schema",
-		Action: runCueCommand(cmd.validateResources),
+		Action: runCommand(cmd.validateResources),
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:  "dashboard",
@@ -174,7 +175,7 @@ so must be recompiled to validate newly-added CUE files.`,
 		Usage: "generate TypeScript from all known CUE file types",
 		Description: `gen-ts generates TypeScript from all CUE files at
 		expected positions in the filesystem tree of a Grafana repository.`,
-		Action: runCueCommand(cmd.generateTypescript),
+		Action: runCommand(cmd.generateTypescript),
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:  "grafana-root",
@@ -184,6 +185,39 @@ so must be recompiled to validate newly-added CUE files.`,
 	},
 }
 
+var expressionCommands = []*cli.Command{
+	{
+		Name:   "eval",
+		Usage:  "evaluate expressions against data source results loaded from files",
+		Action: runCommand(cmd.evalExpressions),
+		Description: `eval runs server side expressions, such as the queries and conditions
+of an alert rule, without a running Grafana server. The results of the data
+source queries are read from a file instead of querying the data sources.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "frames",
+				Usage: "JSON file with the results of the data source queries, in the format returned by /api/ds/query",
+			},
+			&cli.StringFlag{
+				Name:  "queries",
+				Usage: "JSON file with an array of expression queries, each with a refId",
+			},
+			&cli.StringFlag{
+				Name:  "from",
+				Usage: "start of the time range of the expressions in RFC 3339 format, defaults to one hour before to",
+			},
+			&cli.StringFlag{
+				Name:  "to",
+				Usage: "end of the time range of the expressions in RFC 3339 format, defaults to now",
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "file to write the results to, instead of printing them",
+			},
+		},
+	},
+}
+
 var Commands = []*cli.Command{
 	{
 		Name:        "plugins",
@@ -200,4 +234,9 @@ var Commands = []*cli.Command{
 		Usage:       "Cue validation commands",
 		Subcommands: cueCommands,
 	},
+	{
+		Name:        "expressions",
+		Usage:       "Server side expressions commands",
+		Subcommands: expressionCommands,
+	},
 }
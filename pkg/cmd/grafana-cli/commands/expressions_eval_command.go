@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/expr"
+)
+
+// evalExpressions evaluates expression queries against data source results read
+// from a file, and writes the results of all queries as JSON.
+func (cmd Command) evalExpressions(c utils.CommandLine) error {
+	framesPath := c.String("frames")
+	if framesPath == "" {
+		return errors.New("must provide a file with the frames of the data source queries")
+	}
+	queriesPath := c.String("queries")
+	if queriesPath == "" {
+		return errors.New("must provide a file with the expression queries")
+	}
+
+	frames, err := readExpressionFrames(framesPath)
+	if err != nil {
+		return err
+	}
+
+	timeRange, err := parseExpressionTimeRange(c.String("from"), c.String("to"))
+	if err != nil {
+		return err
+	}
+	queries, err := readExpressionQueries(queriesPath, timeRange)
+	if err != nil {
+		return err
+	}
+
+	res, err := expr.Evaluate(context.Background(), frames, queries)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate expressions: %w", err)
+	}
+
+	out, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return err
+	}
+	if output := c.String("output"); output != "" {
+		// nolint:gosec
+		if err := os.WriteFile(output, out, 0644); err != nil {
+			return fmt.Errorf("failed to write results: %w", err)
+		}
+	} else {
+		logger.Info(string(out) + "\n")
+	}
+
+	refIDs := make([]string, 0, len(res.Responses))
+	for refID, dr := range res.Responses {
+		if dr.Error != nil {
+			refIDs = append(refIDs, refID)
+		}
+	}
+	if len(refIDs) > 0 {
+		sort.Strings(refIDs)
+		return fmt.Errorf("evaluation failed for queries %v", refIDs)
+	}
+	return nil
+}
+
+// readExpressionFrames reads the frames of each query from a file in the format of
+// a query response of the /api/ds/query endpoint.
+func readExpressionFrames(path string) (map[string]data.Frames, error) {
+	// nolint:gosec
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read frames: %w", err)
+	}
+	res := backend.QueryDataResponse{}
+	if err := json.Unmarshal(b, &res); err != nil {
+		return nil, fmt.Errorf("failed to parse frames in %v: %w", path, err)
+	}
+	frames := make(map[string]data.Frames, len(res.Responses))
+	for refID, dr := range res.Responses {
+		if dr.Error != nil {
+			return nil, fmt.Errorf("frames of query %v have an error: %w", refID, dr.Error)
+		}
+		frames[refID] = dr.Frames
+	}
+	return frames, nil
+}
+
+// readExpressionQueries reads a JSON array of expression query models from a file,
+// each of which must have a refId.
+func readExpressionQueries(path string, timeRange expr.TimeRange) ([]expr.Query, error) {
+	// nolint:gosec
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queries: %w", err)
+	}
+	var models []json.RawMessage
+	if err := json.Unmarshal(b, &models); err != nil {
+		return nil, fmt.Errorf("failed to parse queries in %v: %w", path, err)
+	}
+
+	queries := make([]expr.Query, 0, len(models))
+	for i, model := range models {
+		var q struct {
+			RefID string `json:"refId"`
+		}
+		if err := json.Unmarshal(model, &q); err != nil {
+			return nil, fmt.Errorf("failed to parse query %v in %v: %w", i, path, err)
+		}
+		if q.RefID == "" {
+			return nil, fmt.Errorf("query %v in %v has no refId", i, path)
+		}
+		queries = append(queries, expr.Query{
+			RefID:     q.RefID,
+			TimeRange: timeRange,
+			JSON:      model,
+		})
+	}
+	return queries, nil
+}
+
+// parseExpressionTimeRange parses the RFC 3339 time range of the expressions. It
+// defaults to the last hour.
+func parseExpressionTimeRange(from, to string) (expr.TimeRange, error) {
+	tr := expr.TimeRange{To: time.Now()}
+	if to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return tr, fmt.Errorf("invalid to time: %w", err)
+		}
+		tr.To = t
+	}
+	tr.From = tr.To.Add(-time.Hour)
+	if from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return tr, fmt.Errorf("invalid from time: %w", err)
+		}
+		tr.From = t
+	}
+	if tr.From.After(tr.To) {
+		return tr, errors.New("from time must not be after to time")
+	}
+	return tr, nil
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/commandstest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvalExpressions(t *testing.T) {
+	t.Run("results are written to the output file", func(t *testing.T) {
+		output := filepath.Join(t.TempDir(), "results.json")
+		c, err := commandstest.NewCliContext(map[string]string{
+			"frames":  "testdata/expressions/frames.json",
+			"queries": "testdata/expressions/queries.json",
+			"output":  output,
+		})
+		require.NoError(t, err)
+		require.NoError(t, cmd.evalExpressions(c))
+
+		b, err := os.ReadFile(output)
+		require.NoError(t, err)
+		res := backend.QueryDataResponse{}
+		require.NoError(t, json.Unmarshal(b, &res))
+
+		require.Len(t, res.Responses["B"].Frames, 1)
+		require.Equal(t, float64(9), *res.Responses["B"].Frames[0].Fields[0].At(0).(*float64))
+		require.Len(t, res.Responses["C"].Frames, 1)
+		require.Equal(t, float64(1), *res.Responses["C"].Frames[0].Fields[0].At(0).(*float64))
+	})
+
+	t.Run("missing files are an error", func(t *testing.T) {
+		c, err := commandstest.NewCliContext(map[string]string{
+			"frames":  "testdata/expressions/missing.json",
+			"queries": "testdata/expressions/queries.json",
+		})
+		require.NoError(t, err)
+		require.Error(t, cmd.evalExpressions(c))
+	})
+
+	t.Run("an invalid time range is an error", func(t *testing.T) {
+		c, err := commandstest.NewCliContext(map[string]string{
+			"frames":  "testdata/expressions/frames.json",
+			"queries": "testdata/expressions/queries.json",
+			"from":    "2021-10-01T10:00:00Z",
+			"to":      "2021-10-01T09:00:00Z",
+		})
+		require.NoError(t, err)
+		require.Error(t, cmd.evalExpressions(c))
+	})
+}
@@ -0,0 +1,429 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/prometheus/alertmanager/pkg/labels"
+)
+
+const (
+	// AggregateSum sums the values of each group.
+	AggregateSum = "sum"
+	// AggregateAvg averages the values of each group.
+	AggregateAvg = "avg"
+	// AggregateMin returns the smallest value of each group.
+	AggregateMin = "min"
+	// AggregateMax returns the largest value of each group.
+	AggregateMax = "max"
+	// AggregateCount counts the non-null values of each group.
+	AggregateCount = "count"
+	// AggregateTopK keeps the k largest values of each group.
+	AggregateTopK = "topk"
+	// AggregateBottomK keeps the k smallest values of each group.
+	AggregateBottomK = "bottomk"
+)
+
+// defaultAggregateRankReducer is the reducer used to rank series for topk and bottomk.
+const defaultAggregateRankReducer = "last"
+
+// AggregateCommand is an expression command that filters the numbers or series of a
+// variable by their labels, and then groups them by a set of labels to either combine
+// each group into a single value (sum, avg, min, max, count) or keep the k largest or
+// smallest values of each group (topk, bottomk).
+//
+// Series are combined point by point at each time that exists in any series of the
+// group. For topk and bottomk, series are ranked by reducing them with RankReducer.
+type AggregateCommand struct {
+	VarToAggregate string
+	// Function is the aggregation function, if empty the values are only filtered.
+	Function string
+	// By are the labels to group values by. If empty, all values are one group.
+	By []string
+	// Matchers filter the values by their labels before grouping.
+	Matchers    []*labels.Matcher
+	K           int
+	RankReducer string
+	refID       string
+}
+
+// NewAggregateCommand creates a new AggregateCommand.
+func NewAggregateCommand(refID, varToAggregate, function string, by []string, matchers []*labels.Matcher, k int, rankReducer string) (*AggregateCommand, error) {
+	switch function {
+	case "":
+		if len(matchers) == 0 {
+			return nil, fmt.Errorf("aggregate command for refId %v requires a function or label matchers", refID)
+		}
+	case AggregateSum, AggregateAvg, AggregateMin, AggregateMax, AggregateCount:
+	case AggregateTopK, AggregateBottomK:
+		if k < 1 {
+			return nil, fmt.Errorf("aggregate function '%v' for refId %v requires k to be at least 1, got %v", function, refID, k)
+		}
+		if rankReducer == "" {
+			rankReducer = defaultAggregateRankReducer
+		}
+		if !mathexp.IsValidReducer(rankReducer) {
+			return nil, fmt.Errorf("reducer '%v' to rank series for refId %v is not valid", rankReducer, refID)
+		}
+	default:
+		return nil, fmt.Errorf("'%v' is not a valid aggregate function for refId %v", function, refID)
+	}
+
+	return &AggregateCommand{
+		VarToAggregate: varToAggregate,
+		Function:       function,
+		By:             by,
+		Matchers:       matchers,
+		K:              k,
+		RankReducer:    rankReducer,
+		refID:          refID,
+	}, nil
+}
+
+// UnmarshalAggregateCommand creates a AggregateCommand from Grafana's frontend query.
+func UnmarshalAggregateCommand(rn *rawNode) (*AggregateCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, fmt.Errorf("no variable specified to aggregate for refId %v", rn.RefID)
+	}
+	varToAggregate, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected aggregate variable to be a string, got %T for refId %v", rawVar, rn.RefID)
+	}
+	varToAggregate = strings.TrimPrefix(varToAggregate, "$")
+
+	var function string
+	if rawFunction, ok := rn.Query["function"]; ok {
+		function, ok = rawFunction.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected aggregate function to be a string, got %T for refId %v", rawFunction, rn.RefID)
+		}
+	}
+
+	var by []string
+	if rawBy, ok := rn.Query["by"]; ok && rawBy != nil {
+		rawLabels, ok := rawBy.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected aggregate by to be a list of labels, got %T for refId %v", rawBy, rn.RefID)
+		}
+		for _, rawLabel := range rawLabels {
+			label, ok := rawLabel.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected aggregate by label to be a string, got %T for refId %v", rawLabel, rn.RefID)
+			}
+			by = append(by, label)
+		}
+	}
+
+	var matchers []*labels.Matcher
+	if rawMatchers, ok := rn.Query["matchers"]; ok && rawMatchers != nil {
+		matchersString, ok := rawMatchers.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected aggregate matchers to be a string, got %T for refId %v", rawMatchers, rn.RefID)
+		}
+		if matchersString != "" {
+			var err error
+			matchers, err = labels.ParseMatchers(matchersString)
+			if err != nil {
+				return nil, fmt.Errorf("invalid aggregate matchers for refId %v: %w", rn.RefID, err)
+			}
+		}
+	}
+
+	var k int
+	if rawK, ok := rn.Query["k"]; ok && rawK != nil {
+		floatK, ok := rawK.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected aggregate k to be a number, got %T for refId %v", rawK, rn.RefID)
+		}
+		k = int(floatK)
+	}
+
+	var rankReducer string
+	if rawReducer, ok := rn.Query["reducer"]; ok && rawReducer != nil {
+		rankReducer, ok = rawReducer.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected aggregate reducer to be a string, got %T for refId %v", rawReducer, rn.RefID)
+		}
+	}
+
+	return NewAggregateCommand(rn.RefID, varToAggregate, function, by, matchers, k, rankReducer)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ac *AggregateCommand) NeedsVars() []string {
+	return []string{ac.VarToAggregate}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (ac *AggregateCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{Values: mathexp.Values{}}
+	groups := ac.group(ac.filter(vars[ac.VarToAggregate].Values))
+
+	for _, g := range groups {
+		var vals mathexp.Values
+		var err error
+		switch ac.Function {
+		case "":
+			vals, err = withName(ac.refID, g.values)
+		case AggregateTopK, AggregateBottomK:
+			vals, err = ac.rank(g.values)
+			if err == nil {
+				vals, err = withName(ac.refID, vals)
+			}
+		default:
+			var val mathexp.Value
+			val, err = ac.combine(g)
+			vals = mathexp.Values{val}
+		}
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, vals...)
+	}
+	return newRes, nil
+}
+
+// withName returns copies of the values that are filtered or ranked named name, so they
+// are named after the aggregate command instead of the node they come from.
+func withName(name string, vals mathexp.Values) (mathexp.Values, error) {
+	named := make(mathexp.Values, 0, len(vals))
+	for _, val := range vals {
+		v, err := valueWithName(name, val)
+		if err != nil {
+			return nil, err
+		}
+		named = append(named, v)
+	}
+	return named, nil
+}
+
+func valueWithName(name string, val mathexp.Value) (mathexp.Value, error) {
+	labels := val.GetLabels()
+	if labels != nil {
+		labels = labels.Copy()
+	}
+	switch v := val.(type) {
+	case mathexp.Series:
+		newSeries := mathexp.NewSeries(name, labels, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			t, f := v.GetPoint(i)
+			if f != nil {
+				c := *f
+				f = &c
+			}
+			if err := newSeries.SetPoint(i, t, f); err != nil {
+				return nil, err
+			}
+		}
+		return newSeries, nil
+	case mathexp.Number:
+		n := mathexp.NewNumber(name, labels)
+		if f := v.GetFloat64Value(); f != nil {
+			c := *f
+			n.SetValue(&c)
+		}
+		return n, nil
+	case mathexp.Scalar:
+		var f *float64
+		if v := v.GetFloat64Value(); v != nil {
+			c := *v
+			f = &c
+		}
+		return mathexp.NewScalar(name, f), nil
+	default:
+		return nil, fmt.Errorf("can not aggregate type %v", val.Type())
+	}
+}
+
+// filter returns the values whose labels satisfy all matchers.
+func (ac *AggregateCommand) filter(vals mathexp.Values) mathexp.Values {
+	if len(ac.Matchers) == 0 {
+		return vals
+	}
+	filtered := mathexp.Values{}
+VALUES:
+	for _, val := range vals {
+		l := val.GetLabels()
+		for _, m := range ac.Matchers {
+			if !m.Matches(l[m.Name]) {
+				continue VALUES
+			}
+		}
+		filtered = append(filtered, val)
+	}
+	return filtered
+}
+
+// aggregateGroup is a set of values with the same values for the labels to group by.
+type aggregateGroup struct {
+	labels data.Labels
+	values mathexp.Values
+}
+
+// group groups vals by the labels in By, in the order the groups are first seen.
+func (ac *AggregateCommand) group(vals mathexp.Values) []*aggregateGroup {
+	groups := []*aggregateGroup{}
+	byKey := map[string]*aggregateGroup{}
+	for _, val := range vals {
+		var l data.Labels
+		for _, name := range ac.By {
+			if v, ok := val.GetLabels()[name]; ok {
+				if l == nil {
+					l = data.Labels{}
+				}
+				l[name] = v
+			}
+		}
+		key := l.String()
+		g, ok := byKey[key]
+		if !ok {
+			g = &aggregateGroup{labels: l}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.values = append(g.values, val)
+	}
+	return groups
+}
+
+// combine aggregates the values of a group into a number, or a series if the group
+// holds series.
+func (ac *AggregateCommand) combine(g *aggregateGroup) (mathexp.Value, error) {
+	var series []mathexp.Series
+	var floats []*float64
+	for _, val := range g.values {
+		switch v := val.(type) {
+		case mathexp.Series:
+			series = append(series, v)
+		case mathexp.Number:
+			floats = append(floats, v.GetFloat64Value())
+		case mathexp.Scalar:
+			floats = append(floats, v.GetFloat64Value())
+		default:
+			return nil, fmt.Errorf("can not aggregate type %v", val.Type())
+		}
+	}
+	if len(series) > 0 && len(floats) > 0 {
+		return nil, fmt.Errorf("can not aggregate series and numbers in the same group %v", g.labels)
+	}
+
+	if len(series) == 0 {
+		n := mathexp.NewNumber(ac.refID, g.labels)
+		n.SetValue(aggregateFloats(ac.Function, floats))
+		return n, nil
+	}
+
+	byTime := map[time.Time][]*float64{}
+	for _, s := range series {
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			byTime[t] = append(byTime[t], f)
+		}
+	}
+	times := make([]time.Time, 0, len(byTime))
+	for t := range byTime {
+		times = append(times, t)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	newSeries := mathexp.NewSeries(ac.refID, g.labels, len(times))
+	for i, t := range times {
+		if err := newSeries.SetPoint(i, t, aggregateFloats(ac.Function, byTime[t])); err != nil {
+			return nil, err
+		}
+	}
+	return newSeries, nil
+}
+
+// aggregateFloats applies an aggregate function to the non-null values of floats. If
+// there are none, the result is null, except for count which is 0.
+func aggregateFloats(function string, floats []*float64) *float64 {
+	var result float64
+	count := 0
+	for _, f := range floats {
+		if f == nil {
+			continue
+		}
+		switch {
+		case function == AggregateSum || function == AggregateAvg:
+			result += *f
+		case function == AggregateMin && (count == 0 || *f < result):
+			result = *f
+		case function == AggregateMax && (count == 0 || *f > result):
+			result = *f
+		}
+		count++
+	}
+
+	switch {
+	case function == AggregateCount:
+		result = float64(count)
+	case count == 0:
+		return nil
+	case function == AggregateAvg:
+		result /= float64(count)
+	}
+	return &result
+}
+
+// rank returns the K largest (topk) or smallest (bottomk) values. Series are ranked by
+// the result of reducing them with RankReducer. Null and NaN values are ranked last.
+func (ac *AggregateCommand) rank(vals mathexp.Values) (mathexp.Values, error) {
+	type ranked struct {
+		val  mathexp.Value
+		rank *float64
+	}
+	rs := make([]ranked, 0, len(vals))
+	for _, val := range vals {
+		r := ranked{val: val}
+		switch v := val.(type) {
+		case mathexp.Series:
+			reduced, err := v.Reduce(ac.refID, ac.RankReducer, nil)
+			if err != nil {
+				return nil, err
+			}
+			r.rank = reduced.GetFloat64Value()
+		case mathexp.Number:
+			r.rank = v.GetFloat64Value()
+		case mathexp.Scalar:
+			r.rank = v.GetFloat64Value()
+		default:
+			return nil, fmt.Errorf("can not rank type %v", val.Type())
+		}
+		if r.rank != nil && math.IsNaN(*r.rank) {
+			r.rank = nil
+		}
+		rs = append(rs, r)
+	}
+
+	sort.SliceStable(rs, func(i, j int) bool {
+		a, b := rs[i].rank, rs[j].rank
+		switch {
+		case a == nil:
+			return false
+		case b == nil:
+			return true
+		case ac.Function == AggregateTopK:
+			return *a > *b
+		}
+		return *a < *b
+	})
+
+	if len(rs) > ac.K {
+		rs = rs[:ac.K]
+	}
+	top := make(mathexp.Values, 0, len(rs))
+	for _, r := range rs {
+		top = append(top, r.val)
+	}
+	return top, nil
+}
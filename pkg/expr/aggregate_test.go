@@ -0,0 +1,160 @@
+package expr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+)
+
+func TestAggregateCommandExecute(t *testing.T) {
+	number := func(labels data.Labels, f *float64) mathexp.Number {
+		n := mathexp.NewNumber("A", labels)
+		n.SetValue(f)
+		return n
+	}
+	pods := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{
+			number(data.Labels{"cluster": "c1", "pod": "p1"}, ptr.Float64(1)),
+			number(data.Labels{"cluster": "c1", "pod": "p2"}, ptr.Float64(5)),
+			number(data.Labels{"cluster": "c2", "pod": "p3"}, ptr.Float64(3)),
+			number(data.Labels{"cluster": "c2", "pod": "p4"}, nil),
+		}},
+	}
+
+	type result struct {
+		labels data.Labels
+		value  *float64
+	}
+	var tests = []struct {
+		name     string
+		query    map[string]interface{}
+		expected []result
+	}{
+		{
+			name:  "sum by cluster",
+			query: map[string]interface{}{"expression": "A", "function": "sum", "by": []interface{}{"cluster"}},
+			expected: []result{
+				{data.Labels{"cluster": "c1"}, ptr.Float64(6)},
+				{data.Labels{"cluster": "c2"}, ptr.Float64(3)},
+			},
+		},
+		{
+			name:  "avg without grouping",
+			query: map[string]interface{}{"expression": "A", "function": "avg"},
+			expected: []result{
+				{nil, ptr.Float64(3)},
+			},
+		},
+		{
+			name:  "count by cluster ignores null values",
+			query: map[string]interface{}{"expression": "A", "function": "count", "by": []interface{}{"cluster"}},
+			expected: []result{
+				{data.Labels{"cluster": "c1"}, ptr.Float64(2)},
+				{data.Labels{"cluster": "c2"}, ptr.Float64(1)},
+			},
+		},
+		{
+			name:  "max filtered by matchers",
+			query: map[string]interface{}{"expression": "A", "function": "max", "matchers": `{pod=~"p[13]"}`},
+			expected: []result{
+				{nil, ptr.Float64(3)},
+			},
+		},
+		{
+			name:  "filter only keeps the original values",
+			query: map[string]interface{}{"expression": "A", "matchers": `{cluster="c2"}`},
+			expected: []result{
+				{data.Labels{"cluster": "c2", "pod": "p3"}, ptr.Float64(3)},
+				{data.Labels{"cluster": "c2", "pod": "p4"}, nil},
+			},
+		},
+		{
+			name:  "topk by cluster ranks null last",
+			query: map[string]interface{}{"expression": "A", "function": "topk", "k": float64(1), "by": []interface{}{"cluster"}},
+			expected: []result{
+				{data.Labels{"cluster": "c1", "pod": "p2"}, ptr.Float64(5)},
+				{data.Labels{"cluster": "c2", "pod": "p3"}, ptr.Float64(3)},
+			},
+		},
+		{
+			name:  "bottomk",
+			query: map[string]interface{}{"expression": "A", "function": "bottomk", "k": float64(2)},
+			expected: []result{
+				{data.Labels{"cluster": "c1", "pod": "p1"}, ptr.Float64(1)},
+				{data.Labels{"cluster": "c2", "pod": "p3"}, ptr.Float64(3)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := UnmarshalAggregateCommand(&rawNode{RefID: "B", Query: tt.query})
+			require.NoError(t, err)
+			res, err := cmd.Execute(context.Background(), pods)
+			require.NoError(t, err)
+
+			actual := make([]result, 0, len(res.Values))
+			for _, v := range res.Values {
+				n, ok := v.(mathexp.Number)
+				require.True(t, ok)
+				require.Equal(t, "B", n.Frame.Fields[0].Name, "results are named after the aggregate command")
+				actual = append(actual, result{n.GetLabels(), n.GetFloat64Value()})
+			}
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+	for _, v := range pods["A"].Values {
+		require.Equal(t, "A", v.(mathexp.Number).Frame.Fields[0].Name, "the input values are not changed")
+	}
+
+	t.Run("series are combined point by point", func(t *testing.T) {
+		series := func(labels data.Labels, values ...*float64) mathexp.Series {
+			s := mathexp.NewSeries("", labels, len(values))
+			for i, v := range values {
+				require.NoError(t, s.SetPoint(i, time.Unix(int64(i*10), 0), v))
+			}
+			return s
+		}
+		vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{
+			series(data.Labels{"cluster": "c1", "pod": "p1"}, ptr.Float64(1), ptr.Float64(2)),
+			series(data.Labels{"cluster": "c1", "pod": "p2"}, ptr.Float64(3), nil, ptr.Float64(4)),
+		}}}
+		cmd, err := NewAggregateCommand("B", "A", AggregateSum, []string{"cluster"}, nil, 0, "")
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+
+		s := res.Values[0].(mathexp.Series)
+		require.Equal(t, data.Labels{"cluster": "c1"}, s.GetLabels())
+		require.Equal(t, 3, s.Len())
+		for i, expected := range []*float64{ptr.Float64(4), ptr.Float64(2), ptr.Float64(4)} {
+			require.Equal(t, expected, s.GetValue(i))
+		}
+	})
+}
+
+func TestUnmarshalAggregateCommandErrors(t *testing.T) {
+	var tests = []struct {
+		name  string
+		query map[string]interface{}
+	}{
+		{"no function or matchers", map[string]interface{}{"expression": "A"}},
+		{"unknown function", map[string]interface{}{"expression": "A", "function": "median"}},
+		{"topk without k", map[string]interface{}{"expression": "A", "function": "topk"}},
+		{"topk with invalid reducer", map[string]interface{}{"expression": "A", "function": "topk", "k": float64(1), "reducer": "nope"}},
+		{"invalid matchers", map[string]interface{}{"expression": "A", "matchers": `{pod~"p1"}`}},
+		{"by is not a list", map[string]interface{}{"expression": "A", "function": "sum", "by": "cluster"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := UnmarshalAggregateCommand(&rawNode{RefID: "B", Query: tt.query})
+			require.Error(t, err)
+		})
+	}
+}
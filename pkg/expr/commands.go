@@ -3,6 +3,7 @@ package expr
 import (
 	"context"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -64,24 +65,44 @@ func (gm *MathCommand) NeedsVars() []string {
 // Execute runs the command and returns the results or an error if the command
 // failed to execute.
 func (gm *MathCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
-	return gm.Expression.Execute(gm.refID, vars)
+	res, warnings, err := gm.Expression.ExecuteWithWarnings(gm.refID, vars)
+	for _, w := range warnings {
+		addWarning(ctx, "%s", w)
+	}
+	return res, err
 }
 
 // ReduceCommand is an expression command for reduction of a timeseries such as a min, mean, or max.
 type ReduceCommand struct {
 	Reducer     string
 	VarToReduce string
+	Mapper      mathexp.ReduceMapper
 	refID       string
 }
 
-// NewReduceCommand creates a new ReduceCMD.
-func NewReduceCommand(refID, reducer, varToReduce string) *ReduceCommand {
-	// TODO: validate reducer here, before execution
+const (
+	// ReduceModeStrict propagates null and NaN values to the reduced value. It is the default mode.
+	ReduceModeStrict = ""
+	// ReduceModeDrop drops null, NaN and infinite values before reduction.
+	ReduceModeDrop = "dropNN"
+	// ReduceModeReplace replaces null, NaN and infinite values with a fixed value before reduction.
+	ReduceModeReplace = "replaceNN"
+	// ReduceModeFail fails the reduction if there are any null, NaN or infinite values.
+	ReduceModeFail = "failNN"
+)
+
+// NewReduceCommand creates a new ReduceCMD. It will return an error if reducer
+// is not a known reduction function. mapper may be nil.
+func NewReduceCommand(refID, reducer, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
+	if !mathexp.IsValidReducer(reducer) {
+		return nil, fmt.Errorf("reducer '%v' for refId %v is not a valid reducer", reducer, refID)
+	}
 	return &ReduceCommand{
 		Reducer:     reducer,
 		VarToReduce: varToReduce,
+		Mapper:      mapper,
 		refID:       refID,
-	}
+	}, nil
 }
 
 // UnmarshalReduceCommand creates a MathCMD from Grafana's frontend query.
@@ -105,7 +126,51 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 		return nil, fmt.Errorf("expected reducer to be a string, got %T for refId %v", rawReducer, rn.RefID)
 	}
 
-	return NewReduceCommand(rn.RefID, redFunc, varToReduce), nil
+	var mapper mathexp.ReduceMapper
+	if rawSettings, ok := rn.Query["settings"]; ok && rawSettings != nil {
+		settings, ok := rawSettings.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected reduce settings to be an object, got %T for refId %v", rawSettings, rn.RefID)
+		}
+		var err error
+		if mapper, err = unmarshalReduceMapper(settings, rn.RefID); err != nil {
+			return nil, err
+		}
+	}
+
+	return NewReduceCommand(rn.RefID, redFunc, varToReduce, mapper)
+}
+
+// unmarshalReduceMapper returns the ReduceMapper for the mode in the reduce settings.
+func unmarshalReduceMapper(settings map[string]interface{}, refID string) (mathexp.ReduceMapper, error) {
+	rawMode, ok := settings["mode"]
+	if !ok {
+		return nil, nil
+	}
+	mode, ok := rawMode.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected reduce mode to be a string, got %T for refId %v", rawMode, refID)
+	}
+	switch mode {
+	case ReduceModeStrict:
+		return nil, nil
+	case ReduceModeDrop:
+		return mathexp.DropNonNumber{}, nil
+	case ReduceModeFail:
+		return mathexp.FailOnNonNumber{}, nil
+	case ReduceModeReplace:
+		rawValue, ok := settings["replaceWithValue"]
+		if !ok {
+			return nil, fmt.Errorf("reduce mode %v requires replaceWithValue for refId %v", mode, refID)
+		}
+		value, ok := rawValue.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected replaceWithValue to be a number, got %T for refId %v", rawValue, refID)
+		}
+		return mathexp.ReplaceNonNumberWithValue{Value: value}, nil
+	default:
+		return nil, fmt.Errorf("reduce mode '%v' for refId %v is not a valid mode", mode, refID)
+	}
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -123,10 +188,13 @@ func (gr *ReduceCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathex
 		if !ok {
 			return newRes, fmt.Errorf("can only reduce type series, got type %v", val.Type())
 		}
-		num, err := series.Reduce(gr.refID, gr.Reducer)
+		num, err := series.Reduce(gr.refID, gr.Reducer, gr.Mapper)
 		if err != nil {
 			return newRes, err
 		}
+		if f := num.GetFloat64Value(); gr.Mapper == nil && f != nil && math.IsNaN(*f) {
+			addWarning(ctx, "series {%v} reduced to NaN, it may contain null or NaN values: set a reduce mode to drop or replace them", series.GetLabels())
+		}
 		newRes.Values = append(newRes.Values, num)
 	}
 	return newRes, nil
@@ -240,6 +308,10 @@ const (
 	TypeResample
 	// TypeClassicConditions is the CMDType for the classic condition operation.
 	TypeClassicConditions
+	// TypeThreshold is the CMDType for checking if a threshold has been crossed.
+	TypeThreshold
+	// TypeAggregate is the CMDType for filtering and aggregating values by their labels.
+	TypeAggregate
 )
 
 func (gt CommandType) String() string {
@@ -252,6 +324,10 @@ func (gt CommandType) String() string {
 		return "resample"
 	case TypeClassicConditions:
 		return "classic_conditions"
+	case TypeThreshold:
+		return "threshold"
+	case TypeAggregate:
+		return "aggregate"
 	default:
 		return "unknown"
 	}
@@ -268,6 +344,10 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeResample, nil
 	case "classic_conditions":
 		return TypeClassicConditions, nil
+	case "threshold":
+		return TypeThreshold, nil
+	case "aggregate":
+		return TypeAggregate, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
@@ -0,0 +1,61 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalReduceCommand(t *testing.T) {
+	var tests = []struct {
+		name     string
+		query    map[string]interface{}
+		errIs    require.ErrorAssertionFunc
+		expected mathexp.ReduceMapper
+	}{
+		{
+			name:  "no settings",
+			query: map[string]interface{}{"expression": "$A", "reducer": "p95"},
+			errIs: require.NoError,
+		},
+		{
+			name:     "drop mode",
+			query:    map[string]interface{}{"expression": "$A", "reducer": "mean", "settings": map[string]interface{}{"mode": "dropNN"}},
+			errIs:    require.NoError,
+			expected: mathexp.DropNonNumber{},
+		},
+		{
+			name:     "replace mode",
+			query:    map[string]interface{}{"expression": "$A", "reducer": "mean", "settings": map[string]interface{}{"mode": "replaceNN", "replaceWithValue": 1.5}},
+			errIs:    require.NoError,
+			expected: mathexp.ReplaceNonNumberWithValue{Value: 1.5},
+		},
+		{
+			name:  "replace mode without value",
+			query: map[string]interface{}{"expression": "$A", "reducer": "mean", "settings": map[string]interface{}{"mode": "replaceNN"}},
+			errIs: require.Error,
+		},
+		{
+			name:  "unknown mode",
+			query: map[string]interface{}{"expression": "$A", "reducer": "mean", "settings": map[string]interface{}{"mode": "ignore"}},
+			errIs: require.Error,
+		},
+		{
+			name:  "unknown reducer",
+			query: map[string]interface{}{"expression": "$A", "reducer": "avg"},
+			errIs: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := UnmarshalReduceCommand(&rawNode{RefID: "B", Query: tt.query})
+			tt.errIs(t, err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, "A", cmd.VarToReduce)
+			require.Equal(t, tt.expected, cmd.Mapper)
+		})
+	}
+}
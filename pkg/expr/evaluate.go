@@ -0,0 +1,75 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// prefetchedDatasourceUID is the data source uid of the nodes built for the frames given to Evaluate.
+const prefetchedDatasourceUID = "__prefetched__"
+
+// Evaluate executes expression queries against data source results that were fetched
+// beforehand, and returns the results of all queries. It does not query any data source
+// or use any other service, so it can be used without a running server.
+//
+// frames holds the frames returned by each data source query, by the query's RefID.
+// Each of the expressions must be an expression command query; a query without a data
+// source uid is treated as one.
+func Evaluate(ctx context.Context, frames map[string]data.Frames, expressions []Query) (*backend.QueryDataResponse, error) {
+	req := &Request{}
+	exprRefIDs := make(map[string]struct{}, len(expressions))
+	for _, q := range expressions {
+		if q.DatasourceUID == "" {
+			q.DatasourceUID = DatasourceUID
+		}
+		if q.DatasourceUID != DatasourceUID {
+			return nil, fmt.Errorf("query %v is not an expression, the results of data source queries must be given as frames", q.RefID)
+		}
+		exprRefIDs[q.RefID] = struct{}{}
+		req.Queries = append(req.Queries, q)
+	}
+
+	refIDs := make([]string, 0, len(frames))
+	for refID := range frames {
+		if _, ok := exprRefIDs[refID]; !ok {
+			refIDs = append(refIDs, refID)
+		}
+	}
+	sort.Strings(refIDs)
+	for _, refID := range refIDs {
+		req.Queries = append(req.Queries, Query{
+			RefID:         refID,
+			DatasourceUID: prefetchedDatasourceUID,
+			JSON:          json.RawMessage(`{}`),
+		})
+	}
+
+	s := &Service{
+		Cfg:        &setting.Cfg{ExpressionsEnabled: true},
+		prefetched: frames,
+	}
+	pipeline, err := s.BuildPipeline(req)
+	if err != nil {
+		return nil, err
+	}
+	return s.ExecutePipeline(ctx, pipeline)
+}
+
+// prefetchedData returns the prefetched frames of the single query in req as its response.
+func (s *Service) prefetchedData(req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	if len(req.Queries) != 1 {
+		return nil, fmt.Errorf("expected a single query, got %v", len(req.Queries))
+	}
+	refID := req.Queries[0].RefID
+	frames, ok := s.prefetched[refID]
+	if !ok {
+		return nil, fmt.Errorf("no frames for query %v", refID)
+	}
+	return cachedResponse(refID, backend.DataResponse{Frames: frames}), nil
+}
@@ -0,0 +1,51 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+)
+
+func TestEvaluate(t *testing.T) {
+	frames := map[string]data.Frames{
+		"A": {data.NewFrame("",
+			data.NewField("time", nil, []time.Time{time.Unix(1, 0), time.Unix(2, 0)}),
+			data.NewField("value", data.Labels{"host": "a"}, []*float64{ptr.Float64(2), ptr.Float64(4)}),
+		)},
+	}
+
+	t.Run("expressions are executed against the given frames", func(t *testing.T) {
+		res, err := Evaluate(context.Background(), frames, []Query{
+			{RefID: "B", JSON: json.RawMessage(`{"type": "reduce", "expression": "A", "reducer": "mean"}`)},
+			{RefID: "C", JSON: json.RawMessage(`{"type": "math", "expression": "$B * 10"}`)},
+		})
+		require.NoError(t, err)
+
+		require.Contains(t, res.Responses, "A")
+		require.Contains(t, res.Responses, "B")
+		c := res.Responses["C"]
+		require.NoError(t, c.Error)
+		require.Len(t, c.Frames, 1)
+		require.Equal(t, data.Labels{"host": "a"}, c.Frames[0].Fields[0].Labels)
+		require.Equal(t, ptr.Float64(30), c.Frames[0].Fields[0].At(0))
+	})
+
+	t.Run("data source queries are rejected", func(t *testing.T) {
+		_, err := Evaluate(context.Background(), frames, []Query{
+			{RefID: "B", DatasourceUID: "prometheus", JSON: json.RawMessage(`{}`)},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("missing frames fail the pipeline", func(t *testing.T) {
+		_, err := Evaluate(context.Background(), frames, []Query{
+			{RefID: "B", JSON: json.RawMessage(`{"type": "math", "expression": "$X + 1"}`)},
+		})
+		require.Error(t, err)
+	})
+}
@@ -45,6 +45,21 @@ type Node interface {
 // DataPipeline is an ordered set of nodes returned from DPGraph processing.
 type DataPipeline []Node
 
+// NodeError is returned when a node of a DataPipeline fails to execute. It keeps the
+// RefID of the node so that callers can tell which query or expression failed.
+type NodeError struct {
+	RefID string
+	Err   error
+}
+
+func (e *NodeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *NodeError) Unwrap() error {
+	return e.Err
+}
+
 // execute runs all the command/datasource requests in the pipeline return a
 // map of the refId of the of each command
 func (dp *DataPipeline) execute(c context.Context, s *Service) (mathexp.Vars, error) {
@@ -52,7 +67,7 @@ func (dp *DataPipeline) execute(c context.Context, s *Service) (mathexp.Vars, er
 	for _, node := range *dp {
 		res, err := node.Execute(c, vars, s)
 		if err != nil {
-			return nil, err
+			return nil, &NodeError{RefID: node.RefID(), Err: err}
 		}
 
 		vars[node.RefID()] = res
@@ -0,0 +1,210 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// joined is a set of values, one from each function argument, that were paired by their labels.
+type joined struct {
+	labels data.Labels
+	vals   []Value
+}
+
+// joinArgs pairs the values of all args by their labels, with the same semantics as
+// the union of the two sides of a binary operation.
+func joinArgs(args []Results) []joined {
+	if len(args) == 0 {
+		return nil
+	}
+	joins := make([]joined, 0, len(args[0].Values))
+	for _, val := range args[0].Values {
+		joins = append(joins, joined{labels: val.GetLabels(), vals: []Value{val}})
+	}
+
+	for _, arg := range args[1:] {
+		// stand-ins for the values joined so far, so they can be paired with union
+		standIns := Results{Values: make(Values, 0, len(joins))}
+		byFrame := make(map[*data.Frame]int, len(joins))
+		for i, j := range joins {
+			n := NewNumber("", j.labels)
+			byFrame[n.Frame] = i
+			standIns.Values = append(standIns.Values, n)
+		}
+
+		newJoins := []joined{}
+		for _, u := range union(standIns, arg) {
+			prev := joins[byFrame[u.A.AsDataFrame()]]
+			vals := make([]Value, len(prev.vals), len(prev.vals)+1)
+			copy(vals, prev.vals)
+			newJoins = append(newJoins, joined{labels: u.Labels, vals: append(vals, u.B)})
+		}
+		joins = newJoins
+	}
+	return joins
+}
+
+// perJoined calls valuesF with the values of each set of joined args and returns the results.
+// If any of the values is a series, the result is a series with a point for each time that
+// exists in all series. Otherwise the result is a number, or a scalar if all values are scalars.
+func perJoined(e *State, name string, args []Results, valuesF func(vals []*float64) *float64) (Results, error) {
+	newRes := Results{Values: Values{}}
+	for _, j := range joinArgs(args) {
+		var series []Series
+		var hasNumber bool
+		for _, val := range j.vals {
+			switch v := val.(type) {
+			case Series:
+				series = append(series, v)
+			case Number:
+				hasNumber = true
+			case Scalar:
+			default:
+				return newRes, fmt.Errorf("%s can not be applied to type %v", name, val.Type())
+			}
+		}
+
+		if len(series) == 0 {
+			vals := make([]*float64, len(j.vals))
+			for i, val := range j.vals {
+				switch v := val.(type) {
+				case Number:
+					vals[i] = v.GetFloat64Value()
+				case Scalar:
+					vals[i] = v.GetFloat64Value()
+				}
+			}
+			if hasNumber {
+				n := NewNumber(e.RefID, j.labels)
+				n.SetValue(copyFloat(valuesF(vals)))
+				newRes.Values = append(newRes.Values, n)
+				continue
+			}
+			newRes.Values = append(newRes.Values, NewScalar(e.RefID, copyFloat(valuesF(vals))))
+			continue
+		}
+
+		newSeries, err := perJoinedSeries(e, j, series, valuesF)
+		if err != nil {
+			return newRes, err
+		}
+		if newSeries.Len() < series[0].Len() {
+			e.warnf("points of %s arguments with timestamps that do not exist in all series were dropped", name)
+		}
+		newRes.Values = append(newRes.Values, newSeries)
+	}
+	return newRes, nil
+}
+
+// perJoinedSeries returns a series with a point for each time of the first series in
+// series that exists in all of them.
+func perJoinedSeries(e *State, j joined, series []Series, valuesF func(vals []*float64) *float64) (Series, error) {
+	byTime := make([]map[time.Time]*float64, len(series))
+	for i, s := range series {
+		byTime[i] = make(map[time.Time]*float64, s.Len())
+		for p := 0; p < s.Len(); p++ {
+			t, f := s.GetPoint(p)
+			byTime[i][t] = f
+		}
+	}
+
+	newSeries := NewSeries(e.RefID, j.labels, 0)
+	vals := make([]*float64, len(j.vals))
+POINTS:
+	for p := 0; p < series[0].Len(); p++ {
+		t := series[0].GetTime(p)
+		seriesIdx := 0
+		for i, val := range j.vals {
+			switch v := val.(type) {
+			case Series:
+				f, ok := byTime[seriesIdx][t]
+				if !ok {
+					continue POINTS
+				}
+				vals[i] = f
+				seriesIdx++
+			case Number:
+				vals[i] = v.GetFloat64Value()
+			case Scalar:
+				vals[i] = v.GetFloat64Value()
+			}
+		}
+		if err := newSeries.AppendPoint(newSeries.Len(), t, copyFloat(valuesF(vals))); err != nil {
+			return newSeries, err
+		}
+	}
+	return newSeries, nil
+}
+
+// copyFloat returns a pointer to a copy of the value of f, or nil if f is nil.
+func copyFloat(f *float64) *float64 {
+	if f == nil {
+		return nil
+	}
+	c := *f
+	return &c
+}
+
+// boolToFloatPointer returns a pointer to 1 if b is true and to 0 otherwise.
+func boolToFloatPointer(b bool) *float64 {
+	f := 0.0
+	if b {
+		f = 1
+	}
+	return &f
+}
+
+// ifFunc returns a when cond is non-zero and b otherwise. If cond is null or NaN, so is the result.
+func ifFunc(e *State, cond, a, b Results) (Results, error) {
+	return perJoined(e, "if", []Results{cond, a, b}, func(vals []*float64) *float64 {
+		switch {
+		case vals[0] == nil:
+			return nil
+		case math.IsNaN(*vals[0]):
+			return vals[0]
+		case *vals[0] != 0:
+			return vals[1]
+		}
+		return vals[2]
+	})
+}
+
+// isNull returns 1 for each null value and 0 otherwise.
+func isNull(e *State, varSet Results) (Results, error) {
+	return perJoined(e, "is_null", []Results{varSet}, func(vals []*float64) *float64 {
+		return boolToFloatPointer(vals[0] == nil)
+	})
+}
+
+// isNaN returns 1 for each NaN value and 0 otherwise, null values are not NaN.
+func isNaN(e *State, varSet Results) (Results, error) {
+	return perJoined(e, "is_nan", []Results{varSet}, func(vals []*float64) *float64 {
+		return boolToFloatPointer(vals[0] != nil && math.IsNaN(*vals[0]))
+	})
+}
+
+// coalesce returns the first of its arguments that is neither null nor NaN. If there
+// is none, the result is null.
+func coalesce(e *State, first Results, rest ...Results) (Results, error) {
+	return perJoined(e, "coalesce", append([]Results{first}, rest...), func(vals []*float64) *float64 {
+		for _, f := range vals {
+			if f != nil && !math.IsNaN(*f) {
+				return f
+			}
+		}
+		return nil
+	})
+}
+
+// replaceNaN returns the value of x, or v where x is NaN.
+func replaceNaN(e *State, x, v Results) (Results, error) {
+	return perJoined(e, "replace_nan", []Results{x, v}, func(vals []*float64) *float64 {
+		if vals[0] != nil && math.IsNaN(*vals[0]) {
+			return vals[1]
+		}
+		return vals[0]
+	})
+}
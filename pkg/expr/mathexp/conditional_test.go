@@ -0,0 +1,198 @@
+package mathexp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+var sparseVars = Vars{
+	"A": Results{
+		[]Value{
+			makeNumber("", data.Labels{"host": "a"}, float64Pointer(1)),
+			makeNumber("", data.Labels{"host": "b"}, nil),
+			makeNumber("", data.Labels{"host": "c"}, NaN),
+		},
+	},
+	"B": Results{
+		[]Value{
+			makeNumber("", data.Labels{"host": "a"}, float64Pointer(10)),
+			makeNumber("", data.Labels{"host": "b"}, float64Pointer(20)),
+			makeNumber("", data.Labels{"host": "c"}, float64Pointer(30)),
+		},
+	},
+	"S": Results{
+		[]Value{
+			makeSeries("", data.Labels{"host": "a"},
+				tp{time.Unix(5, 0), float64Pointer(2)},
+				tp{time.Unix(10, 0), nil},
+			),
+		},
+	},
+}
+
+func TestConditionalFunctions(t *testing.T) {
+	var tests = []struct {
+		name    string
+		expr    string
+		errIs   require.ErrorAssertionFunc
+		results Results
+	}{
+		{
+			name:  "if on numbers",
+			expr:  "if($A > 0, $A, $B)",
+			errIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(1)),
+				makeNumber("", data.Labels{"host": "b"}, nil),
+				makeNumber("", data.Labels{"host": "c"}, NaN),
+			}},
+		},
+		{
+			name:    "if on scalars",
+			expr:    "if(0, 1, 2)",
+			errIs:   require.NoError,
+			results: Results{[]Value{NewScalar("", float64Pointer(2))}},
+		},
+		{
+			name:  "is_null",
+			expr:  "is_null($A)",
+			errIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(0)),
+				makeNumber("", data.Labels{"host": "b"}, float64Pointer(1)),
+				makeNumber("", data.Labels{"host": "c"}, float64Pointer(0)),
+			}},
+		},
+		{
+			name:  "is_nan",
+			expr:  "is_nan($A)",
+			errIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(0)),
+				makeNumber("", data.Labels{"host": "b"}, float64Pointer(0)),
+				makeNumber("", data.Labels{"host": "c"}, float64Pointer(1)),
+			}},
+		},
+		{
+			name:  "coalesce with a number set and a scalar",
+			expr:  "coalesce($A, 0)",
+			errIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(1)),
+				makeNumber("", data.Labels{"host": "b"}, float64Pointer(0)),
+				makeNumber("", data.Labels{"host": "c"}, float64Pointer(0)),
+			}},
+		},
+		{
+			name:  "coalesce with more than two arguments joins by labels",
+			expr:  "coalesce($A, null(), $B)",
+			errIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(1)),
+				makeNumber("", data.Labels{"host": "b"}, float64Pointer(20)),
+				makeNumber("", data.Labels{"host": "c"}, float64Pointer(30)),
+			}},
+		},
+		{
+			name:  "replace_nan leaves null values",
+			expr:  "replace_nan($A, -1)",
+			errIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(1)),
+				makeNumber("", data.Labels{"host": "b"}, nil),
+				makeNumber("", data.Labels{"host": "c"}, float64Pointer(-1)),
+			}},
+		},
+		{
+			name:  "coalesce on a series returns a series",
+			expr:  "coalesce($S, $B)",
+			errIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(5, 0), float64Pointer(2)},
+					tp{time.Unix(10, 0), float64Pointer(10)},
+				),
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			res, err := e.Execute("", sparseVars)
+			tt.errIs(t, err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, len(tt.results.Values), len(res.Values))
+			for i := range tt.results.Values {
+				require.Equal(t, tt.results.Values[i].GetLabels(), res.Values[i].GetLabels())
+				require.Equal(t, tt.results.Values[i].Type(), res.Values[i].Type())
+				requireSameValues(t, tt.results.Values[i], res.Values[i])
+			}
+		})
+	}
+}
+
+// requireSameValues compares the values of two numbers, scalars or series, with NaN equal to NaN.
+func requireSameValues(t *testing.T, expected, actual Value) {
+	t.Helper()
+	floats := func(v Value) []*float64 {
+		switch v := v.(type) {
+		case Number:
+			return []*float64{v.GetFloat64Value()}
+		case Scalar:
+			return []*float64{v.GetFloat64Value()}
+		case Series:
+			fs := make([]*float64, v.Len())
+			for i := range fs {
+				fs[i] = v.GetValue(i)
+			}
+			return fs
+		}
+		return nil
+	}
+	e, a := floats(expected), floats(actual)
+	require.Len(t, a, len(e))
+	for i := range e {
+		switch {
+		case e[i] == nil:
+			require.Nil(t, a[i])
+		case math.IsNaN(*e[i]):
+			require.NotNil(t, a[i])
+			require.True(t, math.IsNaN(*a[i]))
+		default:
+			require.NotNil(t, a[i])
+			require.Equal(t, *e[i], *a[i])
+		}
+	}
+}
+
+func TestConditionalFunctionsParse(t *testing.T) {
+	var tests = []struct {
+		expr       string
+		errIs      require.ErrorAssertionFunc
+		returnType string
+	}{
+		{"coalesce($A, $B, 1, 2)", require.NoError, "seriesSet"},
+		{"coalesce(1, 2)", require.NoError, "scalar"},
+		{"coalesce($A)", require.Error, ""},
+		{"replace_nan(1, 2, 3)", require.Error, ""},
+		{"if(1, 2, $A)", require.NoError, "seriesSet"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.errIs(t, err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, tt.returnType, e.Tree.Root.Return().String())
+		})
+	}
+}
@@ -24,6 +24,9 @@ type State struct {
 	//  - Unions (How many result A and many Result B in case A + B are joined)
 	//  - NaN/Null behavior
 	RefID string
+	// Warnings are messages about data that was dropped, coerced or turned
+	// into NaN/null while executing the expression.
+	Warnings []string
 }
 
 // Vars holds the results of datasource queries or other expression commands.
@@ -44,12 +47,31 @@ func New(expr string, funcs ...map[string]parse.Func) (*Expr, error) {
 
 // Execute applies a parse expression to the context and executes it
 func (e *Expr) Execute(refID string, vars Vars) (r Results, err error) {
+	r, _, err = e.ExecuteWithWarnings(refID, vars)
+	return
+}
+
+// ExecuteWithWarnings is like Execute but also returns warnings about values
+// that were dropped, had their labels removed, or propagated null or NaN values.
+func (e *Expr) ExecuteWithWarnings(refID string, vars Vars) (Results, []string, error) {
 	s := &State{
 		Expr:  e,
 		Vars:  vars,
 		RefID: refID,
 	}
-	return e.executeState(s)
+	r, err := e.executeState(s)
+	return r, s.Warnings, err
+}
+
+// warnf adds a warning to the state unless an identical one already exists.
+func (e *State) warnf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	for _, w := range e.Warnings {
+		if w == msg {
+			return
+		}
+	}
+	e.Warnings = append(e.Warnings, msg)
 }
 
 func (e *Expr) executeState(s *State) (r Results, err error) {
@@ -252,7 +274,21 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	if err != nil {
 		return res, err
 	}
-	unions := union(ar, br)
+	var unions []*Union
+	if node.Matching != nil {
+		unions, err = matchUnion(ar, br, node.Matching)
+		if err != nil {
+			return res, err
+		}
+	} else {
+		unions = union(ar, br)
+	}
+	// without on() or ignoring(), values that do not match result in no data as before
+	if node.Matching != nil && len(unions) == 0 && len(ar.Values) > 0 && len(br.Values) > 0 {
+		return res, fmt.Errorf("no labels matched between the left side %v and the right side %v of the %q operation: left side has %v, right side has %v",
+			node.Args[0], node.Args[1], node.OpStr, labelSetsString(ar), labelSetsString(br))
+	}
+	e.warnUnions(node, ar, br, unions)
 	for _, uni := range unions {
 		var value Value
 		switch at := uni.A.(type) {
@@ -296,6 +332,9 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 			// case Series op Series
 			case Series:
 				value, err = e.biSeriesSeries(uni.Labels, node.OpStr, at, bt)
+				if err == nil && (value.(Series).Len() < at.Len() || value.(Series).Len() < bt.Len()) {
+					e.warnf("points of %v and %v with timestamps that do not exist on both sides were dropped", node.Args[0], node.Args[1])
+				}
 			default:
 				return res, fmt.Errorf("not implemented: binary %v on %T and %T", node.OpStr, uni.A, uni.B)
 			}
@@ -324,6 +363,62 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	return res, nil
 }
 
+// warnUnions adds warnings for values of a binary operation that were not part
+// of any union, for labels that were removed to combine values, and for null
+// or NaN values that will propagate to the result.
+func (e *State) warnUnions(node *parse.BinaryNode, ar, br Results, unions []*Union) {
+	usedA := make(map[*data.Frame]struct{}, len(unions))
+	usedB := make(map[*data.Frame]struct{}, len(unions))
+	for _, u := range unions {
+		usedA[u.A.AsDataFrame()] = struct{}{}
+		usedB[u.B.AsDataFrame()] = struct{}{}
+		if u.Labels == nil && (len(u.A.GetLabels()) > 0 || len(u.B.GetLabels()) > 0) {
+			e.warnf("labels of %v {%v} and %v {%v} do not match and were dropped to combine them with %q",
+				node.Args[0], u.A.GetLabels(), node.Args[1], u.B.GetLabels(), node.OpStr)
+		}
+	}
+	warnDropped := func(arg parse.Node, res Results, used map[*data.Frame]struct{}) {
+		dropped := 0
+		for _, v := range res.Values {
+			if _, ok := used[v.AsDataFrame()]; !ok {
+				dropped++
+			}
+		}
+		if dropped > 0 {
+			e.warnf("%d of %d values of %v had no matching labels on the other side of %q and were dropped", dropped, len(res.Values), arg, node.OpStr)
+		}
+	}
+	warnDropped(node.Args[0], ar, usedA)
+	warnDropped(node.Args[1], br, usedB)
+
+	for i, res := range []Results{ar, br} {
+		for _, v := range res.Values {
+			if hasNonNumber(v) {
+				e.warnf("%v contains null or NaN values which propagate to the result of %q", node.Args[i], node.OpStr)
+				break
+			}
+		}
+	}
+}
+
+// hasNonNumber returns true if any value held by v is null or NaN.
+func hasNonNumber(v Value) bool {
+	isNN := func(f *float64) bool { return f == nil || math.IsNaN(*f) }
+	switch t := v.(type) {
+	case Scalar:
+		return isNN(t.GetFloat64Value())
+	case Number:
+		return isNN(t.GetFloat64Value())
+	case Series:
+		for i := 0; i < t.Len(); i++ {
+			if isNN(t.GetValue(i)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // binaryOp performs a binary operations (e.g. A+B or A>B) on two
 // float values
 // nolint:gocyclo
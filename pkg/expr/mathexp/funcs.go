@@ -1,6 +1,7 @@
 package mathexp
 
 import (
+	"fmt"
 	"math"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
@@ -29,6 +30,69 @@ var builtins = map[string]parse.Func{
 		Return: parse.TypeScalar,
 		F:      null,
 	},
+	"clamp": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clamp,
+	},
+	"rate": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      rate,
+	},
+	"delta": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      delta,
+	},
+	"increase": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      increase,
+	},
+	"cumsum": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      cumsum,
+	},
+	"moving_avg": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      movingAvg,
+		Check:  checkDurationArg(1),
+	},
+	"if": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeVariantSet, parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             ifFunc,
+	},
+	"is_null": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             isNull,
+	},
+	"is_nan": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             isNaN,
+	},
+	"coalesce": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeVariantSet},
+		VariantReturn: true,
+		Variadic:      true,
+		F:             coalesce,
+	},
+	"replace_nan": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             replaceNaN,
+	},
+	"time_shift": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      timeShift,
+		Check:  checkDurationArg(1),
+	},
 }
 
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
@@ -57,6 +121,40 @@ func log(e *State, varSet Results) (Results, error) {
 	return newRes, nil
 }
 
+// clamp limits each value in NumberSet, SeriesSet, or Scalar to the range [min, max]
+func clamp(e *State, varSet Results, minRes, maxRes Results) (Results, error) {
+	newRes := Results{}
+	lower, upper := scalarArg(minRes), scalarArg(maxRes)
+	if lower == nil || upper == nil {
+		return newRes, fmt.Errorf("clamp requires non-null min and max values")
+	}
+	if *lower > *upper {
+		return newRes, fmt.Errorf("clamp min %v must not be greater than max %v", *lower, *upper)
+	}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, func(x float64) float64 {
+			return math.Max(*lower, math.Min(*upper, x))
+		})
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// scalarArg returns the value of a scalar function argument.
+func scalarArg(res Results) *float64 {
+	if len(res.Values) != 1 {
+		return nil
+	}
+	s, ok := res.Values[0].(Scalar)
+	if !ok {
+		return nil
+	}
+	return s.GetFloat64Value()
+}
+
 // nan returns a scalar nan value
 func nan(e *State) Results {
 	aNaN := math.NaN()
@@ -0,0 +1,133 @@
+package mathexp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// matchUnion creates Union objects by pairing the values of aResults and bResults
+// whose matching labels (as selected by on() or ignoring()) are equal.
+// Scalars have no labels, so if either side holds a Scalar the default union is used.
+func matchUnion(aResults, bResults Results, m *parse.VectorMatching) ([]*Union, error) {
+	if hasScalar(aResults) || hasScalar(bResults) {
+		return union(aResults, bResults), nil
+	}
+
+	if m.Card != parse.CardManyToOne {
+		if err := checkUniqueSignatures(aResults, m, "left"); err != nil {
+			return nil, err
+		}
+	}
+	if m.Card != parse.CardOneToMany {
+		if err := checkUniqueSignatures(bResults, m, "right"); err != nil {
+			return nil, err
+		}
+	}
+
+	bSigs := make(map[string][]Value)
+	for _, b := range bResults.Values {
+		sig := matchingLabels(b.GetLabels(), m).String()
+		bSigs[sig] = append(bSigs[sig], b)
+	}
+
+	unions := []*Union{}
+	for _, a := range aResults.Values {
+		sig := matchingLabels(a.GetLabels(), m).String()
+		for _, b := range bSigs[sig] {
+			unions = append(unions, &Union{
+				Labels: resultLabels(a.GetLabels(), b.GetLabels(), m),
+				A:      a,
+				B:      b,
+			})
+		}
+	}
+	return unions, nil
+}
+
+// checkUniqueSignatures returns an error if two values in res have the same
+// matching labels, which is only allowed on the "many" side of a group_left or group_right.
+func checkUniqueSignatures(res Results, m *parse.VectorMatching, side string) error {
+	seen := make(map[string]struct{}, len(res.Values))
+	for _, v := range res.Values {
+		sig := matchingLabels(v.GetLabels(), m).String()
+		if _, ok := seen[sig]; ok {
+			return fmt.Errorf("found duplicate values for the match group {%s} on the %s hand-side of the operation, many-to-many matching is not allowed: use group_left or group_right", sig, side)
+		}
+		seen[sig] = struct{}{}
+	}
+	return nil
+}
+
+// matchingLabels returns the subset of labels that is used to pair values.
+func matchingLabels(labels data.Labels, m *parse.VectorMatching) data.Labels {
+	res := data.Labels{}
+	if m.On {
+		for _, name := range m.MatchingLabels {
+			if v, ok := labels[name]; ok {
+				res[name] = v
+			}
+		}
+		return res
+	}
+	for k, v := range labels {
+		if !util.StringsContain(m.MatchingLabels, k) {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+// resultLabels returns the labels of the value produced by a binary operation
+// on a and b. For one-to-one matching these are the matching labels, otherwise
+// they are the labels of the "many" side plus the included labels from the "one" side.
+func resultLabels(aLabels, bLabels data.Labels, m *parse.VectorMatching) data.Labels {
+	var many, one data.Labels
+	switch m.Card {
+	case parse.CardManyToOne:
+		many, one = aLabels, bLabels
+	case parse.CardOneToMany:
+		many, one = bLabels, aLabels
+	default:
+		return matchingLabels(aLabels, m)
+	}
+	res := data.Labels{}
+	for k, v := range many {
+		res[k] = v
+	}
+	for _, name := range m.Include {
+		if v, ok := one[name]; ok {
+			res[name] = v
+		} else {
+			delete(res, name)
+		}
+	}
+	return res
+}
+
+func hasScalar(res Results) bool {
+	for _, v := range res.Values {
+		if _, ok := v.(Scalar); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// labelSetsString returns a short description of the label sets in res for
+// use in error messages.
+func labelSetsString(res Results) string {
+	const maxSets = 3
+	sets := make([]string, 0, maxSets)
+	for i, v := range res.Values {
+		if i == maxSets {
+			sets = append(sets, fmt.Sprintf("and %d more", len(res.Values)-maxSets))
+			break
+		}
+		sets = append(sets, "{"+v.GetLabels().String()+"}")
+	}
+	return strings.Join(sets, ", ")
+}
@@ -0,0 +1,152 @@
+package mathexp
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+var errorsAndTotals = Vars{
+	"A": Results{
+		[]Value{
+			makeNumber("errors", data.Labels{"service": "api", "instance": "i-1"}, float64Pointer(2)),
+			makeNumber("errors", data.Labels{"service": "web", "instance": "i-2"}, float64Pointer(3)),
+		},
+	},
+	"B": Results{
+		[]Value{
+			makeNumber("total", data.Labels{"service": "api", "pod": "p-1"}, float64Pointer(10)),
+			makeNumber("total", data.Labels{"service": "web", "pod": "p-2"}, float64Pointer(30)),
+		},
+	},
+}
+
+var instancesAndLimits = Vars{
+	"A": Results{
+		[]Value{
+			makeNumber("usage", data.Labels{"cluster": "c1", "instance": "i-1"}, float64Pointer(4)),
+			makeNumber("usage", data.Labels{"cluster": "c1", "instance": "i-2"}, float64Pointer(6)),
+		},
+	},
+	"B": Results{
+		[]Value{
+			makeNumber("limit", data.Labels{"cluster": "c1", "tier": "gold"}, float64Pointer(8)),
+		},
+	},
+}
+
+func TestVectorMatching(t *testing.T) {
+	var tests = []struct {
+		name    string
+		expr    string
+		vars    Vars
+		errIs   require.ErrorAssertionFunc
+		results Results
+	}{
+		{
+			name:    "unrelated extra labels result in no data without matching",
+			expr:    "$A / $B",
+			vars:    errorsAndTotals,
+			errIs:   require.NoError,
+			results: Results{[]Value{}},
+		},
+		{
+			name:  "on() pairs values by the given labels only",
+			expr:  "$A / on(service) $B",
+			vars:  errorsAndTotals,
+			errIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"service": "api"}, float64Pointer(0.2)),
+					makeNumber("", data.Labels{"service": "web"}, float64Pointer(0.1)),
+				},
+			},
+		},
+		{
+			name:  "ignoring() pairs values by all other labels",
+			expr:  "$A / ignoring(instance, pod) $B",
+			vars:  errorsAndTotals,
+			errIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"service": "api"}, float64Pointer(0.2)),
+					makeNumber("", data.Labels{"service": "web"}, float64Pointer(0.1)),
+				},
+			},
+		},
+		{
+			name:  "one-to-one matching with duplicates on one side is an error",
+			expr:  "$A / on(cluster) $B",
+			vars:  instancesAndLimits,
+			errIs: require.Error,
+		},
+		{
+			name:  "group_left keeps the labels of the many side and includes labels from the one side",
+			expr:  "$A / on(cluster) group_left(tier) $B",
+			vars:  instancesAndLimits,
+			errIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"cluster": "c1", "instance": "i-1", "tier": "gold"}, float64Pointer(0.5)),
+					makeNumber("", data.Labels{"cluster": "c1", "instance": "i-2", "tier": "gold"}, float64Pointer(0.75)),
+				},
+			},
+		},
+		{
+			name:  "group_right keeps the labels of the right side",
+			expr:  "$B - on(cluster) group_right $A",
+			vars:  instancesAndLimits,
+			errIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"cluster": "c1", "instance": "i-1"}, float64Pointer(4)),
+					makeNumber("", data.Labels{"cluster": "c1", "instance": "i-2"}, float64Pointer(2)),
+				},
+			},
+		},
+		{
+			name:  "on() with no matching values is an error",
+			expr:  "$A / on(instance) $B",
+			vars:  instancesAndLimits,
+			errIs: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			res, err := e.Execute("", tt.vars)
+			tt.errIs(t, err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, tt.results, res)
+		})
+	}
+}
+
+func TestVectorMatchingParse(t *testing.T) {
+	var tests = []struct {
+		expr     string
+		errIs    require.ErrorAssertionFunc
+		rendered string
+	}{
+		{"$A + on(a, b) $B", require.NoError, "$A + on(a, b) $B"},
+		{"$A + ignoring(__name__) group_left(c) $B", require.NoError, "$A + ignoring(__name__) group_left(c) $B"},
+		{"$A + on() group_right $B", require.NoError, "$A + on() group_right() $B"},
+		{"$A + on(a $B", require.Error, ""},
+		{"$A + on(a) group_left(a) $B", require.Error, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.errIs(t, err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, tt.rendered, e.Tree.String())
+		})
+	}
+}
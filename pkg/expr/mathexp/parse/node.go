@@ -9,6 +9,7 @@ package parse
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // A Node is an element in the parse tree. The interface is trivial.
@@ -163,12 +164,15 @@ func (f *FuncNode) StringAST() string {
 func (f *FuncNode) Check(t *Tree) error {
 	if len(f.Args) < len(f.F.Args) {
 		return fmt.Errorf("parse: not enough arguments for %s", f.Name)
-	} else if len(f.Args) > len(f.F.Args) {
+	} else if len(f.Args) > len(f.F.Args) && !f.F.Variadic {
 		return fmt.Errorf("parse: too many arguments for %s", f.Name)
 	}
 
 	for i, arg := range f.Args {
-		funcType := f.F.Args[i]
+		funcType := f.F.Args[len(f.F.Args)-1]
+		if i < len(f.F.Args) {
+			funcType = f.F.Args[i]
+		}
 		argType := arg.Return()
 		// if funcType == TypeNumberSet && argType == TypeScalar {
 		// 	argType = TypeNumberSet
@@ -298,6 +302,7 @@ type BinaryNode struct {
 	Args     [2]Node
 	Operator item
 	OpStr    string
+	Matching *VectorMatching // nil unless on() or ignoring() is used
 }
 
 func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
@@ -306,11 +311,17 @@ func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
 
 // String returns the string representation of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) String() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s %s %s", b.Args[0], b.Operator.val, b.Matching, b.Args[1])
+	}
 	return fmt.Sprintf("%s %s %s", b.Args[0], b.Operator.val, b.Args[1])
 }
 
 // StringAST returns the string representation of abstract syntax tree of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) StringAST() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s(%s, %s)", b.Operator.val, b.Matching, b.Args[0], b.Args[1])
+	}
 	return fmt.Sprintf("%s(%s, %s)", b.Operator.val, b.Args[0], b.Args[1])
 }
 
@@ -329,6 +340,48 @@ func (b *BinaryNode) Return() ReturnType {
 	return t0
 }
 
+// VectorMatchCardinality describes how many values on each side of a binary
+// operation may be paired with each other.
+type VectorMatchCardinality int
+
+const (
+	// CardOneToOne requires each value on either side to match at most one value on the other side.
+	CardOneToOne VectorMatchCardinality = iota
+	// CardManyToOne allows many values on the left side to match one value on the right side (group_left).
+	CardManyToOne
+	// CardOneToMany allows one value on the left side to match many values on the right side (group_right).
+	CardOneToMany
+)
+
+// VectorMatching describes how the values of a binary operation are paired by
+// their labels, in the style of Prometheus' on, ignoring, group_left and group_right.
+type VectorMatching struct {
+	Card VectorMatchCardinality
+	// On is true if MatchingLabels are the only labels used for matching (on), and false
+	// if they are excluded from matching (ignoring).
+	On             bool
+	MatchingLabels []string
+	// Include are the labels copied from the "one" side to the result of a
+	// many-to-one or one-to-many match.
+	Include []string
+}
+
+// String returns the string representation of the VectorMatching as it would be written in an expression.
+func (m *VectorMatching) String() string {
+	kw := "ignoring"
+	if m.On {
+		kw = "on"
+	}
+	s := fmt.Sprintf("%s(%s)", kw, strings.Join(m.MatchingLabels, ", "))
+	switch m.Card {
+	case CardManyToOne:
+		s += fmt.Sprintf(" group_left(%s)", strings.Join(m.Include, ", "))
+	case CardOneToMany:
+		s += fmt.Sprintf(" group_right(%s)", strings.Join(m.Include, ", "))
+	}
+	return s
+}
+
 // UnaryNode holds one argument and an operator.
 type UnaryNode struct {
 	NodeType
@@ -30,11 +30,15 @@ type Tree struct {
 
 // Func holds the structure of a parsed function call.
 type Func struct {
-	Args          []ReturnType
-	Return        ReturnType
-	F             interface{}
+	Args   []ReturnType
+	Return ReturnType
+	F      interface{}
+	// VariantReturn makes the function return the type of its widest argument,
+	// in the order Scalar, NumberSet, SeriesSet.
 	VariantReturn bool
-	Check         func(*Tree, *FuncNode) error
+	// Variadic allows the last argument to be repeated any number of times.
+	Variadic bool
+	Check    func(*Tree, *FuncNode) error
 }
 
 // Parse returns a Tree, created by parsing the expression described in the
@@ -165,18 +169,90 @@ func (t *Tree) parse() {
 }
 
 /* Grammar:
-O -> A {"||" A}
-A -> C {"&&" C}
-C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") P}
-P -> M {( "+" | "-" ) M}
-M -> E {( "*" | "/" ) F}
-E -> F {( "**" ) F}
+O -> A {"||" [match] A}
+A -> C {"&&" [match] C}
+C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") [match] P}
+P -> M {( "+" | "-" ) [match] M}
+M -> E {( "*" | "/" ) [match] F}
+E -> F {( "**" ) [match] F}
 F -> v | "(" O ")" | "!" O | "-" O
 v -> number | func(..) | queryVar
 Func -> name "(" param {"," param} ")"
 param -> number | "string" | queryVar
+match -> ("on" | "ignoring") labels [("group_left" | "group_right") [labels]]
+labels -> "(" [name {"," name}] ")"
 */
 
+// binary consumes a binary operator, any vector matching modifiers that
+// follow it, and the right hand side parsed by rhs.
+func (t *Tree) binary(lhs Node, rhs func() Node) Node {
+	op := t.next()
+	matching := t.vectorMatching()
+	b := newBinary(op, lhs, rhs())
+	b.Matching = matching
+	return b
+}
+
+// vectorMatching is [match] in the grammar. It returns nil if the next
+// token does not start a matching clause.
+func (t *Tree) vectorMatching() *VectorMatching {
+	token := t.peek()
+	if token.typ != itemFunc || (token.val != "on" && token.val != "ignoring") {
+		return nil
+	}
+	t.next()
+	m := &VectorMatching{
+		Card:           CardOneToOne,
+		On:             token.val == "on",
+		MatchingLabels: t.labelList(token.val),
+	}
+
+	token = t.peek()
+	if token.typ != itemFunc || (token.val != "group_left" && token.val != "group_right") {
+		return m
+	}
+	t.next()
+	m.Card = CardManyToOne
+	if token.val == "group_right" {
+		m.Card = CardOneToMany
+	}
+	if t.peek().typ == itemLeftParen {
+		m.Include = t.labelList(token.val)
+	}
+	for _, l := range m.Include {
+		for _, ml := range m.MatchingLabels {
+			if m.On && l == ml {
+				t.errorf("label %q must not occur in both on() and %s()", l, token.val)
+			}
+		}
+	}
+	return m
+}
+
+// labelList is labels in the grammar.
+func (t *Tree) labelList(context string) []string {
+	t.expect(itemLeftParen, context)
+	labels := []string{}
+	for {
+		token := t.next()
+		switch token.typ {
+		case itemRightParen:
+			return labels
+		case itemFunc:
+			labels = append(labels, token.val)
+		default:
+			t.unexpected(token, context)
+		}
+		switch token = t.next(); token.typ {
+		case itemRightParen:
+			return labels
+		case itemComma:
+		default:
+			t.unexpected(token, context)
+		}
+	}
+}
+
 // expr:
 
 // O is A {"||" A} in the grammar.
@@ -185,7 +261,7 @@ func (t *Tree) O() Node {
 	for {
 		switch t.peek().typ {
 		case itemOr:
-			n = newBinary(t.next(), n, t.A())
+			n = t.binary(n, t.A)
 		default:
 			return n
 		}
@@ -198,7 +274,7 @@ func (t *Tree) A() Node {
 	for {
 		switch t.peek().typ {
 		case itemAnd:
-			n = newBinary(t.next(), n, t.C())
+			n = t.binary(n, t.C)
 		default:
 			return n
 		}
@@ -211,7 +287,7 @@ func (t *Tree) C() Node {
 	for {
 		switch t.peek().typ {
 		case itemEq, itemNotEq, itemGreater, itemGreaterEq, itemLess, itemLessEq:
-			n = newBinary(t.next(), n, t.P())
+			n = t.binary(n, t.P)
 		default:
 			return n
 		}
@@ -224,7 +300,7 @@ func (t *Tree) P() Node {
 	for {
 		switch t.peek().typ {
 		case itemPlus, itemMinus:
-			n = newBinary(t.next(), n, t.M())
+			n = t.binary(n, t.M)
 		default:
 			return n
 		}
@@ -237,7 +313,7 @@ func (t *Tree) M() Node {
 	for {
 		switch t.peek().typ {
 		case itemMult, itemDiv, itemMod:
-			n = newBinary(t.next(), n, t.E())
+			n = t.binary(n, t.E)
 		default:
 			return n
 		}
@@ -250,7 +326,7 @@ func (t *Tree) E() Node {
 	for {
 		switch t.peek().typ {
 		case itemPow:
-			n = newBinary(t.next(), n, t.F())
+			n = t.binary(n, t.F)
 		default:
 			return n
 		}
@@ -320,7 +396,7 @@ func (t *Tree) Func() (f *FuncNode) {
 			t.backup()
 			node := t.O()
 			f.append(node)
-			if len(f.Args) == 1 && f.F.VariantReturn {
+			if f.F.VariantReturn && (len(f.Args) == 1 || node.Return() > f.F.Return) {
 				f.F.Return = node.Return()
 			}
 		case itemString:
@@ -329,6 +405,10 @@ func (t *Tree) Func() (f *FuncNode) {
 				t.errorf("Unquoting error: %s", err)
 			}
 			f.append(newString(token.pos, token.val, s))
+		case itemComma:
+			if len(f.Args) == 0 {
+				t.unexpected(token, "func")
+			}
 		case itemRightParen:
 			return
 		}
@@ -3,6 +3,9 @@ package mathexp
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
@@ -69,28 +72,224 @@ func Count(fv *Float64Field) *float64 {
 	return &f
 }
 
-// Reduce turns the Series into a Number based on the given reduction function
-func (s Series) Reduce(refID, rFunc string) (Number, error) {
+// CountNonNull returns the number of values that are neither null nor NaN.
+func CountNonNull(fv *Float64Field) *float64 {
+	var f float64
+	for i := 0; i < fv.Len(); i++ {
+		if v := fv.GetValue(i); v != nil && !math.IsNaN(*v) {
+			f++
+		}
+	}
+	return &f
+}
+
+// First returns the first value, or NaN if it is null or there are no values.
+func First(fv *Float64Field) *float64 {
+	if fv.Len() == 0 {
+		return nanPointer()
+	}
+	return nilToNaN(fv.GetValue(0))
+}
+
+// Last returns the last value, or NaN if it is null or there are no values.
+func Last(fv *Float64Field) *float64 {
+	if fv.Len() == 0 {
+		return nanPointer()
+	}
+	return nilToNaN(fv.GetValue(fv.Len() - 1))
+}
+
+// Diff returns the last value minus the first value.
+func Diff(fv *Float64Field) *float64 {
+	f := *Last(fv) - *First(fv)
+	return &f
+}
+
+// Range returns the max value minus the min value.
+func Range(fv *Float64Field) *float64 {
+	f := *Max(fv) - *Min(fv)
+	return &f
+}
+
+// Stddev returns the population standard deviation of the values.
+func Stddev(fv *Float64Field) *float64 {
+	mean := Avg(fv)
+	if fv.Len() == 0 || math.IsNaN(*mean) {
+		return nanPointer()
+	}
+	var sumSquares float64
+	for i := 0; i < fv.Len(); i++ {
+		d := *fv.GetValue(i) - *mean
+		sumSquares += d * d
+	}
+	f := math.Sqrt(sumSquares / float64(fv.Len()))
+	return &f
+}
+
+// Median returns the 50th percentile of the values.
+func Median(fv *Float64Field) *float64 {
+	return Percentile(fv, 50)
+}
+
+// Percentile returns the p-th percentile (0 <= p <= 100) of the values,
+// linearly interpolating between the closest ranks.
+func Percentile(fv *Float64Field, p float64) *float64 {
+	if fv.Len() == 0 {
+		return nanPointer()
+	}
+	vals := make([]float64, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			return nanPointer()
+		}
+		vals[i] = *v
+	}
+	sort.Float64s(vals)
+	rank := p / 100 * float64(len(vals)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	f := vals[lower] + (vals[upper]-vals[lower])*(rank-float64(lower))
+	return &f
+}
+
+// parsePercentile returns the percentile of a reducer named like "p95" or "p99.9".
+func parsePercentile(rFunc string) (float64, bool) {
+	if !strings.HasPrefix(rFunc, "p") {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(strings.TrimPrefix(rFunc, "p"), 64)
+	if err != nil || p < 0 || p > 100 {
+		return 0, false
+	}
+	return p, true
+}
+
+func nanPointer() *float64 {
+	nan := math.NaN()
+	return &nan
+}
+
+func nilToNaN(f *float64) *float64 {
+	if f == nil {
+		return nanPointer()
+	}
+	return f
+}
+
+// reducers holds the reduction functions that need no arguments, by name.
+var reducers = map[string]func(*Float64Field) *float64{
+	"sum":            Sum,
+	"mean":           Avg,
+	"min":            Min,
+	"max":            Max,
+	"count":          Count,
+	"count_non_null": CountNonNull,
+	"first":          First,
+	"last":           Last,
+	"diff":           Diff,
+	"range":          Range,
+	"stddev":         Stddev,
+	"median":         Median,
+}
+
+// IsValidReducer returns true if rFunc is the name of a reduction function
+// known to Series.Reduce.
+func IsValidReducer(rFunc string) bool {
+	if _, ok := reducers[rFunc]; ok {
+		return true
+	}
+	_, ok := parsePercentile(rFunc)
+	return ok
+}
+
+// ReduceMapper transforms the values of a Series before it is reduced.
+type ReduceMapper interface {
+	MapInput(s Series) (Series, error)
+}
+
+// isNonNumber returns true for null, NaN and infinite values.
+func isNonNumber(f *float64) bool {
+	return f == nil || math.IsNaN(*f) || math.IsInf(*f, 0)
+}
+
+// DropNonNumber is a ReduceMapper that removes null, NaN and infinite values.
+type DropNonNumber struct{}
+
+// MapInput returns a copy of s without non-number values.
+func (DropNonNumber) MapInput(s Series) (Series, error) {
+	newSeries := NewSeries(s.Frame.Fields[seriesTypeValIdx].Name, s.GetLabels(), 0)
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		if isNonNumber(f) {
+			continue
+		}
+		if err := newSeries.AppendPoint(i, t, f); err != nil {
+			return newSeries, err
+		}
+	}
+	return newSeries, nil
+}
+
+// ReplaceNonNumberWithValue is a ReduceMapper that replaces null, NaN and
+// infinite values with Value.
+type ReplaceNonNumberWithValue struct {
+	Value float64
+}
+
+// MapInput returns a copy of s with non-number values replaced.
+func (r ReplaceNonNumberWithValue) MapInput(s Series) (Series, error) {
+	newSeries := NewSeries(s.Frame.Fields[seriesTypeValIdx].Name, s.GetLabels(), s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		if isNonNumber(f) {
+			v := r.Value
+			f = &v
+		}
+		if err := newSeries.SetPoint(i, t, f); err != nil {
+			return newSeries, err
+		}
+	}
+	return newSeries, nil
+}
+
+// FailOnNonNumber is a ReduceMapper that returns an error if the series holds
+// any null, NaN or infinite value.
+type FailOnNonNumber struct{}
+
+// MapInput returns s unchanged, or an error if it contains a non-number value.
+func (FailOnNonNumber) MapInput(s Series) (Series, error) {
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		if isNonNumber(f) {
+			return s, fmt.Errorf("series {%v} has a non-number value at %v", s.GetLabels(), t)
+		}
+	}
+	return s, nil
+}
+
+// Reduce turns the Series into a Number based on the given reduction function.
+// If mapper is not nil, it is applied to the series before the reduction.
+func (s Series) Reduce(refID, rFunc string, mapper ReduceMapper) (Number, error) {
 	var l data.Labels
 	if s.GetLabels() != nil {
 		l = s.GetLabels().Copy()
 	}
 	number := NewNumber(refID, l)
+	if mapper != nil {
+		var err error
+		if s, err = mapper.MapInput(s); err != nil {
+			return number, err
+		}
+	}
 	var f *float64
 	fVec := s.Frame.Fields[seriesTypeValIdx]
 	floatField := Float64Field(*fVec)
-	switch rFunc {
-	case "sum":
-		f = Sum(&floatField)
-	case "mean":
-		f = Avg(&floatField)
-	case "min":
-		f = Min(&floatField)
-	case "max":
-		f = Max(&floatField)
-	case "count":
-		f = Count(&floatField)
-	default:
+	if reducer, ok := reducers[rFunc]; ok {
+		f = reducer(&floatField)
+	} else if p, ok := parsePercentile(rFunc); ok {
+		f = Percentile(&floatField, p)
+	} else {
 		return number, fmt.Errorf("reduction %v not implemented", rFunc)
 	}
 	number.SetValue(f)
@@ -221,7 +221,7 @@ func TestSeriesReduce(t *testing.T) {
 			results := Results{}
 			seriesSet := tt.vars[tt.varToReduce]
 			for _, series := range seriesSet.Values {
-				ns, err := series.Value().(*Series).Reduce("", tt.red)
+				ns, err := series.Value().(*Series).Reduce("", tt.red, nil)
 				tt.errIs(t, err)
 				if err != nil {
 					return
@@ -238,3 +238,60 @@ func TestSeriesReduce(t *testing.T) {
 		})
 	}
 }
+
+var seriesForStats = makeSeries("temp", nil,
+	tp{time.Unix(1, 0), float64Pointer(4)},
+	tp{time.Unix(2, 0), float64Pointer(1)},
+	tp{time.Unix(3, 0), float64Pointer(3)},
+	tp{time.Unix(4, 0), float64Pointer(2)},
+)
+
+var seriesForStatsWithNonNumbers = makeSeries("temp", nil,
+	tp{time.Unix(1, 0), float64Pointer(4)},
+	tp{time.Unix(2, 0), nil},
+	tp{time.Unix(3, 0), NaN},
+	tp{time.Unix(4, 0), float64Pointer(2)},
+)
+
+func TestSeriesReduceStatistics(t *testing.T) {
+	var tests = []struct {
+		name     string
+		red      string
+		series   Series
+		mapper   ReduceMapper
+		errIs    require.ErrorAssertionFunc
+		expected *float64
+	}{
+		{"first", "first", seriesForStats, nil, require.NoError, float64Pointer(4)},
+		{"last", "last", seriesForStats, nil, require.NoError, float64Pointer(2)},
+		{"diff", "diff", seriesForStats, nil, require.NoError, float64Pointer(-2)},
+		{"range", "range", seriesForStats, nil, require.NoError, float64Pointer(3)},
+		{"median", "median", seriesForStats, nil, require.NoError, float64Pointer(2.5)},
+		{"p0", "p0", seriesForStats, nil, require.NoError, float64Pointer(1)},
+		{"p100", "p100", seriesForStats, nil, require.NoError, float64Pointer(4)},
+		{"p75", "p75", seriesForStats, nil, require.NoError, float64Pointer(3.25)},
+		{"stddev", "stddev", seriesForStats, nil, require.NoError, float64Pointer(math.Sqrt(1.25))},
+		{"p101 is not a reducer", "p101", seriesForStats, nil, require.Error, nil},
+		{"count_non_null", "count_non_null", seriesForStatsWithNonNumbers, nil, require.NoError, float64Pointer(2)},
+		{"median with nulls is NaN", "median", seriesForStatsWithNonNumbers, nil, require.NoError, NaN},
+		{"median dropping non numbers", "median", seriesForStatsWithNonNumbers, DropNonNumber{}, require.NoError, float64Pointer(3)},
+		{"sum replacing non numbers", "sum", seriesForStatsWithNonNumbers, ReplaceNonNumberWithValue{Value: 1}, require.NoError, float64Pointer(8)},
+		{"failing on non numbers", "sum", seriesForStatsWithNonNumbers, FailOnNonNumber{}, require.Error, nil},
+		{"failing without non numbers", "sum", seriesForStats, FailOnNonNumber{}, require.NoError, float64Pointer(10)},
+		{"last of empty series dropping non numbers is NaN", "last", makeSeries("temp", nil, tp{time.Unix(1, 0), nil}), DropNonNumber{}, require.NoError, NaN},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := tt.series.Reduce("", tt.red, tt.mapper)
+			tt.errIs(t, err)
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.expected, n.GetFloat64Value(), cmp.Comparer(func(x, y float64) bool {
+				return (math.IsNaN(x) && math.IsNaN(y)) || math.Abs(x-y) < 1e-9
+			})); diff != "" {
+				t.Errorf("Result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package mathexp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// checkDurationArg returns a parse time check that the string argument at
+// argIdx of a function is a valid duration.
+func checkDurationArg(argIdx int) func(*parse.Tree, *parse.FuncNode) error {
+	return func(t *parse.Tree, f *parse.FuncNode) error {
+		s, ok := f.Args[argIdx].(*parse.StringNode)
+		if !ok {
+			return fmt.Errorf("parse: expected a duration string for argument %v of %s", argIdx, f.Name)
+		}
+		if _, err := gtime.ParseDuration(s.Text); err != nil {
+			return fmt.Errorf("parse: invalid duration %q for argument %v of %s: %w", s.Text, argIdx, f.Name, err)
+		}
+		return nil
+	}
+}
+
+// perSeries applies seriesF to each Series in varSet, with the points of the
+// series in ascending time order. It errors if varSet holds anything but series.
+func perSeries(e *State, name string, varSet Results, seriesF func(Series) (Series, error)) (Results, error) {
+	newRes := Results{}
+	for _, val := range varSet.Values {
+		s, ok := val.(Series)
+		if !ok {
+			return newRes, fmt.Errorf("%s can only be applied to type series, got type %v", name, val.Type())
+		}
+		newSeries, err := seriesF(sortedCopy(e.RefID, s))
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newSeries)
+	}
+	return newRes, nil
+}
+
+// sortedCopy returns a copy of s named refID with its points sorted by time.
+func sortedCopy(refID string, s Series) Series {
+	c := NewSeries(refID, s.GetLabels(), s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		_ = c.SetPoint(i, t, f)
+	}
+	c.SortByTime(false)
+	return c
+}
+
+// pairwise returns a series with one point less than s where each point is the
+// result of pairF on a point and its predecessor. If either value is null, or
+// pairF returns nil, the new point is null.
+func pairwise(s Series, pairF func(prevT time.Time, prev float64, t time.Time, cur float64) *float64) (Series, error) {
+	newSeries := NewSeries(s.Frame.Fields[seriesTypeValIdx].Name, s.GetLabels(), 0)
+	for i := 1; i < s.Len(); i++ {
+		prevT, prev := s.GetPoint(i - 1)
+		t, cur := s.GetPoint(i)
+		if prev == nil || cur == nil {
+			if err := newSeries.AppendPoint(i-1, t, nil); err != nil {
+				return newSeries, err
+			}
+			continue
+		}
+		if err := newSeries.AppendPoint(i-1, t, pairF(prevT, *prev, t, *cur)); err != nil {
+			return newSeries, err
+		}
+	}
+	return newSeries, nil
+}
+
+// counterIncrease returns the increase between two samples of a counter. A
+// decreasing value is treated as a counter reset.
+func counterIncrease(prev, cur float64) float64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
+
+// delta returns the difference between each point and its predecessor.
+func delta(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "delta", varSet, func(s Series) (Series, error) {
+		return pairwise(s, func(_ time.Time, prev float64, _ time.Time, cur float64) *float64 {
+			f := cur - prev
+			return &f
+		})
+	})
+}
+
+// increase returns the increase of a counter between each point and its
+// predecessor, accounting for counter resets.
+func increase(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "increase", varSet, func(s Series) (Series, error) {
+		return pairwise(s, func(_ time.Time, prev float64, _ time.Time, cur float64) *float64 {
+			f := counterIncrease(prev, cur)
+			return &f
+		})
+	})
+}
+
+// rate returns the per-second increase of a counter between each point and
+// its predecessor, accounting for counter resets. The rate of points with the
+// same time as their predecessor is null.
+func rate(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "rate", varSet, func(s Series) (Series, error) {
+		return pairwise(s, func(prevT time.Time, prev float64, t time.Time, cur float64) *float64 {
+			seconds := t.Sub(prevT).Seconds()
+			if seconds == 0 {
+				return nil
+			}
+			f := counterIncrease(prev, cur) / seconds
+			return &f
+		})
+	})
+}
+
+// cumsum returns the running total of each series. Null points stay null and
+// do not contribute to the total.
+func cumsum(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "cumsum", varSet, func(s Series) (Series, error) {
+		var sum float64
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if f == nil {
+				continue
+			}
+			sum += *f
+			v := sum
+			if err := s.SetPoint(i, t, &v); err != nil {
+				return s, err
+			}
+		}
+		return s, nil
+	})
+}
+
+// movingAvg returns the mean of the non-null values in the window ending at each point
+// (exclusive of the start, inclusive of the point). If there are no values the point is null.
+func movingAvg(e *State, varSet Results, rawWindow string) (Results, error) {
+	window, err := gtime.ParseDuration(rawWindow)
+	if err != nil {
+		return Results{}, err
+	}
+	return perSeries(e, "moving_avg", varSet, func(s Series) (Series, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		start := 0
+		for i := 0; i < s.Len(); i++ {
+			t := s.GetTime(i)
+			for start < i && !s.GetTime(start).After(t.Add(-window)) {
+				start++
+			}
+			var sum float64
+			count := 0
+			for j := start; j <= i; j++ {
+				if f := s.GetValue(j); f != nil {
+					sum += *f
+					count++
+				}
+			}
+			var avg *float64
+			if count > 0 {
+				v := sum / float64(count)
+				avg = &v
+			}
+			if err := newSeries.SetPoint(i, t, avg); err != nil {
+				return newSeries, err
+			}
+		}
+		return newSeries, nil
+	})
+}
+
+// timeShift moves every point of each series by the given duration. A negative
+// duration moves points into the past.
+func timeShift(e *State, varSet Results, rawShift string) (Results, error) {
+	shift, err := gtime.ParseDuration(rawShift)
+	if err != nil {
+		return Results{}, err
+	}
+	return perSeries(e, "time_shift", varSet, func(s Series) (Series, error) {
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if err := s.SetPoint(i, t.Add(shift), f); err != nil {
+				return s, err
+			}
+		}
+		return s, nil
+	})
+}
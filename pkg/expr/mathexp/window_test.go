@@ -0,0 +1,188 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+var counterSeries = Vars{
+	"A": Results{
+		[]Value{
+			// out of order on purpose, functions must sort by time first
+			makeSeries("requests", data.Labels{"host": "a"}, tp{
+				time.Unix(20, 0), float64Pointer(30),
+			}, tp{
+				time.Unix(0, 0), float64Pointer(0),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(10),
+			}, tp{
+				time.Unix(30, 0), float64Pointer(5),
+			}),
+		},
+	},
+}
+
+func TestWindowFunctions(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expr     string
+		vars     Vars
+		newErrIs require.ErrorAssertionFunc
+		errIs    require.ErrorAssertionFunc
+		results  Results
+	}{
+		{
+			name:     "delta",
+			expr:     "delta($A)",
+			vars:     counterSeries,
+			newErrIs: require.NoError,
+			errIs:    require.NoError,
+			results: Results{[]Value{
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(10, 0), float64Pointer(10)},
+					tp{time.Unix(20, 0), float64Pointer(20)},
+					tp{time.Unix(30, 0), float64Pointer(-25)},
+				),
+			}},
+		},
+		{
+			name:     "increase handles counter resets",
+			expr:     "increase($A)",
+			vars:     counterSeries,
+			newErrIs: require.NoError,
+			errIs:    require.NoError,
+			results: Results{[]Value{
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(10, 0), float64Pointer(10)},
+					tp{time.Unix(20, 0), float64Pointer(20)},
+					tp{time.Unix(30, 0), float64Pointer(5)},
+				),
+			}},
+		},
+		{
+			name:     "rate is the per second increase",
+			expr:     "rate($A)",
+			vars:     counterSeries,
+			newErrIs: require.NoError,
+			errIs:    require.NoError,
+			results: Results{[]Value{
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(10, 0), float64Pointer(1)},
+					tp{time.Unix(20, 0), float64Pointer(2)},
+					tp{time.Unix(30, 0), float64Pointer(0.5)},
+				),
+			}},
+		},
+		{
+			name: "rate of points with duplicate timestamps is null",
+			expr: "rate($A)",
+			vars: Vars{"A": Results{[]Value{
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(0, 0), float64Pointer(0)},
+					tp{time.Unix(10, 0), float64Pointer(10)},
+					tp{time.Unix(10, 0), float64Pointer(20)},
+					tp{time.Unix(20, 0), float64Pointer(30)},
+				),
+			}}},
+			newErrIs: require.NoError,
+			errIs:    require.NoError,
+			results: Results{[]Value{
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(10, 0), float64Pointer(1)},
+					tp{time.Unix(10, 0), nil},
+					tp{time.Unix(20, 0), float64Pointer(1)},
+				),
+			}},
+		},
+		{
+			name:     "cumsum",
+			expr:     "cumsum($A)",
+			vars:     counterSeries,
+			newErrIs: require.NoError,
+			errIs:    require.NoError,
+			results: Results{[]Value{
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(0, 0), float64Pointer(0)},
+					tp{time.Unix(10, 0), float64Pointer(10)},
+					tp{time.Unix(20, 0), float64Pointer(40)},
+					tp{time.Unix(30, 0), float64Pointer(45)},
+				),
+			}},
+		},
+		{
+			name:     "moving_avg",
+			expr:     `moving_avg($A, "20s")`,
+			vars:     counterSeries,
+			newErrIs: require.NoError,
+			errIs:    require.NoError,
+			results: Results{[]Value{
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(0, 0), float64Pointer(0)},
+					tp{time.Unix(10, 0), float64Pointer(5)},
+					tp{time.Unix(20, 0), float64Pointer(20)},
+					tp{time.Unix(30, 0), float64Pointer(17.5)},
+				),
+			}},
+		},
+		{
+			name:     "time_shift",
+			expr:     `time_shift($A, "-10s")`,
+			vars:     counterSeries,
+			newErrIs: require.NoError,
+			errIs:    require.NoError,
+			results: Results{[]Value{
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(-10, 0), float64Pointer(0)},
+					tp{time.Unix(0, 0), float64Pointer(10)},
+					tp{time.Unix(10, 0), float64Pointer(30)},
+					tp{time.Unix(20, 0), float64Pointer(5)},
+				),
+			}},
+		},
+		{
+			name:     "moving_avg with an invalid window fails to parse",
+			expr:     `moving_avg($A, "soon")`,
+			newErrIs: require.Error,
+		},
+		{
+			name:     "rate on a number fails",
+			expr:     "rate($A)",
+			vars:     Vars{"A": Results{[]Value{makeNumber("", nil, float64Pointer(1))}}},
+			newErrIs: require.NoError,
+			errIs:    require.Error,
+		},
+		{
+			name:     "clamp on a number",
+			expr:     "clamp($A, 0, 10)",
+			vars:     Vars{"A": Results{[]Value{makeNumber("", nil, float64Pointer(12))}}},
+			newErrIs: require.NoError,
+			errIs:    require.NoError,
+			results:  Results{[]Value{makeNumber("", nil, float64Pointer(10))}},
+		},
+		{
+			name:     "clamp with min greater than max fails",
+			expr:     "clamp($A, 10, 0)",
+			vars:     Vars{"A": Results{[]Value{makeNumber("", nil, float64Pointer(12))}}},
+			newErrIs: require.NoError,
+			errIs:    require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if err != nil {
+				return
+			}
+			res, err := e.Execute("", tt.vars)
+			tt.errIs(t, err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, tt.results, res)
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -115,6 +117,10 @@ func buildCMDNode(dp *simple.DirectedGraph, rn *rawNode) (*CMDNode, error) {
 		node.Command, err = UnmarshalResampleCommand(rn)
 	case TypeClassicConditions:
 		node.Command, err = classic.UnmarshalConditionsCmd(rn.Query, rn.RefID)
+	case TypeThreshold:
+		node.Command, err = UnmarshalThresholdCommand(rn)
+	case TypeAggregate:
+		node.Command, err = UnmarshalAggregateCommand(rn)
 	default:
 		return nil, fmt.Errorf("expression command type '%v' in '%v' not implemented", commandType, rn.RefID)
 	}
@@ -230,49 +236,43 @@ func (dn *DSNode) Execute(ctx context.Context, vars mathexp.Vars, s *Service) (m
 		},
 	}
 
-	resp, err := s.queryData(ctx, &backend.QueryDataRequest{
+	req := &backend.QueryDataRequest{
 		PluginContext: pc,
 		Queries:       q,
 		Headers:       dn.request.Headers,
-	})
+	}
+	var resp *backend.QueryDataResponse
+	var err error
+	switch {
+	case s.prefetched != nil:
+		resp, err = s.prefetchedData(req)
+	case s.QueryCache != nil:
+		resp, err = s.QueryCache.queryData(ctx, req, s.queryData)
+	default:
+		resp, err = s.queryData(ctx, req)
+	}
 
 	if err != nil {
 		return mathexp.Results{}, err
 	}
 
 	vals := make([]mathexp.Value, 0)
+	defer func() {
+		if len(vals) == 0 {
+			addWarning(ctx, "query %v returned no data", dn.refID)
+		}
+	}()
 	for refID, qr := range resp.Responses {
 		if qr.Error != nil {
 			return mathexp.Results{}, fmt.Errorf("failed to execute query %v: %w", refID, qr.Error)
 		}
 
-		if len(qr.Frames) == 1 {
-			frame := qr.Frames[0]
-			if frame.TimeSeriesSchema().Type == data.TimeSeriesTypeNot && isNumberTable(frame) {
-				logger.Debug("expression datasource query (numberSet)", "query", refID)
-				numberSet, err := extractNumberSet(frame)
-				if err != nil {
-					return mathexp.Results{}, err
-				}
-				for _, n := range numberSet {
-					vals = append(vals, n)
-				}
-
-				return mathexp.Results{
-					Values: vals,
-				}, nil
-			}
-		}
-
 		for _, frame := range qr.Frames {
-			logger.Debug("expression datasource query (seriesSet)", "query", refID)
-			series, err := WideToMany(frame)
+			frameVals, err := frameToValues(refID, frame)
 			if err != nil {
 				return mathexp.Results{}, err
 			}
-			for _, s := range series {
-				vals = append(vals, s)
-			}
+			vals = append(vals, frameVals...)
 		}
 	}
 	return mathexp.Results{
@@ -280,12 +280,77 @@ func (dn *DSNode) Execute(ctx context.Context, vars mathexp.Vars, s *Service) (m
 	}, nil
 }
 
+// frameToValues converts a frame returned by a data source query into numbers or series.
+// Frames without fields hold no data and result in no values.
+func frameToValues(refID string, frame *data.Frame) ([]mathexp.Value, error) {
+	vals := []mathexp.Value{}
+	if len(frame.Fields) == 0 {
+		return vals, nil
+	}
+
+	tsSchema := frame.TimeSeriesSchema()
+	switch {
+	case tsSchema.Type == data.TimeSeriesTypeNot && isNumberTable(frame):
+		logger.Debug("expression datasource query (numberSet)", "query", refID)
+		numberSet, err := extractNumberSet(frame)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range numberSet {
+			vals = append(vals, n)
+		}
+	case tsSchema.Type == data.TimeSeriesTypeWide && hasNumericValues(frame, tsSchema):
+		logger.Debug("expression datasource query (seriesSet)", "query", refID)
+		series, err := WideToMany(frame)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range series {
+			vals = append(vals, s)
+		}
+	case tsSchema.Type == data.TimeSeriesTypeLong && hasNumericValues(frame, tsSchema):
+		logger.Debug("expression datasource query (long seriesSet)", "query", refID)
+		series, err := LongToMany(frame)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range series {
+			vals = append(vals, s)
+		}
+	default:
+		return nil, fmt.Errorf("failed to convert the response of query %v: %w", refID, unsupportedFrameError(frame))
+	}
+	return vals, nil
+}
+
+// unsupportedFrameError returns an error describing the fields of frame and the
+// frame shapes that can be converted into numbers or series.
+func unsupportedFrameError(frame *data.Frame) error {
+	fields := make([]string, 0, len(frame.Fields))
+	for _, field := range frame.Fields {
+		fields = append(fields, fmt.Sprintf("%q (%s)", field.Name, field.Type().ItemTypeString()))
+	}
+	return fmt.Errorf("unsupported frame with fields [%s], accepted frames are: "+
+		"a wide time series (one time field and numeric fields), "+
+		"a long time series (one time field, string fields and numeric fields), "+
+		"or a table without time (string fields and numeric fields)", strings.Join(fields, ", "))
+}
+
+// hasNumericValues returns true if all the value fields of a time series frame are numeric.
+func hasNumericValues(frame *data.Frame, tsSchema data.TimeSeriesSchema) bool {
+	for _, idx := range tsSchema.ValueIndices {
+		if !frame.Fields[idx].Type().Numeric() {
+			return false
+		}
+	}
+	return true
+}
+
 func isNumberTable(frame *data.Frame) bool {
 	if frame == nil || frame.Fields == nil {
 		return false
 	}
 	numericCount := 0
-	stringCount := 0
 	otherCount := 0
 	for _, field := range frame.Fields {
 		fType := field.Type()
@@ -293,49 +358,129 @@ func isNumberTable(frame *data.Frame) bool {
 		case fType.Numeric():
 			numericCount++
 		case fType == data.FieldTypeString || fType == data.FieldTypeNullableString:
-			stringCount++
 		default:
 			otherCount++
 		}
 	}
-	return numericCount == 1 && otherCount == 0
+	return numericCount > 0 && otherCount == 0
 }
 
+// fieldLabel is the label that has the name of the numeric field of a number created from
+// a table with more than one numeric field.
+const fieldLabel = "__field__"
+
+// extractNumberSet converts a table into numbers. The string fields become labels and
+// a number is created for each row and numeric field. If there is more than one numeric
+// field the numbers are named after their field, and the name of the field is added as
+// the fieldLabel label so that the numbers of the same row have different labels.
 func extractNumberSet(frame *data.Frame) ([]mathexp.Number, error) {
-	numericField := 0
+	numericFieldIdxs := []int{}
 	stringFieldIdxs := []int{}
 	stringFieldNames := []string{}
 	for i, field := range frame.Fields {
 		fType := field.Type()
 		switch {
 		case fType.Numeric():
-			numericField = i
+			numericFieldIdxs = append(numericFieldIdxs, i)
 		case fType == data.FieldTypeString || fType == data.FieldTypeNullableString:
 			stringFieldIdxs = append(stringFieldIdxs, i)
 			stringFieldNames = append(stringFieldNames, field.Name)
 		}
 	}
-	numbers := make([]mathexp.Number, frame.Rows())
+	numbers := make([]mathexp.Number, 0, frame.Rows()*len(numericFieldIdxs))
 
-	for rowIdx := 0; rowIdx < frame.Rows(); rowIdx++ {
-		val, _ := frame.FloatAt(numericField, rowIdx)
-		var labels data.Labels
-		for i := 0; i < len(stringFieldIdxs); i++ {
-			if i == 0 {
-				labels = make(data.Labels)
-			}
-			key := stringFieldNames[i] // TODO check for duplicate string column names
-			val, _ := frame.ConcreteAt(stringFieldIdxs[i], rowIdx)
-			labels[key] = val.(string) // TODO check assertion / return error
+	for _, numericField := range numericFieldIdxs {
+		name := ""
+		if len(numericFieldIdxs) > 1 {
+			name = frame.Fields[numericField].Name
 		}
+		for rowIdx := 0; rowIdx < frame.Rows(); rowIdx++ {
+			val, _ := frame.FloatAt(numericField, rowIdx)
+			var labels data.Labels
+			for i := 0; i < len(stringFieldIdxs); i++ {
+				if i == 0 {
+					labels = make(data.Labels)
+				}
+				key := stringFieldNames[i] // TODO check for duplicate string column names
+				labels[key] = stringAt(frame, stringFieldIdxs[i], rowIdx)
+			}
+			if name != "" {
+				if labels == nil {
+					labels = make(data.Labels)
+				}
+				labels[fieldLabel] = name
+			}
 
-		n := mathexp.NewNumber("", labels)
-		n.SetValue(&val)
-		numbers[rowIdx] = n
+			n := mathexp.NewNumber(name, labels)
+			n.SetValue(&val)
+			numbers = append(numbers, n)
+		}
 	}
 	return numbers, nil
 }
 
+// stringAt returns the value of a string or nullable string field, null values are empty strings.
+func stringAt(frame *data.Frame, fieldIdx, rowIdx int) string {
+	val, ok := frame.ConcreteAt(fieldIdx, rowIdx)
+	if !ok {
+		return ""
+	}
+	str, _ := val.(string)
+	return str
+}
+
+// LongToMany converts a data package long type Frame to one or multiple Series. The
+// string fields of the frame become labels, and a series is created for each numeric
+// field and unique set of labels.
+func LongToMany(frame *data.Frame) ([]mathexp.Series, error) {
+	tsSchema := frame.TimeSeriesSchema()
+	if tsSchema.Type != data.TimeSeriesTypeLong {
+		return nil, fmt.Errorf("input data must be a long series but got type %s", tsSchema.Type)
+	}
+	if frame.Rows() == 0 {
+		return []mathexp.Series{}, nil
+	}
+
+	sorted, err := sortByTime(frame, tsSchema.TimeIndex)
+	if err != nil {
+		return nil, err
+	}
+	wide, err := data.LongToWide(sorted, nil)
+	if err != nil {
+		return nil, err
+	}
+	return WideToMany(wide)
+}
+
+// sortByTime returns a copy of frame with its rows sorted by the time field at timeIdx.
+func sortByTime(frame *data.Frame, timeIdx int) (*data.Frame, error) {
+	rows := frame.Rows()
+	order := make([]int, rows)
+	times := make([]time.Time, rows)
+	for i := 0; i < rows; i++ {
+		order[i] = i
+		switch t := frame.At(timeIdx, i).(type) {
+		case time.Time:
+			times[i] = t
+		case *time.Time:
+			if t == nil {
+				return nil, fmt.Errorf("time field %q has null values", frame.Fields[timeIdx].Name)
+			}
+			times[i] = *t
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return times[order[a]].Before(times[order[b]])
+	})
+
+	sorted := frame.EmptyCopy()
+	sorted.Meta = frame.Meta
+	for _, i := range order {
+		sorted.AppendRow(frame.RowCopy(i)...)
+	}
+	return sorted, nil
+}
+
 // WideToMany converts a data package wide type Frame to one or multiple Series. A series
 // is created for each value type column of wide frame.
 //
@@ -0,0 +1,92 @@
+package expr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+)
+
+func TestFrameToValues(t *testing.T) {
+	t.Run("long frame becomes a series per numeric field and label set", func(t *testing.T) {
+		frame := data.NewFrame("",
+			data.NewField("time", nil, []time.Time{time.Unix(20, 0), time.Unix(10, 0), time.Unix(10, 0), time.Unix(20, 0)}),
+			data.NewField("host", nil, []string{"a", "a", "b", "b"}),
+			data.NewField("cpu", nil, []float64{2, 1, 3, 4}),
+			data.NewField("mem", nil, []int64{20, 10, 30, 40}),
+		)
+		vals, err := frameToValues("A", frame)
+		require.NoError(t, err)
+		require.Len(t, vals, 4)
+
+		got := map[string][]float64{}
+		for _, v := range vals {
+			s, ok := v.(mathexp.Series)
+			require.True(t, ok)
+			key := s.Frame.Fields[1].Name + s.GetLabels().String()
+			for i := 0; i < s.Len(); i++ {
+				_, f := s.GetPoint(i)
+				got[key] = append(got[key], *f)
+			}
+		}
+		require.Equal(t, map[string][]float64{
+			"cpuhost=a": {1, 2},
+			"cpuhost=b": {3, 4},
+			"memhost=a": {10, 20},
+			"memhost=b": {30, 40},
+		}, got)
+	})
+
+	t.Run("table with multiple numeric fields becomes a number per field and row", func(t *testing.T) {
+		frame := data.NewFrame("",
+			data.NewField("host", nil, []*string{ptr.String("a"), nil}),
+			data.NewField("cpu", nil, []float64{1, 2}),
+			data.NewField("mem", nil, []float64{10, 20}),
+		)
+		vals, err := frameToValues("A", frame)
+		require.NoError(t, err)
+		require.Len(t, vals, 4)
+
+		n := vals[1].(mathexp.Number)
+		require.Equal(t, "cpu", n.Frame.Fields[0].Name)
+		require.Equal(t, data.Labels{"host": "", "__field__": "cpu"}, n.GetLabels())
+		require.Equal(t, ptr.Float64(2), n.GetFloat64Value())
+
+		n = vals[2].(mathexp.Number)
+		require.Equal(t, "mem", n.Frame.Fields[0].Name)
+		require.Equal(t, data.Labels{"host": "a", "__field__": "mem"}, n.GetLabels())
+	})
+
+	t.Run("numbers of the numeric fields of a row have different labels", func(t *testing.T) {
+		frame := data.NewFrame("",
+			data.NewField("cpu", nil, []float64{1}),
+			data.NewField("mem", nil, []float64{10}),
+		)
+		vals, err := frameToValues("A", frame)
+		require.NoError(t, err)
+		require.Len(t, vals, 2)
+		require.Equal(t, data.Labels{"__field__": "cpu"}, vals[0].GetLabels())
+		require.Equal(t, data.Labels{"__field__": "mem"}, vals[1].GetLabels())
+	})
+
+	t.Run("frame without fields has no values", func(t *testing.T) {
+		vals, err := frameToValues("A", data.NewFrame(""))
+		require.NoError(t, err)
+		require.Empty(t, vals)
+	})
+
+	t.Run("unsupported frame lists the accepted shapes", func(t *testing.T) {
+		frame := data.NewFrame("",
+			data.NewField("time", nil, []time.Time{time.Unix(10, 0)}),
+			data.NewField("message", nil, []string{"hello"}),
+		)
+		_, err := frameToValues("A", frame)
+		require.EqualError(t, err, `failed to convert the response of query A: unsupported frame with fields ["time" (time.Time), "message" (string)], `+
+			`accepted frames are: a wide time series (one time field and numeric fields), `+
+			`a long time series (one time field, string fields and numeric fields), `+
+			`or a table without time (string fields and numeric fields)`)
+	})
+}
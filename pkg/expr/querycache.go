@@ -0,0 +1,169 @@
+package expr
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	gocache "github.com/patrickmn/go-cache"
+	"golang.org/x/sync/singleflight"
+)
+
+// QueryCache deduplicates identical data source queries issued by data source nodes.
+// Concurrent identical queries share a single request to the data source and, when
+// the TTL is greater than zero, successful responses are kept and reused for that long.
+//
+// Queries are identical if they have the same org, data source, query model, query type,
+// interval, max data points and time range. The time range is aligned to the second,
+// which is the resolution used when sending queries to the data source.
+type QueryCache struct {
+	ttl     time.Duration
+	timeout time.Duration
+	cache   *gocache.Cache
+	group   singleflight.Group
+}
+
+// NewQueryCache returns a QueryCache that keeps responses for ttl. If ttl is zero
+// or less, responses are not kept and only in-flight queries are deduplicated.
+//
+// Shared queries are not canceled with the context of the caller that issued them,
+// as other callers wait for their response, but they are canceled after timeout.
+func NewQueryCache(ttl, timeout time.Duration) *QueryCache {
+	qc := &QueryCache{ttl: ttl, timeout: timeout}
+	if ttl > 0 {
+		qc.cache = gocache.New(ttl, 2*ttl)
+	}
+	return qc
+}
+
+// queryData returns the response of the single query in req, using fetch to query the
+// data source when there is no cached response for it.
+func (qc *QueryCache) queryData(ctx context.Context, req *backend.QueryDataRequest, fetch func(context.Context, *backend.QueryDataRequest) (*backend.QueryDataResponse, error)) (*backend.QueryDataResponse, error) {
+	if len(req.Queries) != 1 {
+		return fetch(ctx, req)
+	}
+	refID := req.Queries[0].RefID
+
+	key, err := queryCacheKey(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if qc.cache != nil {
+		if cached, ok := qc.cache.Get(key); ok {
+			metrics.MExpressionsQueryCacheTotal.WithLabelValues("hit").Inc()
+			return cachedResponse(refID, cached.(backend.DataResponse)), nil
+		}
+	}
+
+	ch := qc.group.DoChan(key, func() (interface{}, error) {
+		fetchCtx := context.Context(detachedContext{parent: ctx})
+		if qc.timeout > 0 {
+			var cancel context.CancelFunc
+			fetchCtx, cancel = context.WithTimeout(fetchCtx, qc.timeout)
+			defer cancel()
+		}
+		resp, err := fetch(fetchCtx, req)
+		if err != nil {
+			return nil, err
+		}
+		dr, ok := resp.Responses[refID]
+		if !ok || len(resp.Responses) != 1 {
+			return nil, fmt.Errorf("expected a single response for query %v, got %v", refID, len(resp.Responses))
+		}
+		if qc.cache != nil && dr.Error == nil {
+			qc.cache.SetDefault(key, dr)
+		}
+		return dr, nil
+	})
+
+	var res singleflight.Result
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res = <-ch:
+	}
+	if res.Shared {
+		metrics.MExpressionsQueryCacheTotal.WithLabelValues("hit").Inc()
+	} else {
+		metrics.MExpressionsQueryCacheTotal.WithLabelValues("miss").Inc()
+	}
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return cachedResponse(refID, res.Val.(backend.DataResponse)), nil
+}
+
+// detachedContext has the values of its parent, such as the signed in user and the
+// trace, but is never canceled.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (c detachedContext) Deadline() (time.Time, bool)       { return time.Time{}, false }
+func (c detachedContext) Done() <-chan struct{}             { return nil }
+func (c detachedContext) Err() error                        { return nil }
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
+
+// queryCacheKey returns the key identifying the single query in req. The refID is
+// not part of the key so the same query from different rules is deduplicated.
+func queryCacheKey(req *backend.QueryDataRequest) (string, error) {
+	q := req.Queries[0]
+	var dsID int64
+	var dsUID string
+	if req.PluginContext.DataSourceInstanceSettings != nil {
+		dsID = req.PluginContext.DataSourceInstanceSettings.ID
+		dsUID = req.PluginContext.DataSourceInstanceSettings.UID
+	}
+
+	headers := make([]string, 0, len(req.Headers))
+	for k, v := range req.Headers {
+		headers = append(headers, k+"="+v)
+	}
+	sort.Strings(headers)
+
+	h := sha256.New()
+	if _, err := fmt.Fprintf(h, "%d\x00%d\x00%s\x00%s\x00%d\x00%d\x00%d\x00%d\x00%q\x00",
+		req.PluginContext.OrgID, dsID, dsUID, q.QueryType, q.Interval, q.MaxDataPoints,
+		q.TimeRange.From.Unix(), q.TimeRange.To.Unix(), headers); err != nil {
+		return "", err
+	}
+	if _, err := h.Write(q.JSON); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// cachedResponse returns a response for refID holding a copy of the frames of dr,
+// so that callers sharing a response can not change each others data.
+func cachedResponse(refID string, dr backend.DataResponse) *backend.QueryDataResponse {
+	frames := make(data.Frames, 0, len(dr.Frames))
+	for _, frame := range dr.Frames {
+		frames = append(frames, copyFrame(frame))
+	}
+	resp := backend.NewQueryDataResponse()
+	resp.Responses[refID] = backend.DataResponse{
+		Frames: frames,
+		Error:  dr.Error,
+	}
+	return resp
+}
+
+// copyFrame returns a copy of frame and its values. Metadata and field configs are shared.
+func copyFrame(frame *data.Frame) *data.Frame {
+	c := frame.EmptyCopy()
+	c.Meta = frame.Meta
+	for i, field := range frame.Fields {
+		c.Fields[i].Config = field.Config
+	}
+	for i := 0; i < frame.Rows(); i++ {
+		c.AppendRow(frame.RowCopy(i)...)
+	}
+	return c
+}
@@ -0,0 +1,168 @@
+package expr
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+)
+
+func TestQueryCache(t *testing.T) {
+	from := time.Unix(1000, 0)
+	newReq := func(refID string, query string, to time.Time) *backend.QueryDataRequest {
+		return &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{
+				OrgID:                      1,
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: "prom"},
+			},
+			Queries: []backend.DataQuery{{
+				RefID:     refID,
+				JSON:      []byte(query),
+				TimeRange: backend.TimeRange{From: from, To: to},
+			}},
+		}
+	}
+
+	var calls int32
+	fetch := func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+		atomic.AddInt32(&calls, 1)
+		resp := backend.NewQueryDataResponse()
+		resp.Responses[req.Queries[0].RefID] = backend.DataResponse{
+			Frames: data.Frames{data.NewFrame("",
+				data.NewField("value", nil, []*float64{ptr.Float64(1)}),
+			)},
+		}
+		return resp, nil
+	}
+
+	t.Run("identical queries with different refIDs are fetched once", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		qc := NewQueryCache(time.Minute, time.Minute)
+
+		a, err := qc.queryData(context.Background(), newReq("A", `{"expr":"up"}`, from.Add(time.Hour)), fetch)
+		require.NoError(t, err)
+		b, err := qc.queryData(context.Background(), newReq("B", `{"expr":"up"}`, from.Add(time.Hour+time.Millisecond)), fetch)
+		require.NoError(t, err)
+
+		require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		require.Contains(t, a.Responses, "A")
+		require.Contains(t, b.Responses, "B")
+
+		// responses do not share values
+		a.Responses["A"].Frames[0].Set(0, 0, ptr.Float64(2))
+		require.Equal(t, ptr.Float64(1), b.Responses["B"].Frames[0].At(0, 0))
+	})
+
+	t.Run("different queries or time ranges are fetched separately", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		qc := NewQueryCache(time.Minute, time.Minute)
+
+		_, err := qc.queryData(context.Background(), newReq("A", `{"expr":"up"}`, from.Add(time.Hour)), fetch)
+		require.NoError(t, err)
+		_, err = qc.queryData(context.Background(), newReq("A", `{"expr":"down"}`, from.Add(time.Hour)), fetch)
+		require.NoError(t, err)
+		_, err = qc.queryData(context.Background(), newReq("A", `{"expr":"up"}`, from.Add(2*time.Hour)), fetch)
+		require.NoError(t, err)
+
+		require.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("without a TTL only concurrent queries are deduplicated", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		qc := NewQueryCache(0, time.Minute)
+
+		release := make(chan struct{})
+		blockingFetch := func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+			<-release
+			return fetch(ctx, req)
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := qc.queryData(context.Background(), newReq("A", `{"expr":"up"}`, from.Add(time.Hour)), blockingFetch)
+				require.NoError(t, err)
+			}()
+		}
+		// give both queries the time to start before releasing the fetch
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+		_, err := qc.queryData(context.Background(), newReq("A", `{"expr":"up"}`, from.Add(time.Hour)), fetch)
+		require.NoError(t, err)
+		require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("shared queries are not canceled with the caller that issued them", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		qc := NewQueryCache(0, time.Minute)
+		started := make(chan struct{})
+		release := make(chan struct{})
+		blockingFetch := func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+			close(started)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-release:
+			}
+			return fetch(ctx, req)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		firstErr := make(chan error)
+		go func() {
+			_, err := qc.queryData(ctx, newReq("A", `{"expr":"up"}`, from.Add(time.Hour)), blockingFetch)
+			firstErr <- err
+		}()
+		<-started
+
+		second := make(chan error)
+		go func() {
+			_, err := qc.queryData(context.Background(), newReq("B", `{"expr":"up"}`, from.Add(time.Hour)), blockingFetch)
+			second <- err
+		}()
+		// give the second query the time to join the first one
+		time.Sleep(50 * time.Millisecond)
+
+		cancel()
+		require.ErrorIs(t, <-firstErr, context.Canceled)
+		close(release)
+		require.NoError(t, <-second)
+		require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("shared queries are canceled after the timeout", func(t *testing.T) {
+		qc := NewQueryCache(0, 10*time.Millisecond)
+		blockingFetch := func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		_, err := qc.queryData(context.Background(), newReq("A", `{"expr":"up"}`, from.Add(time.Hour)), blockingFetch)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		qc := NewQueryCache(time.Minute, time.Minute)
+		failing := func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+			return nil, errors.New("unavailable")
+		}
+		_, err := qc.queryData(context.Background(), newReq("A", `{"expr":"up"}`, from.Add(time.Hour)), failing)
+		require.EqualError(t, err, "unavailable")
+
+		atomic.StoreInt32(&calls, 0)
+		_, err = qc.queryData(context.Background(), newReq("A", `{"expr":"up"}`, from.Add(time.Hour)), fetch)
+		require.NoError(t, err)
+		require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+}
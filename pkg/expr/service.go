@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -24,6 +25,11 @@ const DatasourceUID = "-100"
 type Service struct {
 	Cfg         *setting.Cfg
 	DataService plugins.DataRequestHandler
+	// QueryCache, if set, deduplicates the queries of data source nodes.
+	QueryCache *QueryCache
+
+	// prefetched, if set, holds the frames data source nodes return instead of querying.
+	prefetched map[string]data.Frames
 }
 
 func (s *Service) isDisabled() bool {
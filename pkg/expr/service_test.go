@@ -3,6 +3,7 @@ package expr
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"sort"
 	"testing"
 	"time"
@@ -80,12 +81,45 @@ func TestService(t *testing.T) {
 	}
 }
 
+// nolint:staticcheck // plugins.DataPlugin deprecated
+func TestServiceNodeError(t *testing.T) {
+	me := &mockEndpoint{
+		Err: errors.New("connection refused"),
+	}
+	s := Service{DataService: me}
+	bus.AddHandler("test", func(query *models.GetDataSourceQuery) error {
+		query.Result = &models.DataSource{Id: 1, OrgId: 1, Type: "test"}
+		return nil
+	})
+
+	queries := []Query{
+		{
+			RefID: "A",
+			JSON:  json.RawMessage(`{ "datasource": "test", "datasourceId": 1, "orgId": 1, "intervalMs": 1000, "maxDataPoints": 1000 }`),
+		},
+		{
+			RefID: "B",
+			JSON:  json.RawMessage(`{ "datasource": "__expr__", "datasourceId": -100, "type": "math", "expression": "$A * 2" }`),
+		},
+	}
+
+	pl, err := s.BuildPipeline(&Request{Queries: queries})
+	require.NoError(t, err)
+
+	_, err = s.ExecutePipeline(context.Background(), pl)
+	var nodeErr *NodeError
+	require.True(t, errors.As(err, &nodeErr))
+	require.Equal(t, "A", nodeErr.RefID)
+	require.EqualError(t, err, "failed to execute query A: connection refused")
+}
+
 func fp(f float64) *float64 {
 	return &f
 }
 
 type mockEndpoint struct {
 	Frames data.Frames
+	Err    error
 }
 
 // nolint:staticcheck // plugins.DataQueryResponse deprecated
@@ -94,6 +128,7 @@ func (me *mockEndpoint) DataQuery(ctx context.Context, ds *models.DataSource, qu
 		Results: map[string]plugins.DataQueryResult{
 			"A": {
 				Dataframes: plugins.NewDecodedDataFrames(me.Frames),
+				Error:      me.Err,
 			},
 		},
 	}, nil
@@ -0,0 +1,240 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/classic"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+const (
+	// ThresholdIsAbove is the evaluator type for "value is above the threshold".
+	ThresholdIsAbove = "gt"
+	// ThresholdIsBelow is the evaluator type for "value is below the threshold".
+	ThresholdIsBelow = "lt"
+	// ThresholdIsWithinRange is the evaluator type for "value is between the two thresholds".
+	ThresholdIsWithinRange = "within_range"
+	// ThresholdIsOutsideRange is the evaluator type for "value is not between the two thresholds".
+	ThresholdIsOutsideRange = "outside_range"
+)
+
+// ThresholdEvaluator compares a single value against one or two thresholds.
+type ThresholdEvaluator struct {
+	Type   string
+	Params []float64
+}
+
+// NewThresholdEvaluator creates a ThresholdEvaluator. It will return an error
+// if the evaluator type is unknown or has the wrong number of parameters.
+func NewThresholdEvaluator(evalType string, params []float64) (*ThresholdEvaluator, error) {
+	switch evalType {
+	case ThresholdIsAbove, ThresholdIsBelow:
+		if len(params) != 1 {
+			return nil, fmt.Errorf("threshold evaluator '%v' requires exactly 1 parameter, got %v", evalType, len(params))
+		}
+	case ThresholdIsWithinRange, ThresholdIsOutsideRange:
+		if len(params) != 2 {
+			return nil, fmt.Errorf("threshold evaluator '%v' requires exactly 2 parameters, got %v", evalType, len(params))
+		}
+	default:
+		return nil, fmt.Errorf("'%v' is not a valid threshold evaluator type", evalType)
+	}
+	return &ThresholdEvaluator{
+		Type:   evalType,
+		Params: params,
+	}, nil
+}
+
+// Eval returns true if f satisfies the evaluator. Range bounds may be given
+// in either order.
+func (te *ThresholdEvaluator) Eval(f float64) bool {
+	switch te.Type {
+	case ThresholdIsAbove:
+		return f > te.Params[0]
+	case ThresholdIsBelow:
+		return f < te.Params[0]
+	case ThresholdIsWithinRange:
+		lower, upper := orderedBounds(te.Params[0], te.Params[1])
+		return lower < f && f < upper
+	case ThresholdIsOutsideRange:
+		lower, upper := orderedBounds(te.Params[0], te.Params[1])
+		return f < lower || f > upper
+	}
+	return false
+}
+
+func orderedBounds(a, b float64) (float64, float64) {
+	if a > b {
+		return b, a
+	}
+	return a, b
+}
+
+// ThresholdCommand is an expression command that compares every Number or
+// Series point of a variable against a threshold evaluator and returns 1
+// when the value satisfies it and 0 when it does not. Null values stay null.
+//
+// When a RecoveryEvaluator is set the command applies hysteresis: values whose
+// labels are in LoadedDimensions (i.e. currently firing) keep returning 1 until
+// the RecoveryEvaluator is satisfied.
+type ThresholdCommand struct {
+	ReferenceVar      string
+	Evaluator         *ThresholdEvaluator
+	RecoveryEvaluator *ThresholdEvaluator
+	LoadedDimensions  map[string]struct{}
+	refID             string
+}
+
+// NewThresholdCommand creates a new ThresholdCommand. recovery may be nil.
+func NewThresholdCommand(refID, referenceVar string, evaluator, recovery *ThresholdEvaluator, loaded []data.Labels) (*ThresholdCommand, error) {
+	if evaluator == nil {
+		return nil, fmt.Errorf("threshold command for refId %v is missing an evaluator", refID)
+	}
+	if recovery == nil && len(loaded) > 0 {
+		return nil, fmt.Errorf("threshold command for refId %v has loaded dimensions but no recovery evaluator", refID)
+	}
+	dims := make(map[string]struct{}, len(loaded))
+	for _, l := range loaded {
+		dims[l.String()] = struct{}{}
+	}
+	return &ThresholdCommand{
+		ReferenceVar:      referenceVar,
+		Evaluator:         evaluator,
+		RecoveryEvaluator: recovery,
+		LoadedDimensions:  dims,
+		refID:             refID,
+	}, nil
+}
+
+// UnmarshalThresholdCommand creates a ThresholdCommand from Grafana's frontend query.
+func UnmarshalThresholdCommand(rn *rawNode) (*ThresholdCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, fmt.Errorf("no variable specified to threshold for refId %v", rn.RefID)
+	}
+	referenceVar, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected threshold variable to be a string, got %T for refId %v", rawVar, rn.RefID)
+	}
+	referenceVar = strings.TrimPrefix(referenceVar, "$")
+
+	evaluator, err := unmarshalThresholdEvaluator(rn.Query["evaluator"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid evaluator in threshold command for refId %v: %w", rn.RefID, err)
+	}
+	if evaluator == nil {
+		return nil, fmt.Errorf("no evaluator specified in threshold command for refId %v", rn.RefID)
+	}
+
+	recovery, err := unmarshalThresholdEvaluator(rn.Query["recoveryEvaluator"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid recovery evaluator in threshold command for refId %v: %w", rn.RefID, err)
+	}
+
+	var loaded []data.Labels
+	if rawLoaded, ok := rn.Query["loadedDimensions"]; ok && rawLoaded != nil {
+		b, err := json.Marshal(rawLoaded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remarshal loaded dimensions for refId %v: %w", rn.RefID, err)
+		}
+		if err := json.Unmarshal(b, &loaded); err != nil {
+			return nil, fmt.Errorf("expected loaded dimensions to be a list of label sets for refId %v: %w", rn.RefID, err)
+		}
+	}
+
+	return NewThresholdCommand(rn.RefID, referenceVar, evaluator, recovery, loaded)
+}
+
+// unmarshalThresholdEvaluator decodes an evaluator object with the same shape as
+// the evaluator of a classic condition. It returns nil if raw is nil.
+func unmarshalThresholdEvaluator(raw interface{}) (*ThresholdEvaluator, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var model classic.ConditionEvalJSON
+	if err := json.Unmarshal(b, &model); err != nil {
+		return nil, err
+	}
+	return NewThresholdEvaluator(model.Type, model.Params)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (tc *ThresholdCommand) NeedsVars() []string {
+	return []string{tc.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (tc *ThresholdCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+	for _, val := range vars[tc.ReferenceVar].Values {
+		evaluator := tc.evaluatorFor(val.GetLabels())
+		switch v := val.(type) {
+		case mathexp.Number:
+			n := mathexp.NewNumber(tc.refID, copyLabels(v.GetLabels()))
+			n.SetValue(evaluator(v.GetFloat64Value()))
+			newRes.Values = append(newRes.Values, n)
+		case mathexp.Scalar:
+			newRes.Values = append(newRes.Values, mathexp.NewScalar(tc.refID, evaluator(v.GetFloat64Value())))
+		case mathexp.Series:
+			s := mathexp.NewSeries(tc.refID, copyLabels(v.GetLabels()), v.Len())
+			for i := 0; i < v.Len(); i++ {
+				t, f := v.GetPoint(i)
+				if err := s.SetPoint(i, t, evaluator(f)); err != nil {
+					return newRes, err
+				}
+			}
+			newRes.Values = append(newRes.Values, s)
+		default:
+			return newRes, fmt.Errorf("can not apply a threshold to type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// evaluatorFor returns the function used for values with the given labels.
+// Dimensions that are currently firing are held at 1 until they recover.
+func (tc *ThresholdCommand) evaluatorFor(labels data.Labels) func(*float64) *float64 {
+	firing := func(f *float64) *float64 {
+		if f == nil {
+			return nil
+		}
+		return boolToFloat(tc.Evaluator.Eval(*f))
+	}
+	if tc.RecoveryEvaluator == nil {
+		return firing
+	}
+	if _, ok := tc.LoadedDimensions[labels.String()]; !ok {
+		return firing
+	}
+	return func(f *float64) *float64 {
+		if f == nil {
+			return nil
+		}
+		return boolToFloat(!tc.RecoveryEvaluator.Eval(*f))
+	}
+}
+
+func boolToFloat(b bool) *float64 {
+	var v float64
+	if b {
+		v = 1
+	}
+	return &v
+}
+
+func copyLabels(l data.Labels) data.Labels {
+	if l == nil {
+		return nil
+	}
+	return l.Copy()
+}
@@ -0,0 +1,161 @@
+package expr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+)
+
+func TestNewThresholdEvaluator(t *testing.T) {
+	var tests = []struct {
+		name     string
+		evalType string
+		params   []float64
+		errIs    require.ErrorAssertionFunc
+	}{
+		{"gt with one param", ThresholdIsAbove, []float64{1}, require.NoError},
+		{"lt without params", ThresholdIsBelow, nil, require.Error},
+		{"within_range with two params", ThresholdIsWithinRange, []float64{1, 2}, require.NoError},
+		{"outside_range with one param", ThresholdIsOutsideRange, []float64{1}, require.Error},
+		{"unknown type", "eq", []float64{1}, require.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewThresholdEvaluator(tt.evalType, tt.params)
+			tt.errIs(t, err)
+		})
+	}
+}
+
+func TestThresholdEvaluatorEval(t *testing.T) {
+	var tests = []struct {
+		name     string
+		evalType string
+		params   []float64
+		value    float64
+		expected bool
+	}{
+		{"3 is gt 1", ThresholdIsAbove, []float64{1}, 3, true},
+		{"1 is not gt 1", ThresholdIsAbove, []float64{1}, 1, false},
+		{"1 is lt 3", ThresholdIsBelow, []float64{3}, 1, true},
+		{"5 is within 1 and 10", ThresholdIsWithinRange, []float64{1, 10}, 5, true},
+		{"5 is within 10 and 1", ThresholdIsWithinRange, []float64{10, 1}, 5, true},
+		{"10 is not within 1 and 10", ThresholdIsWithinRange, []float64{1, 10}, 10, false},
+		{"11 is outside 1 and 10", ThresholdIsOutsideRange, []float64{1, 10}, 11, true},
+		{"5 is not outside 10 and 1", ThresholdIsOutsideRange, []float64{10, 1}, 5, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewThresholdEvaluator(tt.evalType, tt.params)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, e.Eval(tt.value))
+		})
+	}
+}
+
+func TestUnmarshalThresholdCommand(t *testing.T) {
+	t.Run("valid model with hysteresis", func(t *testing.T) {
+		rn := &rawNode{
+			RefID: "C",
+			Query: map[string]interface{}{
+				"expression":        "$B",
+				"evaluator":         map[string]interface{}{"type": "gt", "params": []interface{}{80.0}},
+				"recoveryEvaluator": map[string]interface{}{"type": "lt", "params": []interface{}{70.0}},
+				"loadedDimensions":  []interface{}{map[string]interface{}{"host": "a"}},
+			},
+		}
+		cmd, err := UnmarshalThresholdCommand(rn)
+		require.NoError(t, err)
+		require.Equal(t, []string{"B"}, cmd.NeedsVars())
+		require.Equal(t, ThresholdIsAbove, cmd.Evaluator.Type)
+		require.Equal(t, ThresholdIsBelow, cmd.RecoveryEvaluator.Type)
+		require.Contains(t, cmd.LoadedDimensions, data.Labels{"host": "a"}.String())
+	})
+
+	t.Run("missing evaluator", func(t *testing.T) {
+		rn := &rawNode{RefID: "C", Query: map[string]interface{}{"expression": "$B"}}
+		_, err := UnmarshalThresholdCommand(rn)
+		require.Error(t, err)
+	})
+
+	t.Run("loaded dimensions without recovery evaluator", func(t *testing.T) {
+		rn := &rawNode{
+			RefID: "C",
+			Query: map[string]interface{}{
+				"expression":       "$B",
+				"evaluator":        map[string]interface{}{"type": "gt", "params": []interface{}{80.0}},
+				"loadedDimensions": []interface{}{map[string]interface{}{"host": "a"}},
+			},
+		}
+		_, err := UnmarshalThresholdCommand(rn)
+		require.Error(t, err)
+	})
+}
+
+func TestThresholdCommandExecute(t *testing.T) {
+	gt80, err := NewThresholdEvaluator(ThresholdIsAbove, []float64{80})
+	require.NoError(t, err)
+	lt70, err := NewThresholdEvaluator(ThresholdIsBelow, []float64{70})
+	require.NoError(t, err)
+
+	number := func(labels data.Labels, f *float64) mathexp.Number {
+		n := mathexp.NewNumber("", labels)
+		n.SetValue(f)
+		return n
+	}
+
+	t.Run("numbers without hysteresis", func(t *testing.T) {
+		cmd, err := NewThresholdCommand("C", "B", gt80, nil, nil)
+		require.NoError(t, err)
+
+		vars := mathexp.Vars{"B": mathexp.Results{Values: mathexp.Values{
+			number(data.Labels{"host": "a"}, ptr.Float64(90)),
+			number(data.Labels{"host": "b"}, ptr.Float64(75)),
+			number(data.Labels{"host": "c"}, nil),
+		}}}
+
+		res, err := cmd.Execute(context.Background(), vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 3)
+		require.Equal(t, ptr.Float64(1), res.Values[0].(mathexp.Number).GetFloat64Value())
+		require.Equal(t, ptr.Float64(0), res.Values[1].(mathexp.Number).GetFloat64Value())
+		require.Nil(t, res.Values[2].(mathexp.Number).GetFloat64Value())
+		require.Equal(t, data.Labels{"host": "a"}, res.Values[0].GetLabels())
+	})
+
+	t.Run("firing dimensions use the recovery evaluator", func(t *testing.T) {
+		cmd, err := NewThresholdCommand("C", "B", gt80, lt70, []data.Labels{{"host": "a"}})
+		require.NoError(t, err)
+
+		vars := mathexp.Vars{"B": mathexp.Results{Values: mathexp.Values{
+			number(data.Labels{"host": "a"}, ptr.Float64(75)),
+			number(data.Labels{"host": "b"}, ptr.Float64(75)),
+		}}}
+
+		res, err := cmd.Execute(context.Background(), vars)
+		require.NoError(t, err)
+		require.Equal(t, ptr.Float64(1), res.Values[0].(mathexp.Number).GetFloat64Value())
+		require.Equal(t, ptr.Float64(0), res.Values[1].(mathexp.Number).GetFloat64Value())
+	})
+
+	t.Run("series are evaluated per point", func(t *testing.T) {
+		cmd, err := NewThresholdCommand("C", "B", gt80, nil, nil)
+		require.NoError(t, err)
+
+		s := mathexp.NewSeries("B", data.Labels{"host": "a"}, 2)
+		require.NoError(t, s.SetPoint(0, time.Unix(1, 0), ptr.Float64(81)))
+		require.NoError(t, s.SetPoint(1, time.Unix(2, 0), ptr.Float64(79)))
+
+		res, err := cmd.Execute(context.Background(), mathexp.Vars{"B": mathexp.Results{Values: mathexp.Values{s}}})
+		require.NoError(t, err)
+		out := res.Values[0].(mathexp.Series)
+		require.Equal(t, 2, out.Len())
+		require.Equal(t, ptr.Float64(1), out.GetValue(0))
+		require.Equal(t, ptr.Float64(0), out.GetValue(1))
+	})
+}
@@ -0,0 +1,151 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// TraceRefID is the key of the response that holds the pipeline trace frame
+// when a request is executed with Debug enabled.
+const TraceRefID = "__expr_trace__"
+
+// NodeTrace holds debug information about the execution of a single node.
+type NodeTrace struct {
+	RefID    string
+	NodeType NodeType
+	// Command is the expression command type, it is empty for data source nodes.
+	Command  string
+	Duration time.Duration
+	Warnings []string
+	Error    error
+}
+
+// PipelineTrace holds a NodeTrace for each executed node in execution order.
+type PipelineTrace []NodeTrace
+
+type warningsKey struct{}
+
+// nodeWarnings collects the warnings of a single node while it executes.
+type nodeWarnings struct {
+	msgs []string
+}
+
+// addWarning records a warning for the node being executed with ctx. It does
+// nothing if the pipeline is not being traced.
+func addWarning(ctx context.Context, format string, args ...interface{}) {
+	w, ok := ctx.Value(warningsKey{}).(*nodeWarnings)
+	if !ok {
+		return
+	}
+	w.msgs = append(w.msgs, fmt.Sprintf(format, args...))
+}
+
+// executeTraced is like execute, but records a NodeTrace for each node. Execution
+// stops at the first node that fails, its error is part of the trace and not returned.
+func (dp *DataPipeline) executeTraced(c context.Context, s *Service) (mathexp.Vars, PipelineTrace) {
+	vars := make(mathexp.Vars)
+	trace := make(PipelineTrace, 0, len(*dp))
+	for _, node := range *dp {
+		w := &nodeWarnings{}
+		nt := NodeTrace{
+			RefID:    node.RefID(),
+			NodeType: node.NodeType(),
+		}
+		if cmdNode, ok := node.(*CMDNode); ok {
+			nt.Command = cmdNode.CMDType.String()
+		}
+
+		start := time.Now()
+		res, err := node.Execute(context.WithValue(c, warningsKey{}, w), vars, s)
+		nt.Duration = time.Since(start)
+		nt.Warnings = w.msgs
+		nt.Error = err
+		trace = append(trace, nt)
+		if err != nil {
+			break
+		}
+		vars[node.RefID()] = res
+	}
+	return vars, trace
+}
+
+// ExecutePipelineWithTrace executes an expression pipeline and returns the results of
+// every node. Each node's frames carry its warnings as notices and its execution time
+// as a stat, and the response for TraceRefID holds a frame describing the execution
+// order, timings, warnings and errors of all nodes. A failing node does not fail the
+// request: its error is set on its response and nodes depending on it are not executed.
+func (s *Service) ExecutePipelineWithTrace(ctx context.Context, pipeline DataPipeline) (*backend.QueryDataResponse, error) {
+	res := backend.NewQueryDataResponse()
+	vars, trace := pipeline.executeTraced(ctx, s)
+
+	for _, nt := range trace {
+		if nt.Error != nil {
+			res.Responses[nt.RefID] = backend.DataResponse{Error: nt.Error}
+			continue
+		}
+		frames := vars[nt.RefID].Values.AsDataFrames(nt.RefID)
+		for _, frame := range frames {
+			addTraceMeta(frame, nt)
+		}
+		res.Responses[nt.RefID] = backend.DataResponse{Frames: frames}
+	}
+
+	res.Responses[TraceRefID] = backend.DataResponse{
+		Frames: data.Frames{trace.Frame()},
+	}
+	return res, nil
+}
+
+// addTraceMeta adds the warnings and duration of a node to the metadata of frame,
+// keeping any metadata that is already set.
+func addTraceMeta(frame *data.Frame, nt NodeTrace) {
+	if frame.Meta == nil {
+		frame.Meta = &data.FrameMeta{}
+	}
+	for _, w := range nt.Warnings {
+		frame.Meta.Notices = append(frame.Meta.Notices, data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     w,
+		})
+	}
+	frame.Meta.Stats = append(frame.Meta.Stats, data.QueryStat{
+		FieldConfig: data.FieldConfig{DisplayName: "Execution time", Unit: "ms"},
+		Value:       float64(nt.Duration) / float64(time.Millisecond),
+	})
+}
+
+// Frame returns the trace as a data frame with one row per node.
+func (pt PipelineTrace) Frame() *data.Frame {
+	frame := data.NewFrame("trace",
+		data.NewField("order", nil, []int64{}),
+		data.NewField("refId", nil, []string{}),
+		data.NewField("nodeType", nil, []string{}),
+		data.NewField("command", nil, []string{}),
+		data.NewField("duration", nil, []float64{}).SetConfig(&data.FieldConfig{Unit: "ms"}),
+		data.NewField("warnings", nil, []string{}),
+		data.NewField("error", nil, []string{}),
+	)
+	for i, nt := range pt {
+		errString := ""
+		if nt.Error != nil {
+			errString = nt.Error.Error()
+		}
+		frame.AppendRow(
+			int64(i),
+			nt.RefID,
+			nt.NodeType.String(),
+			nt.Command,
+			float64(nt.Duration)/float64(time.Millisecond),
+			strings.Join(nt.Warnings, "\n"),
+			errString,
+		)
+	}
+	frame.RefID = TraceRefID
+	return frame
+}
@@ -0,0 +1,85 @@
+package expr
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+)
+
+// fixedNode is a data source node stand-in that returns fixed results.
+type fixedNode struct {
+	baseNode
+	res mathexp.Results
+	err error
+}
+
+func (fn *fixedNode) NodeType() NodeType { return TypeDatasourceNode }
+
+func (fn *fixedNode) Execute(ctx context.Context, vars mathexp.Vars, s *Service) (mathexp.Results, error) {
+	return fn.res, fn.err
+}
+
+func TestExecutePipelineWithTrace(t *testing.T) {
+	number := func(labels data.Labels, f *float64) mathexp.Number {
+		n := mathexp.NewNumber("", labels)
+		n.SetValue(f)
+		return n
+	}
+
+	a := &fixedNode{
+		baseNode: baseNode{id: 1, refID: "A"},
+		res: mathexp.Results{Values: mathexp.Values{
+			number(data.Labels{"host": "a"}, ptr.Float64(1)),
+			number(data.Labels{"host": "b"}, ptr.Float64(2)),
+		}},
+	}
+	b := &fixedNode{
+		baseNode: baseNode{id: 2, refID: "B"},
+		res: mathexp.Results{Values: mathexp.Values{
+			number(data.Labels{"host": "a"}, nil),
+		}},
+	}
+	mathCmd, err := NewMathCommand("C", "$A + $B")
+	require.NoError(t, err)
+	c := &CMDNode{baseNode: baseNode{id: 3, refID: "C"}, CMDType: TypeMath, Command: mathCmd}
+
+	t.Run("records order, warnings and timings", func(t *testing.T) {
+		s := &Service{}
+		res, err := s.ExecutePipelineWithTrace(context.Background(), DataPipeline{a, b, c})
+		require.NoError(t, err)
+
+		cFrames := res.Responses["C"].Frames
+		require.Len(t, cFrames, 1)
+		require.NotNil(t, cFrames[0].Meta)
+		require.Len(t, cFrames[0].Meta.Stats, 1)
+		require.Len(t, cFrames[0].Meta.Notices, 2) // one value of $A dropped, null in $B
+
+		trace := res.Responses[TraceRefID].Frames[0]
+		require.Equal(t, 3, trace.Rows())
+		for i, refID := range []string{"A", "B", "C"} {
+			require.Equal(t, refID, trace.At(1, i))
+		}
+		require.Equal(t, "math", trace.At(3, 2))
+		require.Empty(t, trace.At(6, 2))
+	})
+
+	t.Run("a failing node is reported and stops execution", func(t *testing.T) {
+		failing := &fixedNode{baseNode: baseNode{id: 2, refID: "B"}, err: errors.New("boom")}
+		s := &Service{}
+		res, err := s.ExecutePipelineWithTrace(context.Background(), DataPipeline{a, failing, c})
+		require.NoError(t, err)
+
+		require.EqualError(t, res.Responses["B"].Error, "boom")
+		_, executed := res.Responses["C"]
+		require.False(t, executed)
+
+		trace := res.Responses[TraceRefID].Frames[0]
+		require.Equal(t, 2, trace.Rows())
+		require.Equal(t, "boom", trace.At(6, 1))
+	})
+}
@@ -35,6 +35,7 @@ func init() {
 // WrapTransformData creates and executes transform requests
 func (s *Service) WrapTransformData(ctx context.Context, query plugins.DataQuery) (*backend.QueryDataResponse, error) {
 	req := Request{
+		Debug:   query.Debug,
 		OrgId:   query.User.OrgId,
 		Queries: []Query{},
 	}
@@ -62,6 +63,8 @@ func (s *Service) WrapTransformData(ctx context.Context, query plugins.DataQuery
 // Request is similar to plugins.DataQuery but with the Time Ranges is per Query.
 type Request struct {
 	Headers map[string]string
+	// Debug executes the request with ExecutePipelineWithTrace and returns
+	// the results of hidden queries.
 	Debug   bool
 	OrgId   int64
 	Queries []Query
@@ -112,6 +115,10 @@ func (s *Service) TransformData(ctx context.Context, req *Request) (r *backend.Q
 		return nil, err
 	}
 
+	if req.Debug {
+		return s.ExecutePipelineWithTrace(ctx, pipeline)
+	}
+
 	// Execute the pipeline
 	responses, err := s.ExecutePipeline(ctx, pipeline)
 	if err != nil {
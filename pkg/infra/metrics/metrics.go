@@ -108,6 +108,9 @@ var (
 
 	// MAccessEvaluationCount is a metric gauge for total number of evaluation requests
 	MAccessEvaluationCount prometheus.Counter
+
+	// MExpressionsQueryCacheTotal is a metric counter for data source queries of expressions served from the query cache or not
+	MExpressionsQueryCacheTotal *prometheus.CounterVec
 )
 
 // Timers
@@ -352,6 +355,12 @@ func init() {
 		Namespace: ExporterName,
 	}, []string{"type"})
 
+	MExpressionsQueryCacheTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "expressions_query_cache_total",
+		Help:      "counter for data source queries of expressions by query cache result (hit or miss)",
+		Namespace: ExporterName,
+	}, []string{"result"})
+
 	MAwsCloudWatchGetMetricStatistics = newCounterStartingAtZero(prometheus.CounterOpts{
 		Name:      "aws_cloudwatch_get_metric_statistics_total",
 		Help:      "counter for getting metric statistics from aws",
@@ -629,6 +638,7 @@ func initMetricVars() {
 		MAlertingResultState,
 		MAlertingNotificationSent,
 		MAlertingNotificationFailed,
+		MExpressionsQueryCacheTotal,
 		MAwsCloudWatchGetMetricStatistics,
 		MAwsCloudWatchListMetrics,
 		MAwsCloudWatchGetMetricData,
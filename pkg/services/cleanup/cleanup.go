@@ -15,15 +15,17 @@ import (
 	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/ngalert"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
 func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockService,
-	shortURLService shorturls.Service) *CleanUpService {
+	shortURLService shorturls.Service, alertNG *ngalert.AlertNG) *CleanUpService {
 	s := &CleanUpService{
 		Cfg:               cfg,
 		ServerLockService: serverLockService,
 		ShortURLService:   shortURLService,
+		AlertNG:           alertNG,
 		log:               log.New("cleanup"),
 	}
 	return s
@@ -34,6 +36,7 @@ type CleanUpService struct {
 	Cfg               *setting.Cfg
 	ServerLockService *serverlock.ServerLockService
 	ShortURLService   shorturls.Service
+	AlertNG           *ngalert.AlertNG
 }
 
 func (srv *CleanUpService) Run(ctx context.Context) error {
@@ -52,6 +55,9 @@ func (srv *CleanUpService) Run(ctx context.Context) error {
 			srv.cleanUpOldAnnotations(ctxWithTimeout)
 			srv.expireOldUserInvites()
 			srv.deleteStaleShortURLs()
+			srv.deleteExpiredAlertStateHistory()
+			srv.deleteExpiredAlertImages(ctxWithTimeout)
+			srv.deleteExpiredNotificationDeliveries(ctxWithTimeout)
 			err := srv.ServerLockService.LockAndExecute(ctx, "delete old login attempts",
 				time.Minute*10, func(context.Context) {
 					srv.deleteOldLoginAttempts()
@@ -181,3 +187,39 @@ func (srv *CleanUpService) deleteStaleShortURLs() {
 		srv.log.Debug("Deleted short urls", "rows affected", cmd.NumDeleted)
 	}
 }
+
+func (srv *CleanUpService) deleteExpiredAlertImages(ctx context.Context) {
+	if srv.AlertNG == nil {
+		return
+	}
+	affected, err := srv.AlertNG.DeleteExpiredImages(ctx)
+	if err != nil {
+		srv.log.Error("Problem deleting expired alert images", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted expired alert images", "rows affected", affected)
+	}
+}
+
+func (srv *CleanUpService) deleteExpiredNotificationDeliveries(ctx context.Context) {
+	if srv.AlertNG == nil {
+		return
+	}
+	affected, err := srv.AlertNG.DeleteExpiredNotificationDeliveries(ctx)
+	if err != nil {
+		srv.log.Error("Problem deleting expired alert notification deliveries", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted expired alert notification deliveries", "rows affected", affected)
+	}
+}
+
+func (srv *CleanUpService) deleteExpiredAlertStateHistory() {
+	if srv.AlertNG == nil {
+		return
+	}
+	affected, err := srv.AlertNG.DeleteExpiredStateHistory()
+	if err != nil {
+		srv.log.Error("Problem deleting expired alert state history", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted expired alert state history", "rows affected", affected)
+	}
+}
@@ -3,6 +3,7 @@ package remotewrite
 import (
 	"fmt"
 	"hash/fnv"
+	"sort"
 	"strings"
 	"time"
 
@@ -190,6 +191,43 @@ func TimeSeriesFromFramesLabelsColumn(frames ...*data.Frame) []prompb.TimeSeries
 	return promTimeSeriesBatch
 }
 
+// TimeSeriesFromFields converts the first value of each numeric field to a slice of
+// Prometheus TimeSeries with a sample at tm. The time series are named after the fields.
+func TimeSeriesFromFields(tm time.Time, fields ...*data.Field) []prompb.TimeSeries {
+	promTimeSeriesBatch := make([]prompb.TimeSeries, 0, len(fields))
+	for _, field := range fields {
+		if !field.Type().Numeric() || field.Len() == 0 {
+			continue
+		}
+		metricName, ok := sanitizeMetricName(field.Name)
+		if !ok {
+			continue
+		}
+		val, ok := field.ConcreteAt(0)
+		if !ok {
+			continue
+		}
+		value, ok := sampleValue(val)
+		if !ok {
+			continue
+		}
+
+		labels := createLabels(field.Labels)
+		labels = append(labels, prompb.Label{
+			Name:  "__name__",
+			Value: metricName,
+		})
+		sort.Slice(labels, func(i, j int) bool {
+			return labels[i].Name < labels[j].Name
+		})
+		promTimeSeriesBatch = append(promTimeSeriesBatch, prompb.TimeSeries{
+			Labels:  labels,
+			Samples: []prompb.Sample{{Timestamp: toSampleTime(tm), Value: value}},
+		})
+	}
+	return promTimeSeriesBatch
+}
+
 func timeFieldIndex(frame *data.Frame) (int, bool) {
 	timeFieldIndex := -1
 	for i, field := range frame.Fields {
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
 	"github.com/stretchr/testify/require"
 )
 
@@ -85,3 +86,21 @@ func TestSerialize(t *testing.T) {
 	_, err := Serialize(frame)
 	require.NoError(t, err)
 }
+
+func TestTsFromFields(t *testing.T) {
+	tm := time.Now()
+	v1, v2 := 1.0, 2.0
+	fields := []*data.Field{
+		data.NewField("test_value", map[string]string{"test": "yes"}, []*float64{&v1}),
+		data.NewField("test_value", map[string]string{"test": "no"}, []*float64{&v2}),
+		data.NewField("test_value", map[string]string{"test": "null"}, []*float64{nil}),
+		data.NewField("test_value", nil, []*float64{}),
+		data.NewField("test_string", nil, []string{"3"}),
+	}
+	ts := TimeSeriesFromFields(tm, fields...)
+	require.Len(t, ts, 2)
+	require.Equal(t, []prompb.Label{{Name: "__name__", Value: "test_value"}, {Name: "test", Value: "yes"}}, ts[0].Labels)
+	require.Equal(t, []prompb.Sample{{Timestamp: toSampleTime(tm), Value: 1.0}}, ts[0].Samples)
+	require.Equal(t, []prompb.Label{{Name: "__name__", Value: "test_value"}, {Name: "test", Value: "no"}}, ts[1].Labels)
+	require.Equal(t, []prompb.Sample{{Timestamp: toSampleTime(tm), Value: 2.0}}, ts[1].Samples)
+}
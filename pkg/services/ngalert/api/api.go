@@ -12,7 +12,9 @@ import (
 	"github.com/grafana/grafana/pkg/services/encryption"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
@@ -27,6 +29,7 @@ var timeNow = time.Now
 type Scheduler interface {
 	AlertmanagersFor(orgID int64) []*url.URL
 	DroppedAlertmanagersFor(orgID int64) []*url.URL
+	RuleHealth(orgID int64) map[string]ngmodels.AlertRuleHealth
 }
 
 type Alertmanager interface {
@@ -47,6 +50,7 @@ type Alertmanager interface {
 
 	// Testing
 	TestReceivers(ctx context.Context, c apimodels.TestReceiversConfigParams) (*notifier.TestReceiversResult, error)
+	TestTemplate(ctx context.Context, c apimodels.TestTemplatesConfigBodyParams) (*notifier.TestTemplatesResults, error)
 }
 
 // API handlers.
@@ -61,10 +65,14 @@ type API struct {
 	InstanceStore        store.InstanceStore
 	AlertingStore        store.AlertingStore
 	AdminConfigStore     store.AdminConfigurationStore
+	StateHistoryStore    store.StateHistoryStore
+	DeliveryStore        store.DeliveryStore
+	ProvenanceStore      store.ProvisioningStore
 	DataProxy            *datasourceproxy.DataSourceProxyService
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
 	StateManager         *state.Manager
 	EncryptionService    encryption.Service
+	ProvisioningService  *provisioning.Service
 }
 
 // RegisterAPIEndpoints registers API handlers
@@ -78,19 +86,19 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 	api.RegisterAlertmanagerApiEndpoints(NewForkedAM(
 		api.DatasourceCache,
 		NewLotexAM(proxy, logger),
-		AlertmanagerSrv{store: api.AlertingStore, mam: api.MultiOrgAlertmanager, enc: api.EncryptionService, log: logger},
+		AlertmanagerSrv{store: api.AlertingStore, provenanceStore: api.ProvenanceStore, mam: api.MultiOrgAlertmanager, enc: api.EncryptionService, log: logger},
 	), m)
 	// Register endpoints for proxying to Prometheus-compatible backends.
 	api.RegisterPrometheusApiEndpoints(NewForkedProm(
 		api.DatasourceCache,
 		NewLotexProm(proxy, logger),
-		PrometheusSrv{log: logger, manager: api.StateManager, store: api.RuleStore},
+		PrometheusSrv{log: logger, manager: api.StateManager, store: api.RuleStore, scheduler: api.Schedule},
 	), m)
 	// Register endpoints for proxying to Cortex Ruler-compatible backends.
 	api.RegisterRulerApiEndpoints(NewForkedRuler(
 		api.DatasourceCache,
 		NewLotexRuler(proxy, logger),
-		RulerSrv{DatasourceCache: api.DatasourceCache, QuotaService: api.QuotaService, manager: api.StateManager, store: api.RuleStore, log: logger},
+		RulerSrv{DatasourceCache: api.DatasourceCache, QuotaService: api.QuotaService, manager: api.StateManager, store: api.RuleStore, provenanceStore: api.ProvenanceStore, log: logger},
 	), m)
 	api.RegisterTestingApiEndpoints(TestingApiSrv{
 		AlertingProxy:   proxy,
@@ -99,6 +107,23 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		DatasourceCache: api.DatasourceCache,
 		log:             logger,
 	}, m)
+	api.RegisterHistoryApiEndpoints(HistorySrv{
+		log:   logger,
+		store: api.StateHistoryStore,
+	}, m)
+	api.RegisterDeliveryApiEndpoints(DeliverySrv{
+		log:   logger,
+		store: api.DeliveryStore,
+	}, m)
+	api.RegisterHealthApiEndpoints(HealthSrv{
+		log:       logger,
+		store:     api.RuleStore,
+		scheduler: api.Schedule,
+	}, m)
+	api.RegisterProvisioningApiEndpoints(ProvisioningSrv{
+		log:     logger,
+		service: api.ProvisioningService,
+	}, m)
 	api.RegisterConfigurationApiEndpoints(AdminSrv{
 		store:     api.AdminConfigStore,
 		log:       logger,
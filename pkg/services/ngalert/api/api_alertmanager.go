@@ -29,10 +29,11 @@ const (
 )
 
 type AlertmanagerSrv struct {
-	mam   *notifier.MultiOrgAlertmanager
-	enc   encryption.Service
-	store store.AlertingStore
-	log   log.Logger
+	mam             *notifier.MultiOrgAlertmanager
+	enc             encryption.Service
+	store           store.AlertingStore
+	provenanceStore store.ProvisioningStore
+	log             log.Logger
 }
 
 type UnknownReceiverError struct {
@@ -155,6 +156,10 @@ func (srv AlertmanagerSrv) RouteDeleteAlertingConfig(c *models.ReqContext) respo
 		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
 	}
 
+	if errResp := srv.checkNotProvisioned(c.OrgId); errResp != nil {
+		return errResp
+	}
+
 	am, errResp := srv.AlertmanagerFor(c.OrgId)
 	if errResp != nil {
 		return errResp
@@ -339,6 +344,10 @@ func (srv AlertmanagerSrv) RoutePostAlertingConfig(c *models.ReqContext, body ap
 		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
 	}
 
+	if errResp := srv.checkNotProvisioned(c.OrgId); errResp != nil {
+		return errResp
+	}
+
 	// Get the last known working configuration
 	query := ngmodels.GetLatestAlertmanagerConfigurationQuery{OrgID: c.OrgId}
 	if err := srv.store.GetLatestAlertmanagerConfiguration(&query); err != nil {
@@ -420,6 +429,27 @@ func (srv AlertmanagerSrv) RoutePostTestReceivers(c *models.ReqContext, body api
 	return response.JSON(statusForTestReceivers(result.Receivers), newTestReceiversResult(result))
 }
 
+func (srv AlertmanagerSrv) RoutePostTestTemplates(c *models.ReqContext, body apimodels.TestTemplatesConfigBodyParams) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	result, err := am.TestTemplate(c.Req.Context(), body)
+	if err != nil {
+		if errors.Is(err, notifier.ErrInvalidTemplateName) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to test the template")
+	}
+
+	return response.JSON(http.StatusOK, newTestTemplatesResults(result))
+}
+
 // contextWithTimeoutFromRequest returns a context with a deadline set from the
 // Request-Timeout header in the HTTP request. If the header is absent then the
 // context will use the default timeout. The timeout in the Request-Timeout
@@ -467,6 +497,25 @@ func newTestReceiversResult(r *notifier.TestReceiversResult) apimodels.TestRecei
 	return v
 }
 
+func newTestTemplatesResults(r *notifier.TestTemplatesResults) apimodels.TestTemplatesResults {
+	v := apimodels.TestTemplatesResults{
+		Results: make([]apimodels.TestTemplatesResult, 0, len(r.Results)),
+		Errors:  make([]apimodels.TestTemplatesErrorResult, 0, len(r.Errors)),
+	}
+	for _, next := range r.Results {
+		v.Results = append(v.Results, apimodels.TestTemplatesResult{Name: next.Name, Text: next.Text})
+	}
+	for _, next := range r.Errors {
+		v.Errors = append(v.Errors, apimodels.TestTemplatesErrorResult{
+			Name:    next.Name,
+			Kind:    next.Kind,
+			Message: next.Err.Error(),
+			Line:    next.Line,
+		})
+	}
+	return v
+}
+
 // statusForTestReceivers returns the appropriate status code for the response
 // for the results.
 //
@@ -528,3 +577,16 @@ func (srv AlertmanagerSrv) AlertmanagerFor(orgID int64) (Alertmanager, *response
 	srv.log.Error("unable to obtain the org's Alertmanager", "err", err)
 	return nil, response.Error(http.StatusInternalServerError, "unable to obtain org's Alertmanager", err)
 }
+
+// checkNotProvisioned returns a conflict response if the Alertmanager configuration of
+// the organization is provisioned from files.
+func (srv AlertmanagerSrv) checkNotProvisioned(orgID int64) response.Response {
+	provenance, err := srv.provenanceStore.GetProvenance(orgID, ngmodels.ProvisionedAlertmanagerConfiguration, "")
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the provenance of the Alertmanager configuration")
+	}
+	if provenance != ngmodels.ProvenanceNone {
+		return ErrResp(http.StatusConflict, ngmodels.ErrProvisionedResource, "")
+	}
+	return nil
+}
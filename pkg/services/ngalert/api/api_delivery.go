@@ -0,0 +1,92 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// defaultNotificationDeliveriesLimit is the maximum number of attempts to send notifications
+// returned when the request has no limit.
+const defaultNotificationDeliveriesLimit = 1000
+
+type DeliverySrv struct {
+	log   log.Logger
+	store store.DeliveryStore
+}
+
+func (srv DeliverySrv) RouteGetNotificationDeliveries(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
+	}
+	if srv.store == nil {
+		return ErrResp(http.StatusNotFound, errors.New("the delivery log of contact points is disabled"), "")
+	}
+
+	q := ngmodels.ListNotificationDeliveriesQuery{
+		OrgID:          c.SignedInUser.OrgId,
+		Receiver:       web.Params(c.Req)[":Receiver"],
+		IntegrationUID: c.Query("integrationUID"),
+		Limit:          defaultNotificationDeliveriesLimit,
+	}
+
+	if status := c.Query("status"); status != "" {
+		switch s := ngmodels.NotificationDeliveryStatus(status); s {
+		case ngmodels.NotificationDelivered, ngmodels.NotificationFailed, ngmodels.NotificationDropped:
+			q.Status = s
+		default:
+			return ErrResp(http.StatusBadRequest, errors.New("status must be one of delivered, failed or dropped"), "")
+		}
+	}
+
+	if from := c.QueryInt64("from"); from > 0 {
+		q.From = time.Unix(0, from*int64(time.Millisecond))
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		q.To = time.Unix(0, to*int64(time.Millisecond))
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && q.From.After(q.To) {
+		return ErrResp(http.StatusBadRequest, errors.New("from must not be after to"), "")
+	}
+
+	if c.Query("limit") != "" {
+		limit := c.QueryInt("limit")
+		if limit <= 0 {
+			return ErrResp(http.StatusBadRequest, errors.New("limit must be a positive number"), "")
+		}
+		q.Limit = limit
+	}
+
+	if err := srv.store.ListNotificationDeliveries(c.Req.Context(), &q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get notification deliveries")
+	}
+
+	return response.JSON(http.StatusOK, apimodels.NotificationDeliveriesResponse{Deliveries: toNotificationDeliveries(q.Result)})
+}
+
+func toNotificationDeliveries(deliveries []*ngmodels.NotificationDelivery) []apimodels.NotificationDelivery {
+	result := make([]apimodels.NotificationDelivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		result = append(result, apimodels.NotificationDelivery{
+			IntegrationType: d.IntegrationType,
+			IntegrationUID:  d.IntegrationUID,
+			GroupKey:        d.GroupKey,
+			Alerts:          d.Alerts,
+			Attempt:         d.Attempt,
+			Status:          string(d.Status),
+			StatusCode:      d.StatusCode,
+			Error:           d.Error,
+			Duration:        (time.Duration(d.DurationMs) * time.Millisecond).Seconds(),
+			SentAt:          d.SentAt,
+		})
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type fakeDeliveryStore struct {
+	query      *ngmodels.ListNotificationDeliveriesQuery
+	deliveries []*ngmodels.NotificationDelivery
+}
+
+func (f *fakeDeliveryStore) SaveNotificationDelivery(context.Context, *ngmodels.NotificationDelivery) error {
+	return nil
+}
+
+func (f *fakeDeliveryStore) ListNotificationDeliveries(_ context.Context, q *ngmodels.ListNotificationDeliveriesQuery) error {
+	f.query = q
+	q.Result = f.deliveries
+	return nil
+}
+
+func (f *fakeDeliveryStore) DeleteNotificationDeliveries(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestRouteGetNotificationDeliveries(t *testing.T) {
+	sentAt := time.Unix(1000, 0)
+
+	newRequest := func(role models.RoleType, query string) *models.ReqContext {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/receivers/team/deliveries?"+query, nil)
+		req = web.SetURLParams(req, map[string]string{":Receiver": "team"})
+		return &models.ReqContext{
+			Context:      &web.Context{Req: req},
+			SignedInUser: &models.SignedInUser{OrgId: 1, OrgRole: role},
+		}
+	}
+
+	t.Run("viewers are not allowed to read the delivery log", func(t *testing.T) {
+		srv := DeliverySrv{log: log.New("test"), store: &fakeDeliveryStore{}}
+		res := srv.RouteGetNotificationDeliveries(newRequest(models.ROLE_VIEWER, ""))
+		require.Equal(t, http.StatusForbidden, res.Status())
+	})
+
+	t.Run("the delivery log can be disabled", func(t *testing.T) {
+		srv := DeliverySrv{log: log.New("test")}
+		res := srv.RouteGetNotificationDeliveries(newRequest(models.ROLE_EDITOR, ""))
+		require.Equal(t, http.StatusNotFound, res.Status())
+	})
+
+	t.Run("invalid queries are rejected", func(t *testing.T) {
+		srv := DeliverySrv{log: log.New("test"), store: &fakeDeliveryStore{}}
+		for _, query := range []string{"status=unknown", "limit=0", "from=2000&to=1000"} {
+			res := srv.RouteGetNotificationDeliveries(newRequest(models.ROLE_EDITOR, query))
+			require.Equal(t, http.StatusBadRequest, res.Status(), query)
+		}
+	})
+
+	t.Run("the attempts of the contact point are returned", func(t *testing.T) {
+		store := &fakeDeliveryStore{deliveries: []*ngmodels.NotificationDelivery{
+			{OrgID: 1, Receiver: "team", IntegrationType: "slack", IntegrationUID: "slack", GroupKey: "{}:{}", Alerts: 2, Attempt: 2, Status: ngmodels.NotificationFailed, StatusCode: 503, Error: "unavailable", DurationMs: 1500, SentAt: sentAt},
+		}}
+		srv := DeliverySrv{log: log.New("test"), store: store}
+
+		res := srv.RouteGetNotificationDeliveries(newRequest(models.ROLE_EDITOR, "integrationUID=slack&status=failed&from=1000&to=2000&limit=10"))
+		require.Equal(t, http.StatusOK, res.Status())
+
+		require.Equal(t, ngmodels.ListNotificationDeliveriesQuery{
+			OrgID:          1,
+			Receiver:       "team",
+			IntegrationUID: "slack",
+			Status:         ngmodels.NotificationFailed,
+			From:           time.Unix(1, 0),
+			To:             time.Unix(2, 0),
+			Limit:          10,
+			Result:         store.deliveries,
+		}, *store.query)
+
+		var body apimodels.NotificationDeliveriesResponse
+		require.NoError(t, json.Unmarshal(res.Body(), &body))
+		require.Len(t, body.Deliveries, 1)
+		d := body.Deliveries[0]
+		require.Equal(t, "slack", d.IntegrationType)
+		require.Equal(t, 2, d.Attempt)
+		require.Equal(t, "failed", d.Status)
+		require.Equal(t, 503, d.StatusCode)
+		require.Equal(t, "unavailable", d.Error)
+		require.Equal(t, 1.5, d.Duration)
+		require.True(t, sentAt.Equal(d.SentAt))
+	})
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+type HealthSrv struct {
+	log       log.Logger
+	store     store.RuleStore
+	scheduler Scheduler
+}
+
+func (srv HealthSrv) RouteGetUnhealthyRules(c *models.ReqContext) response.Response {
+	result := apimodels.UnhealthyRulesResponse{Rules: []apimodels.UnhealthyRule{}}
+
+	ruleHealth := srv.scheduler.RuleHealth(c.SignedInUser.OrgId)
+	unhealthy := 0
+	for _, h := range ruleHealth {
+		if h.Health == ngmodels.RuleHealthError {
+			unhealthy++
+		}
+	}
+	if unhealthy == 0 {
+		return response.JSON(http.StatusOK, result)
+	}
+
+	namespaces, err := srv.store.GetNamespaces(c.Req.Context(), c.SignedInUser.OrgId, c.SignedInUser)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespaces visible to the user")
+	}
+	q := ngmodels.ListAlertRulesQuery{OrgID: c.SignedInUser.OrgId}
+	if err := srv.store.GetOrgAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rules")
+	}
+
+	result.Rules = unhealthyRules(q.Result, namespaces, ruleHealth)
+	return response.JSON(http.StatusOK, result)
+}
+
+// unhealthyRules returns the alert rules whose last evaluation failed, in the namespaces
+// visible to the user, ordered by the time since they have been failing.
+func unhealthyRules(rules []*ngmodels.AlertRule, namespaces map[string]*models.Folder, ruleHealth map[string]ngmodels.AlertRuleHealth) []apimodels.UnhealthyRule {
+	result := make([]apimodels.UnhealthyRule, 0)
+	for _, rule := range rules {
+		h, ok := ruleHealth[rule.UID]
+		if !ok || h.Health != ngmodels.RuleHealthError {
+			continue
+		}
+		namespace, ok := namespaces[rule.NamespaceUID]
+		if !ok {
+			continue
+		}
+		result = append(result, apimodels.UnhealthyRule{
+			UID:                 rule.UID,
+			Title:               rule.Title,
+			NamespaceUID:        rule.NamespaceUID,
+			Namespace:           namespace.Title,
+			RuleGroup:           rule.RuleGroup,
+			LastError:           h.LastError,
+			LastErrorRefID:      h.LastErrorRefID,
+			LastEvaluation:      h.LastEvaluation,
+			EvaluationTime:      h.EvaluationDuration.Seconds(),
+			Attempts:            h.Attempts,
+			ConsecutiveFailures: h.ConsecutiveFailures,
+			FailingSince:        h.FailingSince,
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].FailingSince.Before(result[j].FailingSince)
+	})
+	return result
+}
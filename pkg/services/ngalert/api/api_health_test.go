@@ -0,0 +1,57 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestUnhealthyRules(t *testing.T) {
+	now := time.Unix(1000, 0)
+	rules := []*ngmodels.AlertRule{
+		{UID: "healthy", Title: "Healthy", NamespaceUID: "folder", RuleGroup: "group"},
+		{UID: "recent", Title: "Recent", NamespaceUID: "folder", RuleGroup: "group"},
+		{UID: "old", Title: "Old", NamespaceUID: "folder", RuleGroup: "group"},
+		{UID: "hidden", Title: "Hidden", NamespaceUID: "private", RuleGroup: "group"},
+		{UID: "unknown", Title: "Unknown", NamespaceUID: "folder", RuleGroup: "group"},
+	}
+	namespaces := map[string]*models.Folder{"folder": {Uid: "folder", Title: "Folder"}}
+	ruleHealth := map[string]ngmodels.AlertRuleHealth{
+		"healthy": {Health: ngmodels.RuleHealthOK, LastEvaluation: now},
+		"recent": {
+			Health:              ngmodels.RuleHealthError,
+			LastEvaluation:      now,
+			EvaluationDuration:  2 * time.Second,
+			LastError:           "failed to execute query A: timeout",
+			LastErrorRefID:      "A",
+			Attempts:            3,
+			ConsecutiveFailures: 1,
+			FailingSince:        now,
+		},
+		"old":    {Health: ngmodels.RuleHealthError, LastEvaluation: now, FailingSince: now.Add(-time.Hour)},
+		"hidden": {Health: ngmodels.RuleHealthError, LastEvaluation: now, FailingSince: now},
+	}
+
+	result := unhealthyRules(rules, namespaces, ruleHealth)
+	require.Len(t, result, 2)
+	require.Equal(t, "old", result[0].UID)
+	require.Equal(t, apimodels.UnhealthyRule{
+		UID:                 "recent",
+		Title:               "Recent",
+		NamespaceUID:        "folder",
+		Namespace:           "Folder",
+		RuleGroup:           "group",
+		LastError:           "failed to execute query A: timeout",
+		LastErrorRefID:      "A",
+		LastEvaluation:      now,
+		EvaluationTime:      2,
+		Attempts:            3,
+		ConsecutiveFailures: 1,
+		FailingSince:        now,
+	}, result[1])
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/pkg/labels"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// maxStateHistoryLimit is the maximum number of state transitions returned, and the number
+// returned when the request has no limit. The labels of the alert instances are matched
+// after reading the transitions, so the limit also bounds the rows read.
+const maxStateHistoryLimit = 1000
+
+type HistorySrv struct {
+	log   log.Logger
+	store store.StateHistoryStore
+}
+
+func (srv HistorySrv) RouteGetStateHistory(c *models.ReqContext) response.Response {
+	if srv.store == nil {
+		return ErrResp(http.StatusNotFound, errors.New("the state history of alert instances is disabled"), "")
+	}
+
+	q := ngmodels.ListAlertStateHistoryQuery{
+		OrgID:   c.SignedInUser.OrgId,
+		RuleUID: c.Query("ruleUID"),
+		Limit:   maxStateHistoryLimit,
+	}
+
+	for _, s := range c.QueryStrings("filter") {
+		matcher, err := labels.ParseMatcher(s)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "invalid filter")
+		}
+		q.Matchers = append(q.Matchers, matcher)
+	}
+
+	if from := c.QueryInt64("from"); from > 0 {
+		q.From = time.Unix(0, from*int64(time.Millisecond))
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		q.To = time.Unix(0, to*int64(time.Millisecond))
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && q.From.After(q.To) {
+		return ErrResp(http.StatusBadRequest, errors.New("from must not be after to"), "")
+	}
+
+	if c.Query("limit") != "" {
+		limit := c.QueryInt("limit")
+		if limit <= 0 {
+			return ErrResp(http.StatusBadRequest, errors.New("limit must be a positive number"), "")
+		}
+		if limit < maxStateHistoryLimit {
+			q.Limit = limit
+		}
+	}
+
+	if err := srv.store.ListAlertStateHistory(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get state history")
+	}
+
+	return response.JSON(http.StatusOK, apimodels.StateHistoryResponse{Frames: stateHistoryToFrames(q.Result)})
+}
+
+// stateHistoryToFrames returns a frame for each alert instance in the state history, with
+// a row for each state transition ordered by time. The values of the evaluations are in
+// a field for each RefID.
+func stateHistoryToFrames(entries []*ngmodels.AlertStateHistoryEntry) data.Frames {
+	type instanceKey struct {
+		ruleUID    string
+		labelsHash string
+	}
+	instances := make(map[instanceKey][]*ngmodels.AlertStateHistoryEntry)
+	keys := make([]instanceKey, 0)
+	for _, entry := range entries {
+		key := instanceKey{ruleUID: entry.RuleUID, labelsHash: entry.LabelsHash}
+		if _, ok := instances[key]; !ok {
+			keys = append(keys, key)
+		}
+		instances[key] = append(instances[key], entry)
+	}
+
+	frames := make(data.Frames, 0, len(keys))
+	for _, key := range keys {
+		instanceEntries := instances[key]
+		sort.SliceStable(instanceEntries, func(i, j int) bool {
+			return instanceEntries[i].EvaluatedAt.Before(instanceEntries[j].EvaluatedAt)
+		})
+		lbls := data.Labels(instanceEntries[0].Labels)
+
+		refIDs := make([]string, 0)
+		for _, entry := range instanceEntries {
+			for refID := range entry.EvaluationValues {
+				if !util.StringsContain(refIDs, refID) {
+					refIDs = append(refIDs, refID)
+				}
+			}
+		}
+		sort.Strings(refIDs)
+
+		timeField := data.NewField("time", nil, make([]time.Time, len(instanceEntries)))
+		stateField := data.NewField("state", lbls, make([]string, len(instanceEntries)))
+		previousStateField := data.NewField("previous_state", lbls, make([]string, len(instanceEntries)))
+		reasonField := data.NewField("reason", lbls, make([]string, len(instanceEntries)))
+		valueFields := make([]*data.Field, 0, len(refIDs))
+		for _, refID := range refIDs {
+			valueFields = append(valueFields, data.NewField(refID, lbls, make([]*float64, len(instanceEntries))))
+		}
+
+		for i, entry := range instanceEntries {
+			timeField.Set(i, entry.EvaluatedAt)
+			stateField.Set(i, string(entry.CurrentState))
+			previousStateField.Set(i, string(entry.PreviousState))
+			reasonField.Set(i, entry.Reason)
+			for j, refID := range refIDs {
+				if v, ok := entry.EvaluationValues[refID]; ok {
+					v := v
+					valueFields[j].Set(i, &v)
+				}
+			}
+		}
+
+		fields := append([]*data.Field{timeField, stateField, previousStateField, reasonField}, valueFields...)
+		frames = append(frames, data.NewFrame(lbls.String(), fields...))
+	}
+
+	sort.SliceStable(frames, func(i, j int) bool {
+		return frames[i].Name < frames[j].Name
+	})
+	return frames
+}
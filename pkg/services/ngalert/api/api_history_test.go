@@ -0,0 +1,132 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type fakeStateHistoryStore struct {
+	query *ngmodels.ListAlertStateHistoryQuery
+}
+
+func (f *fakeStateHistoryStore) SaveAlertStateHistory([]ngmodels.AlertStateHistoryEntry) error {
+	return nil
+}
+
+func (f *fakeStateHistoryStore) ListAlertStateHistory(q *ngmodels.ListAlertStateHistoryQuery) error {
+	f.query = q
+	return nil
+}
+
+func (f *fakeStateHistoryStore) DeleteAlertStateHistory(time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestRouteGetStateHistory(t *testing.T) {
+	newRequest := func(query string) *models.ReqContext {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/rules/history?"+query, nil)
+		return &models.ReqContext{
+			Context:      &web.Context{Req: req},
+			SignedInUser: &models.SignedInUser{OrgId: 1},
+		}
+	}
+
+	t.Run("invalid queries are rejected", func(t *testing.T) {
+		srv := HistorySrv{log: log.New("test"), store: &fakeStateHistoryStore{}}
+		for _, query := range []string{"limit=0", "from=2000&to=1000", "filter=%3D"} {
+			res := srv.RouteGetStateHistory(newRequest(query))
+			require.Equal(t, http.StatusBadRequest, res.Status(), query)
+		}
+	})
+
+	t.Run("the limit is capped", func(t *testing.T) {
+		for query, expected := range map[string]int{
+			"":           maxStateHistoryLimit,
+			"limit=10":   10,
+			"limit=5000": maxStateHistoryLimit,
+		} {
+			store := &fakeStateHistoryStore{}
+			srv := HistorySrv{log: log.New("test"), store: store}
+			res := srv.RouteGetStateHistory(newRequest(query))
+			require.Equal(t, http.StatusOK, res.Status(), query)
+			require.Equal(t, expected, store.query.Limit, query)
+		}
+	})
+}
+
+func TestStateHistoryToFrames(t *testing.T) {
+	now := time.Unix(1000, 0)
+	entries := []*ngmodels.AlertStateHistoryEntry{
+		{
+			RuleUID:          "rule",
+			Labels:           ngmodels.InstanceLabels{"host": "b"},
+			LabelsHash:       "b",
+			PreviousState:    ngmodels.InstanceStateNormal,
+			CurrentState:     ngmodels.InstanceStateError,
+			Reason:           "failed to execute query",
+			EvaluationValues: ngmodels.StateHistoryValues{},
+			EvaluatedAt:      now.Add(time.Minute),
+		},
+		{
+			RuleUID:          "rule",
+			Labels:           ngmodels.InstanceLabels{"host": "a"},
+			LabelsHash:       "a",
+			PreviousState:    ngmodels.InstanceStateFiring,
+			CurrentState:     ngmodels.InstanceStateNormal,
+			EvaluationValues: ngmodels.StateHistoryValues{"B": 1},
+			EvaluatedAt:      now.Add(time.Minute),
+		},
+		{
+			RuleUID:          "rule",
+			Labels:           ngmodels.InstanceLabels{"host": "a"},
+			LabelsHash:       "a",
+			PreviousState:    ngmodels.InstanceStateNormal,
+			CurrentState:     ngmodels.InstanceStateFiring,
+			EvaluationValues: ngmodels.StateHistoryValues{"B": 5, "C": 1},
+			EvaluatedAt:      now,
+		},
+	}
+
+	frames := stateHistoryToFrames(entries)
+	require.Len(t, frames, 2)
+
+	a := frames[0]
+	require.Equal(t, `host=a`, a.Name)
+	require.Equal(t, 2, a.Rows())
+	require.Equal(t, []string{"time", "state", "previous_state", "reason", "B", "C"}, fieldNames(a))
+	require.Equal(t, data.Labels{"host": "a"}, a.Fields[1].Labels)
+	// the transitions are ordered by time
+	require.Equal(t, now, a.Fields[0].At(0))
+	require.Equal(t, "Alerting", a.Fields[1].At(0))
+	require.Equal(t, "Normal", a.Fields[1].At(1))
+	require.Equal(t, ptr.Float64(5), a.Fields[4].At(0))
+	require.Equal(t, ptr.Float64(1), a.Fields[4].At(1))
+	require.Equal(t, ptr.Float64(1), a.Fields[5].At(0))
+	require.Nil(t, a.Fields[5].At(1))
+
+	b := frames[1]
+	require.Equal(t, `host=b`, b.Name)
+	require.Equal(t, 1, b.Rows())
+	require.Equal(t, []string{"time", "state", "previous_state", "reason"}, fieldNames(b))
+	require.Equal(t, "Error", b.Fields[1].At(0))
+	require.Equal(t, "failed to execute query", b.Fields[3].At(0))
+}
+
+func fieldNames(frame *data.Frame) []string {
+	names := make([]string, 0, len(frame.Fields))
+	for _, f := range frame.Fields {
+		names = append(names, f.Name)
+	}
+	return names
+}
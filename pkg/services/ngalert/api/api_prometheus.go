@@ -23,9 +23,10 @@ import (
 )
 
 type PrometheusSrv struct {
-	log     log.Logger
-	manager *state.Manager
-	store   store.RuleStore
+	log       log.Logger
+	manager   *state.Manager
+	store     store.RuleStore
+	scheduler Scheduler
 }
 
 func (srv PrometheusSrv) RouteGetAlertStatuses(c *models.ReqContext) response.Response {
@@ -44,11 +45,12 @@ func (srv PrometheusSrv) RouteGetAlertStatuses(c *models.ReqContext) response.Re
 			valString = alertState.Results[0].EvaluationString
 		}
 		alertResponse.Data.Alerts = append(alertResponse.Data.Alerts, &apimodels.Alert{
-			Labels:      map[string]string(alertState.Labels),
-			Annotations: map[string]string{}, //TODO: Once annotations are added to the evaluation result, set them here
-			State:       alertState.State.String(),
-			ActiveAt:    &startsAt,
-			Value:       valString,
+			Labels:       map[string]string(alertState.Labels),
+			Annotations:  map[string]string{}, //TODO: Once annotations are added to the evaluation result, set them here
+			State:        alertState.State.String(),
+			ActiveAt:     &startsAt,
+			Value:        valString,
+			SuppressedBy: alertState.SuppressedBy,
 		})
 	}
 	return response.JSON(http.StatusOK, alertResponse)
@@ -115,6 +117,9 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 		return response.JSON(http.StatusInternalServerError, ruleResponse)
 	}
 
+	// the health of the rules evaluated by this instance
+	ruleHealth := srv.scheduler.RuleHealth(c.SignedInUser.OrgId)
+
 	groupMap := make(map[string]*apimodels.RuleGroup)
 
 	for _, r := range ruleGroupQuery.Result {
@@ -155,6 +160,7 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 			Query:       queryStr,
 			Duration:    rule.For.Seconds(),
 			Annotations: rule.Annotations,
+			IsPaused:    rule.IsEvaluationPaused(),
 		}
 
 		newRule := apimodels.Rule{
@@ -172,11 +178,12 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 				valString = alertState.Results[0].EvaluationString
 			}
 			alert := &apimodels.Alert{
-				Labels:      map[string]string(alertState.Labels),
-				Annotations: alertState.Annotations,
-				State:       alertState.State.String(),
-				ActiveAt:    &activeAt,
-				Value:       valString, // TODO: set this once it is added to the evaluation results
+				Labels:       map[string]string(alertState.Labels),
+				Annotations:  alertState.Annotations,
+				State:        alertState.State.String(),
+				ActiveAt:     &activeAt,
+				Value:        valString, // TODO: set this once it is added to the evaluation results
+				SuppressedBy: alertState.SuppressedBy,
 			}
 
 			if alertState.LastEvaluationTime.After(newRule.LastEvaluation) {
@@ -207,9 +214,34 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 			alertingRule.Alerts = append(alertingRule.Alerts, alert)
 		}
 
+		if h, ok := ruleHealth[rule.UID]; ok {
+			applyRuleHealth(&newRule, h)
+			if h.LastEvaluation.After(newGroup.LastEvaluation) {
+				newGroup.LastEvaluation = h.LastEvaluation
+			}
+		}
+		if rule.IsEvaluationPaused() {
+			newRule.Health = "paused"
+		}
+		if rule.IsRecording() {
+			newRule.Type = apiv1.RuleTypeRecording
+		}
+
 		alertingRule.Rule = newRule
 		newGroup.Rules = append(newGroup.Rules, alertingRule)
 		newGroup.Interval = float64(rule.IntervalSeconds)
 	}
 	return response.JSON(http.StatusOK, ruleResponse)
 }
+
+// applyRuleHealth sets the health of the last evaluation of the rule kept by the
+// scheduler, which includes the evaluations that failed before creating alert states.
+func applyRuleHealth(rule *apimodels.Rule, h ngmodels.AlertRuleHealth) {
+	rule.Health = string(h.Health)
+	rule.LastError = h.LastError
+	rule.LastErrorRefID = h.LastErrorRefID
+	rule.LastEvaluation = h.LastEvaluation
+	rule.EvaluationTime = h.EvaluationDuration.Seconds()
+	rule.EvaluationAttempts = h.Attempts
+	rule.ConsecutiveFailures = h.ConsecutiveFailures
+}
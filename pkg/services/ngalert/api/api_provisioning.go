@@ -0,0 +1,38 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+)
+
+type ProvisioningSrv struct {
+	log     log.Logger
+	service *provisioning.Service
+}
+
+func (srv ProvisioningSrv) RouteGetProvisioningExport(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
+	}
+
+	// the secure settings of the contact points are only exported to administrators
+	decrypt := c.QueryBool("decrypt")
+	if decrypt && c.OrgRole != models.ROLE_ADMIN {
+		return ErrResp(http.StatusForbidden, errors.New("only administrators can export secure settings"), "")
+	}
+
+	f, err := srv.service.Export(c.Req.Context(), c.OrgId, decrypt)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to export the alerting resources")
+	}
+	b, err := provisioning.EncodeFile(f)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to encode the alerting resources")
+	}
+	return response.Respond(http.StatusOK, b).SetHeader("Content-Type", "application/yaml")
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/api/apierrors"
@@ -23,6 +24,7 @@ import (
 
 type RulerSrv struct {
 	store           store.RuleStore
+	provenanceStore store.ProvisioningStore
 	DatasourceCache datasources.CacheService
 	QuotaService    *quota.QuotaService
 	manager         *state.Manager
@@ -36,6 +38,17 @@ func (srv RulerSrv) RouteDeleteNamespaceRulesConfig(c *models.ReqContext) respon
 		return toNamespaceErrorResponse(err)
 	}
 
+	q := ngmodels.ListNamespaceAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+	}
+	if err := srv.store.GetNamespaceAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespace alert rules")
+	}
+	if errResp := srv.checkNotProvisioned(c.SignedInUser.OrgId, ruleUIDs(q.Result)); errResp != nil {
+		return errResp
+	}
+
 	uids, err := srv.store.DeleteNamespaceAlertRules(c.SignedInUser.OrgId, namespace.Uid)
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to delete namespace alert rules")
@@ -55,6 +68,19 @@ func (srv RulerSrv) RouteDeleteRuleGroupConfig(c *models.ReqContext) response.Re
 		return toNamespaceErrorResponse(err)
 	}
 	ruleGroup := web.Params(c.Req)[":Groupname"]
+
+	q := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+		RuleGroup:    ruleGroup,
+	}
+	if err := srv.store.GetRuleGroupAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get group alert rules")
+	}
+	if errResp := srv.checkNotProvisioned(c.SignedInUser.OrgId, ruleUIDs(q.Result)); errResp != nil {
+		return errResp
+	}
+
 	uids, err := srv.store.DeleteRuleGroupAlertRules(c.SignedInUser.OrgId, namespace.Uid, ruleGroup)
 
 	if err != nil {
@@ -85,6 +111,10 @@ func (srv RulerSrv) RouteGetNamespaceRulesConfig(c *models.ReqContext) response.
 	if err := srv.store.GetNamespaceAlertRules(&q); err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
 	}
+	provenances, err := srv.provenanceStore.GetProvenances(c.SignedInUser.OrgId, ngmodels.ProvisionedAlertRule)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the provenance of the alert rules")
+	}
 
 	result := apimodels.NamespaceConfigResponse{}
 	ruleGroupConfigs := make(map[string]apimodels.GettableRuleGroupConfig)
@@ -95,12 +125,13 @@ func (srv RulerSrv) RouteGetNamespaceRulesConfig(c *models.ReqContext) response.
 			ruleGroupConfigs[r.RuleGroup] = apimodels.GettableRuleGroupConfig{
 				Name:     r.RuleGroup,
 				Interval: ruleGroupInterval,
+				IsPaused: r.IsGroupPaused,
 				Rules: []apimodels.GettableExtendedRuleNode{
-					toGettableExtendedRuleNode(*r, namespace.Id),
+					toGettableExtendedRuleNode(*r, namespace.Id, provenances),
 				},
 			}
 		} else {
-			ruleGroupConfig.Rules = append(ruleGroupConfig.Rules, toGettableExtendedRuleNode(*r, namespace.Id))
+			ruleGroupConfig.Rules = append(ruleGroupConfig.Rules, toGettableExtendedRuleNode(*r, namespace.Id, provenances))
 			ruleGroupConfigs[r.RuleGroup] = ruleGroupConfig
 		}
 	}
@@ -128,12 +159,18 @@ func (srv RulerSrv) RouteGetRulegGroupConfig(c *models.ReqContext) response.Resp
 	if err := srv.store.GetRuleGroupAlertRules(&q); err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to get group alert rules")
 	}
+	provenances, err := srv.provenanceStore.GetProvenances(c.SignedInUser.OrgId, ngmodels.ProvisionedAlertRule)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the provenance of the alert rules")
+	}
 
 	var ruleGroupInterval model.Duration
+	var ruleGroupIsPaused bool
 	ruleNodes := make([]apimodels.GettableExtendedRuleNode, 0, len(q.Result))
 	for _, r := range q.Result {
 		ruleGroupInterval = model.Duration(time.Duration(r.IntervalSeconds) * time.Second)
-		ruleNodes = append(ruleNodes, toGettableExtendedRuleNode(*r, namespace.Id))
+		ruleGroupIsPaused = r.IsGroupPaused
+		ruleNodes = append(ruleNodes, toGettableExtendedRuleNode(*r, namespace.Id, provenances))
 	}
 
 	result := apimodels.RuleGroupConfigResponse{
@@ -141,6 +178,7 @@ func (srv RulerSrv) RouteGetRulegGroupConfig(c *models.ReqContext) response.Resp
 			Name:     ruleGroup,
 			Interval: ruleGroupInterval,
 			Rules:    ruleNodes,
+			IsPaused: ruleGroupIsPaused,
 		},
 	}
 	return response.JSON(http.StatusAccepted, result)
@@ -176,6 +214,10 @@ func (srv RulerSrv) RouteGetRulesConfig(c *models.ReqContext) response.Response
 	if err := srv.store.GetOrgAlertRules(&q); err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rules")
 	}
+	provenances, err := srv.provenanceStore.GetProvenances(c.OrgId, ngmodels.ProvisionedAlertRule)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the provenance of the alert rules")
+	}
 
 	configs := make(map[string]map[string]apimodels.GettableRuleGroupConfig)
 	for _, r := range q.Result {
@@ -192,8 +234,9 @@ func (srv RulerSrv) RouteGetRulesConfig(c *models.ReqContext) response.Response
 			configs[namespace][r.RuleGroup] = apimodels.GettableRuleGroupConfig{
 				Name:     r.RuleGroup,
 				Interval: ruleGroupInterval,
+				IsPaused: r.IsGroupPaused,
 				Rules: []apimodels.GettableExtendedRuleNode{
-					toGettableExtendedRuleNode(*r, folder.Id),
+					toGettableExtendedRuleNode(*r, folder.Id, provenances),
 				},
 			}
 		} else {
@@ -203,12 +246,13 @@ func (srv RulerSrv) RouteGetRulesConfig(c *models.ReqContext) response.Response
 				configs[namespace][r.RuleGroup] = apimodels.GettableRuleGroupConfig{
 					Name:     r.RuleGroup,
 					Interval: ruleGroupInterval,
+					IsPaused: r.IsGroupPaused,
 					Rules: []apimodels.GettableExtendedRuleNode{
-						toGettableExtendedRuleNode(*r, folder.Id),
+						toGettableExtendedRuleNode(*r, folder.Id, provenances),
 					},
 				}
 			} else {
-				ruleGroupConfig.Rules = append(ruleGroupConfig.Rules, toGettableExtendedRuleNode(*r, folder.Id))
+				ruleGroupConfig.Rules = append(ruleGroupConfig.Rules, toGettableExtendedRuleNode(*r, folder.Id, provenances))
 				configs[namespace][r.RuleGroup] = ruleGroupConfig
 			}
 		}
@@ -245,6 +289,29 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 		if err := validateCondition(cond, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
 			return ErrResp(http.StatusBadRequest, err, "failed to validate alert rule %q", r.GrafanaManagedAlert.Title)
 		}
+		if r.GrafanaManagedAlert.NoDataState != "" {
+			if _, err := ngmodels.NoDataStateFromString(string(r.GrafanaManagedAlert.NoDataState)); err != nil {
+				return ErrResp(http.StatusBadRequest, err, "failed to validate alert rule %q", r.GrafanaManagedAlert.Title)
+			}
+		}
+		if r.GrafanaManagedAlert.ExecErrState != "" {
+			if _, err := ngmodels.ErrStateFromString(string(r.GrafanaManagedAlert.ExecErrState)); err != nil {
+				return ErrResp(http.StatusBadRequest, err, "failed to validate alert rule %q", r.GrafanaManagedAlert.Title)
+			}
+		}
+		if record := r.GrafanaManagedAlert.Record; record != nil {
+			if !model.IsValidMetricName(model.LabelValue(record.Metric)) {
+				return ErrResp(http.StatusBadRequest, fmt.Errorf("invalid metric name %q", record.Metric), "failed to validate alert rule %q", r.GrafanaManagedAlert.Title)
+			}
+			if _, err := ngmodels.RecordTargetFromString(record.Target); err != nil {
+				return ErrResp(http.StatusBadRequest, err, "failed to validate alert rule %q", r.GrafanaManagedAlert.Title)
+			}
+		}
+		if suppressedBy := r.GrafanaManagedAlert.SuppressedBy; suppressedBy != nil {
+			if err := validateSuppressedBy(r.GrafanaManagedAlert.UID, r.GrafanaManagedAlert.Record != nil, suppressedBy); err != nil {
+				return ErrResp(http.StatusBadRequest, err, "failed to validate alert rule %q", r.GrafanaManagedAlert.Title)
+			}
+		}
 		if r.GrafanaManagedAlert.UID != "" {
 			_, ok := alertRuleUIDs[r.GrafanaManagedAlert.UID]
 			if ok {
@@ -254,6 +321,33 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 		}
 	}
 
+	// neither the alert rules of the group nor the alert rules moved to it can be provisioned
+	q := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+		RuleGroup:    ruleGroupConfig.Name,
+	}
+	if err := srv.store.GetRuleGroupAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get group alert rules")
+	}
+	uids := ruleUIDs(q.Result)
+	for uid := range alertRuleUIDs {
+		uids = append(uids, uid)
+	}
+	if errResp := srv.checkNotProvisioned(c.SignedInUser.OrgId, uids); errResp != nil {
+		return errResp
+	}
+
+	if hasSuppressingRules(ruleGroupConfig.Rules) {
+		orgRules := ngmodels.ListAlertRulesQuery{OrgID: c.SignedInUser.OrgId}
+		if err := srv.store.GetOrgAlertRules(&orgRules); err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to get alert rules")
+		}
+		if err := validateSuppressingRules(orgRules.Result, q.Result, ruleGroupConfig.Rules); err != nil {
+			return ErrResp(http.StatusBadRequest, err, "failed to validate rule group")
+		}
+	}
+
 	numOfNewRules := len(ruleGroupConfig.Rules) - len(alertRuleUIDs)
 	if numOfNewRules > 0 {
 		// quotas are checked in advanced
@@ -289,7 +383,33 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule group updated successfully"})
 }
 
-func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64) apimodels.GettableExtendedRuleNode {
+// checkNotProvisioned returns a conflict response if any of the alert rules is provisioned
+// from files.
+func (srv RulerSrv) checkNotProvisioned(orgID int64, uids []string) response.Response {
+	if len(uids) == 0 {
+		return nil
+	}
+	provenances, err := srv.provenanceStore.GetProvenances(orgID, ngmodels.ProvisionedAlertRule)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the provenance of the alert rules")
+	}
+	for _, uid := range uids {
+		if provenances[uid] != ngmodels.ProvenanceNone {
+			return ErrResp(http.StatusConflict, ngmodels.ErrProvisionedResource, "alert rule %q", uid)
+		}
+	}
+	return nil
+}
+
+func ruleUIDs(rules []*ngmodels.AlertRule) []string {
+	uids := make([]string, 0, len(rules))
+	for _, r := range rules {
+		uids = append(uids, r.UID)
+	}
+	return uids
+}
+
+func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64, provenances map[string]ngmodels.Provenance) apimodels.GettableExtendedRuleNode {
 	gettableExtendedRuleNode := apimodels.GettableExtendedRuleNode{
 		GrafanaManagedAlert: &apimodels.GettableGrafanaRule{
 			ID:              r.ID,
@@ -306,8 +426,22 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64) apimode
 			RuleGroup:       r.RuleGroup,
 			NoDataState:     apimodels.NoDataState(r.NoDataState),
 			ExecErrState:    apimodels.ExecutionErrorState(r.ExecErrState),
+			IsPaused:        r.IsPaused,
+			Provenance:      provenances[r.UID],
 		},
 	}
+	if r.IsRecording() {
+		gettableExtendedRuleNode.GrafanaManagedAlert.Record = &apimodels.Record{
+			Metric: r.Record,
+			Target: string(r.RecordTarget),
+		}
+	}
+	if r.IsSuppressible() {
+		gettableExtendedRuleNode.GrafanaManagedAlert.SuppressedBy = &apimodels.SuppressedBy{
+			RuleUIDs: r.SuppressedByRuleUIDs,
+			Labels:   r.SuppressedByLabels,
+		}
+	}
 	gettableExtendedRuleNode.ApiRuleNode = &apimodels.ApiRuleNode{
 		For:         model.Duration(r.For),
 		Annotations: r.Annotations,
@@ -316,6 +450,103 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64) apimode
 	return gettableExtendedRuleNode
 }
 
+// validateSuppressedBy checks that the alert rule with the UID is suppressed by other
+// alert rules, and by valid labels.
+func validateSuppressedBy(uid string, isRecording bool, suppressedBy *apimodels.SuppressedBy) error {
+	if isRecording {
+		return errors.New("recording rules cannot be suppressed")
+	}
+	for _, ruleUID := range suppressedBy.RuleUIDs {
+		if ruleUID == "" {
+			return errors.New("suppressing rule UID cannot be empty")
+		}
+		if ruleUID == uid {
+			return errors.New("rule cannot suppress itself")
+		}
+	}
+	for name := range suppressedBy.Labels {
+		if !model.LabelName(name).IsValid() {
+			return fmt.Errorf("invalid suppressing label name %q", name)
+		}
+	}
+	return nil
+}
+
+func hasSuppressingRules(rules []apimodels.PostableExtendedRuleNode) bool {
+	for _, r := range rules {
+		if r.GrafanaManagedAlert.SuppressedBy != nil && len(r.GrafanaManagedAlert.SuppressedBy.RuleUIDs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// validateSuppressingRules checks that the alert rules suppressing the alert rules of a
+// group exist, and that alert rules do not suppress each other in a cycle. orgRules are
+// the alert rules of the organization, and groupRules the alert rules of the group that
+// are replaced by rules.
+func validateSuppressingRules(orgRules, groupRules []*ngmodels.AlertRule, rules []apimodels.PostableExtendedRuleNode) error {
+	suppressedBy := make(map[string][]string, len(orgRules)+len(rules))
+	for _, r := range orgRules {
+		suppressedBy[r.UID] = r.SuppressedByRuleUIDs
+	}
+	for _, r := range groupRules {
+		delete(suppressedBy, r.UID)
+	}
+	for _, r := range rules {
+		// new alert rules without a UID cannot be referenced by other alert rules yet
+		if r.GrafanaManagedAlert.UID == "" {
+			continue
+		}
+		var uids []string
+		if r.GrafanaManagedAlert.SuppressedBy != nil {
+			uids = r.GrafanaManagedAlert.SuppressedBy.RuleUIDs
+		}
+		suppressedBy[r.GrafanaManagedAlert.UID] = uids
+	}
+
+	for _, r := range rules {
+		if r.GrafanaManagedAlert.SuppressedBy == nil {
+			continue
+		}
+		for _, uid := range r.GrafanaManagedAlert.SuppressedBy.RuleUIDs {
+			if _, ok := suppressedBy[uid]; !ok {
+				return fmt.Errorf("suppressing rule %q of alert rule %q does not exist", uid, r.GrafanaManagedAlert.Title)
+			}
+		}
+		if r.GrafanaManagedAlert.UID == "" {
+			continue
+		}
+		if cycle := suppressionCycle(suppressedBy, r.GrafanaManagedAlert.UID); cycle != nil {
+			return fmt.Errorf("alert rules cannot suppress each other in a cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	return nil
+}
+
+// suppressionCycle returns the UIDs of the alert rules suppressing each other from the
+// alert rule with the UID back to it, or nil if the alert rule is not in a cycle.
+func suppressionCycle(suppressedBy map[string][]string, uid string) []string {
+	visited := make(map[string]struct{})
+	var visit func(path []string) []string
+	visit = func(path []string) []string {
+		for _, next := range suppressedBy[path[len(path)-1]] {
+			if next == uid {
+				return append(path, next)
+			}
+			if _, ok := visited[next]; ok {
+				continue
+			}
+			visited[next] = struct{}{}
+			if cycle := visit(append(path, next)); cycle != nil {
+				return cycle
+			}
+		}
+		return nil
+	}
+	return visit([]string{uid})
+}
+
 func toNamespaceErrorResponse(err error) response.Response {
 	if errors.Is(err, ngmodels.ErrCannotEditNamespace) {
 		return ErrResp(http.StatusForbidden, err, err.Error())
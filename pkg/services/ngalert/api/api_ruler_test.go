@@ -0,0 +1,72 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestValidateSuppressingRules(t *testing.T) {
+	rule := func(uid string, suppressedBy ...string) apimodels.PostableExtendedRuleNode {
+		r := apimodels.PostableExtendedRuleNode{GrafanaManagedAlert: &apimodels.PostableGrafanaRule{UID: uid, Title: uid}}
+		if len(suppressedBy) > 0 {
+			r.GrafanaManagedAlert.SuppressedBy = &apimodels.SuppressedBy{RuleUIDs: suppressedBy}
+		}
+		return r
+	}
+	orgRules := []*ngmodels.AlertRule{
+		{UID: "network"},
+		{UID: "database", SuppressedByRuleUIDs: []string{"network"}},
+		{UID: "api", SuppressedByRuleUIDs: []string{"database"}},
+	}
+	groupRules := []*ngmodels.AlertRule{orgRules[2]}
+
+	testCases := []struct {
+		desc     string
+		rules    []apimodels.PostableExtendedRuleNode
+		expError string
+	}{
+		{
+			desc:  "rules suppressed by existing rules",
+			rules: []apimodels.PostableExtendedRuleNode{rule("api", "database"), rule("", "network")},
+		},
+		{
+			desc:  "rules suppressed by rules of the group",
+			rules: []apimodels.PostableExtendedRuleNode{rule("api", "web"), rule("web", "network")},
+		},
+		{
+			desc:     "rules suppressed by rules that do not exist",
+			rules:    []apimodels.PostableExtendedRuleNode{rule("", "unknown")},
+			expError: `suppressing rule "unknown" of alert rule "" does not exist`,
+		},
+		{
+			desc:     "rules suppressed by rules removed from the group",
+			rules:    []apimodels.PostableExtendedRuleNode{rule("web", "api")},
+			expError: `suppressing rule "api" of alert rule "web" does not exist`,
+		},
+		{
+			desc:     "rules suppressing each other in the group",
+			rules:    []apimodels.PostableExtendedRuleNode{rule("api", "web"), rule("web", "api")},
+			expError: "alert rules cannot suppress each other in a cycle: api -> web -> api",
+		},
+		{
+			desc:     "rules suppressing each other through other groups",
+			rules:    []apimodels.PostableExtendedRuleNode{rule("api", "database"), rule("network", "api")},
+			expError: "alert rules cannot suppress each other in a cycle: api -> database -> network -> api",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := validateSuppressingRules(orgRules, groupRules, tc.rules)
+			if tc.expError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.expError)
+		})
+	}
+}
@@ -6,13 +6,16 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb"
 	"github.com/grafana/grafana/pkg/web"
@@ -93,3 +96,57 @@ func (srv TestingApiSrv) RouteEvalQueries(c *models.ReqContext, cmd apimodels.Ev
 
 	return response.JSONStreaming(http.StatusOK, evalResults)
 }
+
+func (srv TestingApiSrv) RouteBacktestRule(c *models.ReqContext, cmd apimodels.BacktestConfig) response.Response {
+	noDataState, err := ngmodels.NoDataStateFromString(string(cmd.NoDataState))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	execErrState, err := ngmodels.ErrStateFromString(string(cmd.ExecErrState))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	interval := time.Duration(cmd.Interval)
+	if interval == 0 {
+		interval = srv.Cfg.UnifiedAlerting.MinInterval
+	}
+	if interval%time.Second != 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("the interval must be a whole number of seconds"), "")
+	}
+
+	rule := &ngmodels.AlertRule{
+		OrgID:           c.SignedInUser.OrgId,
+		Title:           cmd.Title,
+		Condition:       cmd.Condition,
+		Data:            cmd.Data,
+		IntervalSeconds: int64(interval.Seconds()),
+		For:             time.Duration(cmd.For),
+		Labels:          cmd.Labels,
+		NoDataState:     noDataState,
+		ExecErrState:    execErrState,
+	}
+	if err := backtesting.Validate(rule, cmd.From, cmd.To); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	condition := ngmodels.Condition{
+		Condition: rule.Condition,
+		OrgID:     rule.OrgID,
+		Data:      rule.Data,
+	}
+	if err := validateCondition(condition, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid condition")
+	}
+
+	evaluator := eval.Evaluator{Cfg: srv.Cfg, Log: srv.log}
+	result := backtesting.Run(srv.log, rule, cmd.From, cmd.To, func(now time.Time) eval.Results {
+		results, err := evaluator.ConditionEval(&condition, now, srv.DataService)
+		if err != nil {
+			return eval.Results{{State: eval.Error, Error: err, EvaluatedAt: now}}
+		}
+		return results
+	})
+
+	return response.JSON(http.StatusOK, result)
+}
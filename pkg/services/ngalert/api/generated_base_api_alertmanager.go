@@ -32,6 +32,7 @@ type AlertmanagerApiService interface {
 	RoutePostAMAlerts(*models.ReqContext, apimodels.PostableAlerts) response.Response
 	RoutePostAlertingConfig(*models.ReqContext, apimodels.PostableUserConfig) response.Response
 	RoutePostTestReceivers(*models.ReqContext, apimodels.TestReceiversConfigParams) response.Response
+	RoutePostTestTemplates(*models.ReqContext, apimodels.TestTemplatesConfigBodyParams) response.Response
 }
 
 func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *metrics.API) {
@@ -148,5 +149,15 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/{Recipient}/config/api/v1/templates/test"),
+			binding.Bind(apimodels.TestTemplatesConfigBodyParams{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/{Recipient}/config/api/v1/templates/test",
+				srv.RoutePostTestTemplates,
+				m,
+			),
+		)
 	}, middleware.ReqSignedIn)
 }
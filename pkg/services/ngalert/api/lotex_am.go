@@ -244,3 +244,7 @@ func (am *LotexAM) RoutePostAMAlerts(ctx *models.ReqContext, alerts apimodels.Po
 func (am *LotexAM) RoutePostTestReceivers(ctx *models.ReqContext, config apimodels.TestReceiversConfigParams) response.Response {
 	return NotImplementedResp
 }
+
+func (am *LotexAM) RoutePostTestTemplates(ctx *models.ReqContext, config apimodels.TestTemplatesConfigBodyParams) response.Response {
+	return NotImplementedResp
+}
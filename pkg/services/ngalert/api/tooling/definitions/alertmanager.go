@@ -17,6 +17,7 @@ import (
 	amv2 "github.com/prometheus/alertmanager/api/v2/models"
 	"github.com/prometheus/alertmanager/config"
 	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
 	"github.com/prometheus/common/model"
 	"gopkg.in/yaml.v3"
 )
@@ -91,6 +92,18 @@ import (
 //       408: Failure
 //       409: AlertManagerNotReady
 
+// swagger:route POST /api/alertmanager/{Recipient}/config/api/v1/templates/test alertmanager RoutePostTestTemplates
+//
+// Test a notification template without saving it.
+//
+//     Responses:
+//
+//       200: TestTemplatesResults
+//       400: ValidationError
+//       403: PermissionDenied
+//       404: AlertManagerNotFound
+//       409: AlertManagerNotReady
+
 // swagger:route GET /api/alertmanager/{Recipient}/api/v2/silences alertmanager RouteGetSilences
 //
 // get silences
@@ -173,6 +186,67 @@ type TestReceiverConfigResult struct {
 	Error  string `json:"error,omitempty"`
 }
 
+// swagger:parameters RoutePostTestTemplates
+type TestTemplatesConfigParams struct {
+	// in:body
+	Body TestTemplatesConfigBodyParams
+}
+
+// swagger:model
+type TestTemplatesConfigBodyParams struct {
+	// Name is the name of the template file. The template replaces the saved template
+	// with the same name, if any.
+	Name string `yaml:"name" json:"name"`
+	// Template is the definition of the template file, with one or more templates
+	// declared with define.
+	Template string `yaml:"template" json:"template"`
+	// Alerts are the alerts of the notification the templates are executed with.
+	Alerts []*TestTemplatesAlert `yaml:"alerts,omitempty" json:"alerts,omitempty"`
+	// CurrentAlerts executes the templates with the active alerts of the Alertmanager
+	// when no alerts are given. A sample alert is used if neither are.
+	CurrentAlerts bool `yaml:"current_alerts,omitempty" json:"current_alerts,omitempty"`
+}
+
+type TestTemplatesAlert struct {
+	Annotations  model.LabelSet `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+	Labels       model.LabelSet `yaml:"labels,omitempty" json:"labels,omitempty"`
+	StartsAt     time.Time      `yaml:"starts_at,omitempty" json:"starts_at,omitempty"`
+	EndsAt       time.Time      `yaml:"ends_at,omitempty" json:"ends_at,omitempty"`
+	GeneratorURL string         `yaml:"generator_url,omitempty" json:"generator_url,omitempty"`
+}
+
+// swagger:model
+type TestTemplatesResults struct {
+	// Results is the output of each template declared in the template file.
+	Results []TestTemplatesResult `json:"results"`
+	// Errors are the errors of parsing the template file or executing its templates.
+	Errors []TestTemplatesErrorResult `json:"errors"`
+}
+
+// swagger:model
+type TestTemplatesResult struct {
+	Name string `json:"name"`
+	Text string `json:"text"`
+}
+
+type TemplateErrorKind string
+
+const (
+	InvalidTemplate         TemplateErrorKind = "invalid_template"
+	TemplateExecutionFailed TemplateErrorKind = "execution_error"
+)
+
+// swagger:model
+type TestTemplatesErrorResult struct {
+	// Name is the template that failed to execute, empty if the template file is invalid.
+	Name string `json:"name,omitempty"`
+	// enum: invalid_template,execution_error
+	Kind    TemplateErrorKind `json:"kind"`
+	Message string            `json:"message"`
+	// Line is the line of the error in the template file, if known.
+	Line int `json:"line,omitempty"`
+}
+
 // swagger:parameters RouteCreateSilence
 type CreateSilenceParams struct {
 	// in:body
@@ -341,7 +415,7 @@ type BodyAlertingConfig struct {
 }
 
 // alertmanager routes
-// swagger:parameters RoutePostAlertingConfig RouteGetAlertingConfig RouteDeleteAlertingConfig RouteGetAMStatus RouteGetAMAlerts RoutePostAMAlerts RouteGetAMAlertGroups RouteGetSilences RouteCreateSilence RouteGetSilence RouteDeleteSilence RoutePostAlertingConfig RoutePostTestReceivers
+// swagger:parameters RoutePostAlertingConfig RouteGetAlertingConfig RouteDeleteAlertingConfig RouteGetAMStatus RouteGetAMAlerts RoutePostAMAlerts RouteGetAMAlertGroups RouteGetSilences RouteCreateSilence RouteGetSilence RouteDeleteSilence RoutePostAlertingConfig RoutePostTestReceivers RoutePostTestTemplates
 // ruler routes
 // swagger:parameters RouteGetRulesConfig RoutePostNameRulesConfig RouteGetNamespaceRulesConfig RouteDeleteNamespaceRulesConfig RouteGetRulegGroupConfig RouteDeleteRuleGroupConfig
 // prom routes
@@ -587,10 +661,18 @@ func (c *GettableApiAlertingConfig) validate() error {
 
 // Config is the top-level configuration for Alertmanager's config files.
 type Config struct {
-	Global       *config.GlobalConfig  `yaml:"global,omitempty" json:"global,omitempty"`
-	Route        *Route                `yaml:"route,omitempty" json:"route,omitempty"`
-	InhibitRules []*config.InhibitRule `yaml:"inhibit_rules,omitempty" json:"inhibit_rules,omitempty"`
-	Templates    []string              `yaml:"templates" json:"templates"`
+	Global            *config.GlobalConfig  `yaml:"global,omitempty" json:"global,omitempty"`
+	Route             *Route                `yaml:"route,omitempty" json:"route,omitempty"`
+	InhibitRules      []*config.InhibitRule `yaml:"inhibit_rules,omitempty" json:"inhibit_rules,omitempty"`
+	Templates         []string              `yaml:"templates" json:"templates"`
+	MuteTimeIntervals []MuteTimeInterval    `yaml:"mute_time_intervals,omitempty" json:"mute_time_intervals,omitempty"`
+}
+
+// MuteTimeInterval represents a named set of time intervals for which a route should be muted.
+// This is a copy of alertmanager's upstream with JSON tags.
+type MuteTimeInterval struct {
+	Name          string                      `yaml:"name" json:"name"`
+	TimeIntervals []timeinterval.TimeInterval `yaml:"time_intervals" json:"time_intervals"`
 }
 
 // A Route is a node that contains definitions of how to handle alerts. This is modified
@@ -745,6 +827,9 @@ func (c *Config) UnmarshalJSON(b []byte) error {
 	if len(c.Route.Match) > 0 || len(c.Route.MatchRE) > 0 {
 		return fmt.Errorf("root route must not have any matchers")
 	}
+	if len(c.Route.MuteTimeIntervals) > 0 {
+		return fmt.Errorf("root route must not have any mute time intervals")
+	}
 
 	for _, r := range c.InhibitRules {
 		if err := r.UnmarshalYAML(noopUnmarshal); err != nil {
@@ -752,6 +837,33 @@ func (c *Config) UnmarshalJSON(b []byte) error {
 		}
 	}
 
+	tiNames := make(map[string]struct{}, len(c.MuteTimeIntervals))
+	for _, mt := range c.MuteTimeIntervals {
+		if mt.Name == "" {
+			return fmt.Errorf("missing name in mute time interval")
+		}
+		if _, ok := tiNames[mt.Name]; ok {
+			return fmt.Errorf("mute time interval %q is not unique", mt.Name)
+		}
+		tiNames[mt.Name] = struct{}{}
+	}
+
+	return checkTimeInterval(c.Route, tiNames)
+}
+
+// checkTimeInterval returns an error if a node in the routing tree references a mute
+// time interval that is not defined.
+func checkTimeInterval(r *Route, timeIntervals map[string]struct{}) error {
+	for _, sr := range r.Routes {
+		if err := checkTimeInterval(sr, timeIntervals); err != nil {
+			return err
+		}
+	}
+	for _, mt := range r.MuteTimeIntervals {
+		if _, ok := timeIntervals[mt]; !ok {
+			return fmt.Errorf("undefined time interval %q used in route", mt)
+		}
+	}
 	return nil
 }
 
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/timeinterval"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -194,6 +195,93 @@ func Test_ApiAlertingConfig_Marshaling(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "success mute time interval",
+			input: PostableApiAlertingConfig{
+				Config: Config{
+					Route: &Route{
+						Receiver: "graf",
+						Routes: []*Route{
+							{
+								Receiver:          "graf",
+								MuteTimeIntervals: []string{"weekends"},
+							},
+						},
+					},
+					MuteTimeIntervals: []MuteTimeInterval{
+						{
+							Name: "weekends",
+							TimeIntervals: []timeinterval.TimeInterval{
+								{
+									Times:    []timeinterval.TimeRange{{StartMinute: 0, EndMinute: 1440}},
+									Weekdays: []timeinterval.WeekdayRange{{InclusiveRange: timeinterval.InclusiveRange{Begin: 0, End: 0}}, {InclusiveRange: timeinterval.InclusiveRange{Begin: 6, End: 6}}},
+								},
+							},
+						},
+					},
+				},
+				Receivers: []*PostableApiReceiver{
+					{
+						Receiver: config.Receiver{
+							Name: "graf",
+						},
+						PostableGrafanaReceivers: PostableGrafanaReceivers{
+							GrafanaManagedReceivers: []*PostableGrafanaReceiver{{}},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "failure undefined mute time interval",
+			input: PostableApiAlertingConfig{
+				Config: Config{
+					Route: &Route{
+						Receiver: "graf",
+						Routes: []*Route{
+							{
+								Receiver:          "graf",
+								MuteTimeIntervals: []string{"weekends"},
+							},
+						},
+					},
+				},
+				Receivers: []*PostableApiReceiver{
+					{
+						Receiver: config.Receiver{
+							Name: "graf",
+						},
+						PostableGrafanaReceivers: PostableGrafanaReceivers{
+							GrafanaManagedReceivers: []*PostableGrafanaReceiver{{}},
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		{
+			desc: "failure mute time interval in root route",
+			input: PostableApiAlertingConfig{
+				Config: Config{
+					Route: &Route{
+						Receiver:          "graf",
+						MuteTimeIntervals: []string{"weekends"},
+					},
+					MuteTimeIntervals: []MuteTimeInterval{{Name: "weekends"}},
+				},
+				Receivers: []*PostableApiReceiver{
+					{
+						Receiver: config.Receiver{
+							Name: "graf",
+						},
+						PostableGrafanaReceivers: PostableGrafanaReceivers{
+							GrafanaManagedReceivers: []*PostableGrafanaReceiver{{}},
+						},
+					},
+				},
+			},
+			err: true,
+		},
 		{
 			desc: "failure undefined am receiver",
 			input: PostableApiAlertingConfig{
@@ -107,6 +107,8 @@ type PostableRuleGroupConfig struct {
 	Name     string                     `yaml:"name" json:"name"`
 	Interval model.Duration             `yaml:"interval,omitempty" json:"interval,omitempty"`
 	Rules    []PostableExtendedRuleNode `yaml:"rules" json:"rules"`
+	// IsPaused pauses all the rules of the group. It is only supported for Grafana managed rules.
+	IsPaused bool `yaml:"is_paused,omitempty" json:"is_paused,omitempty"`
 }
 
 func (c *PostableRuleGroupConfig) UnmarshalJSON(b []byte) error {
@@ -145,6 +147,10 @@ func (c *PostableRuleGroupConfig) validate() error {
 	if hasGrafRules && hasLotexRules {
 		return fmt.Errorf("cannot mix Grafana & Prometheus style rules")
 	}
+
+	if hasLotexRules && c.IsPaused {
+		return fmt.Errorf("cannot pause Prometheus style rules")
+	}
 	return nil
 }
 
@@ -153,6 +159,8 @@ type GettableRuleGroupConfig struct {
 	Name     string                     `yaml:"name" json:"name"`
 	Interval model.Duration             `yaml:"interval,omitempty" json:"interval,omitempty"`
 	Rules    []GettableExtendedRuleNode `yaml:"rules" json:"rules"`
+	// IsPaused is true if all the rules of the group are paused.
+	IsPaused bool `yaml:"is_paused,omitempty" json:"is_paused,omitempty"`
 }
 
 func (c *GettableRuleGroupConfig) UnmarshalJSON(b []byte) error {
@@ -287,16 +295,20 @@ func (n *GettableExtendedRuleNode) validate() error {
 type NoDataState string
 
 const (
-	Alerting NoDataState = "Alerting"
-	NoData   NoDataState = "NoData"
-	OK       NoDataState = "OK"
+	Alerting      NoDataState = "Alerting"
+	NoData        NoDataState = "NoData"
+	OK            NoDataState = "OK"
+	KeepLastState NoDataState = "KeepLastState"
 )
 
 // swagger:enum ExecutionErrorState
 type ExecutionErrorState string
 
 const (
-	AlertingErrState ExecutionErrorState = "Alerting"
+	AlertingErrState      ExecutionErrorState = "Alerting"
+	ErrorErrState         ExecutionErrorState = "Error"
+	OkErrState            ExecutionErrorState = "OK"
+	KeepLastStateErrState ExecutionErrorState = "KeepLastState"
 )
 
 // swagger:model
@@ -307,6 +319,9 @@ type PostableGrafanaRule struct {
 	UID          string              `json:"uid" yaml:"uid"`
 	NoDataState  NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	IsPaused     bool                `json:"is_paused" yaml:"is_paused"`
+	Record       *Record             `json:"record,omitempty" yaml:"record,omitempty"`
+	SuppressedBy *SuppressedBy       `json:"suppressed_by,omitempty" yaml:"suppressed_by,omitempty"`
 }
 
 // swagger:model
@@ -325,4 +340,31 @@ type GettableGrafanaRule struct {
 	RuleGroup       string              `json:"rule_group" yaml:"rule_group"`
 	NoDataState     NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState    ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	IsPaused        bool                `json:"is_paused" yaml:"is_paused"`
+	Record          *Record             `json:"record,omitempty" yaml:"record,omitempty"`
+	SuppressedBy    *SuppressedBy       `json:"suppressed_by,omitempty" yaml:"suppressed_by,omitempty"`
+	// Provenance is "file" if the alert rule is provisioned from files, and it cannot
+	// be changed through the API.
+	Provenance models.Provenance `json:"provenance,omitempty" yaml:"provenance,omitempty"`
+}
+
+// Record makes a Grafana managed rule a recording rule, which writes the result of
+// its condition as a time series instead of creating alerts.
+// swagger:model
+type Record struct {
+	// Metric is the name of the time series.
+	Metric string `json:"metric" yaml:"metric"`
+	// Target is where the time series is written, either remote_write or live.
+	Target string `json:"target" yaml:"target"`
+}
+
+// SuppressedBy suppresses the alerts of a Grafana managed rule while other rules are
+// firing. Suppressed alerts are not sent to the Alertmanager.
+// swagger:model
+type SuppressedBy struct {
+	// RuleUIDs are the UIDs of the rules that suppress the alerts while any of their
+	// alerts is firing.
+	RuleUIDs []string `json:"rule_uids,omitempty" yaml:"rule_uids,omitempty"`
+	// Labels suppress the alerts while a firing alert of another rule has all of these labels.
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
 }
@@ -133,6 +133,36 @@ func Test_Rule_Group_Marshaling(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "success paused grafana",
+			input: PostableRuleGroupConfig{
+				Name:     "foo",
+				Interval: 0,
+				Rules: []PostableExtendedRuleNode{
+					{
+						GrafanaManagedAlert: &PostableGrafanaRule{IsPaused: true},
+					},
+					{
+						GrafanaManagedAlert: &PostableGrafanaRule{},
+					},
+				},
+				IsPaused: true,
+			},
+		},
+		{
+			desc: "failure paused lotex",
+			input: PostableRuleGroupConfig{
+				Name:     "foo",
+				Interval: 0,
+				Rules: []PostableExtendedRuleNode{
+					{
+						ApiRuleNode: &ApiRuleNode{},
+					},
+				},
+				IsPaused: true,
+			},
+			err: true,
+		},
 		{
 			desc: "failure mixed",
 			input: PostableRuleGroupConfig{
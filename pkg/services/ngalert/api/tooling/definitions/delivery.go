@@ -0,0 +1,81 @@
+package definitions
+
+import (
+	"time"
+)
+
+// swagger:route GET /api/v1/receivers/{Receiver}/deliveries delivery RouteGetNotificationDeliveries
+//
+// gets the attempts of the integrations of a Grafana contact point to send notifications
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: NotificationDeliveriesResponse
+//       400: ValidationError
+//       403: PermissionDenied
+
+// swagger:parameters RouteGetNotificationDeliveries
+type NotificationDeliveriesParams struct {
+	// The name of the contact point
+	// in:path
+	Receiver string
+
+	// Only return the attempts of the integration of the contact point with this UID
+	// in: query
+	// required: false
+	IntegrationUID string `json:"integrationUID"`
+
+	// Only return the attempts with this status
+	// in: query
+	// required: false
+	// enum: delivered,failed,dropped
+	Status string `json:"status"`
+
+	// The start of the time range in epoch milliseconds
+	// in: query
+	// required: false
+	From int64 `json:"from"`
+
+	// The end of the time range in epoch milliseconds
+	// in: query
+	// required: false
+	To int64 `json:"to"`
+
+	// The maximum number of attempts to return, starting from the most recent
+	// in: query
+	// required: false
+	// default: 1000
+	Limit int64 `json:"limit"`
+}
+
+// swagger:model
+type NotificationDeliveriesResponse struct {
+	// Deliveries are ordered from the most recent.
+	Deliveries []NotificationDelivery `json:"deliveries"`
+}
+
+// NotificationDelivery is an attempt of an integration of a contact point to send the
+// notification of an alert group.
+// swagger:model
+type NotificationDelivery struct {
+	IntegrationType string `json:"integration_type"`
+	IntegrationUID  string `json:"integration_uid"`
+	// GroupKey identifies the alert group of the notification.
+	GroupKey string `json:"group_key"`
+	// Alerts is the number of alerts in the notification.
+	Alerts int `json:"alerts"`
+	// Attempt is the number of the attempt to send the notification, starting from 1.
+	Attempt int `json:"attempt"`
+	// Status is delivered if the notification was sent, failed if the attempt failed
+	// and is retried, or dropped if the attempt failed and is not retried.
+	Status string `json:"status"`
+	// StatusCode is the HTTP status code of the last request of the integration, or 0
+	// if it did not get a response.
+	StatusCode int    `json:"status_code"`
+	Error      string `json:"error,omitempty"`
+	// Duration is the time the attempt took in seconds.
+	Duration float64   `json:"duration"`
+	SentAt   time.Time `json:"sent_at"`
+}
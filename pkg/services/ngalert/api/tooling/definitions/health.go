@@ -0,0 +1,44 @@
+package definitions
+
+import (
+	"time"
+)
+
+// swagger:route GET /api/v1/rules/unhealthy health RouteGetUnhealthyRules
+//
+// gets the Grafana managed alert rules whose last evaluation failed
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: UnhealthyRulesResponse
+
+// swagger:model
+type UnhealthyRulesResponse struct {
+	// Rules are ordered by the time since they have been failing, the longest first.
+	Rules []UnhealthyRule `json:"rules"`
+}
+
+// UnhealthyRule is an alert rule whose last evaluation failed. Only the alert rules
+// evaluated by the Grafana instance that serves the request are included, so with
+// sharded rule evaluation the response of each instance of the cluster is partial.
+// swagger:model
+type UnhealthyRule struct {
+	UID          string `json:"uid"`
+	Title        string `json:"title"`
+	NamespaceUID string `json:"namespace_uid"`
+	Namespace    string `json:"namespace"`
+	RuleGroup    string `json:"rule_group"`
+	// LastError is the error of the last evaluation, and LastErrorRefID the RefID of the
+	// query or expression that failed, if the error is specific to one.
+	LastError      string    `json:"last_error"`
+	LastErrorRefID string    `json:"last_error_ref_id,omitempty"`
+	LastEvaluation time.Time `json:"last_evaluation"`
+	// EvaluationTime is the duration of the last evaluation in seconds.
+	EvaluationTime float64 `json:"evaluation_time"`
+	// Attempts is the number of attempts of the last evaluation.
+	Attempts            int64     `json:"attempts"`
+	ConsecutiveFailures int64     `json:"consecutive_failures"`
+	FailingSince        time.Time `json:"failing_since"`
+}
@@ -0,0 +1,52 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// swagger:route GET /api/v1/rules/history history RouteGetStateHistory
+//
+// gets the state transitions of the alert instances of Grafana managed alert rules
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: StateHistoryResponse
+//       400: ValidationError
+
+// swagger:parameters RouteGetStateHistory
+type StateHistoryParams struct {
+	// Only return the state transitions of the alert rule with this UID
+	// in: query
+	// required: false
+	RuleUID string `json:"ruleUID"`
+
+	// A list of matchers to filter the alert instances by
+	// in: query
+	// required: false
+	Matchers []string `json:"filter"`
+
+	// The start of the time range in epoch milliseconds
+	// in: query
+	// required: false
+	From int64 `json:"from"`
+
+	// The end of the time range in epoch milliseconds
+	// in: query
+	// required: false
+	To int64 `json:"to"`
+
+	// The maximum number of state transitions to return, starting from the most recent, at most 1000
+	// in: query
+	// required: false
+	// default: 1000
+	Limit int64 `json:"limit"`
+}
+
+// swagger:model
+type StateHistoryResponse struct {
+	// Frames has a frame for each alert instance, with a row for each state transition
+	// ordered by time. The state fields have the labels of the alert instance.
+	Frames data.Frames `json:"frames"`
+}
@@ -91,6 +91,8 @@ type AlertingRule struct {
 	Annotations overrideLabels `json:"annotations,omitempty"`
 	// required: true
 	Alerts []*Alert `json:"alerts,omitempty"`
+	// IsPaused is true if the rule is paused and not evaluated.
+	IsPaused bool `json:"isPaused,omitempty"`
 	Rule
 }
 
@@ -109,6 +111,13 @@ type Rule struct {
 	Type           v1.RuleType `json:"type"`
 	LastEvaluation time.Time   `json:"lastEvaluation"`
 	EvaluationTime float64     `json:"evaluationTime"`
+	// LastErrorRefID is the RefID of the query or expression that failed in the last
+	// evaluation, if the error is specific to one.
+	LastErrorRefID string `json:"lastErrorRefId,omitempty"`
+	// EvaluationAttempts is the number of attempts of the last evaluation.
+	EvaluationAttempts int64 `json:"evaluationAttempts,omitempty"`
+	// ConsecutiveFailures is the number of evaluations that failed in a row.
+	ConsecutiveFailures int64 `json:"consecutiveFailures,omitempty"`
 }
 
 // Alert has info for an alert.
@@ -123,6 +132,8 @@ type Alert struct {
 	ActiveAt *time.Time `json:"activeAt"`
 	// required: true
 	Value string `json:"value"`
+	// SuppressedBy is the UID of the rule whose firing alert suppresses the alert.
+	SuppressedBy string `json:"suppressedBy,omitempty"`
 }
 
 // override the labels type with a map for generation.
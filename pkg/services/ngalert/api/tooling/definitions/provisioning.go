@@ -0,0 +1,26 @@
+package definitions
+
+// swagger:route GET /api/v1/provisioning/export provisioning RouteGetProvisioningExport
+//
+// exports the Grafana managed alert rules and the Alertmanager configuration of the organization in the format of the provisioning files
+//
+//     Produces:
+//     - application/yaml
+//
+//     Responses:
+//       200: ProvisioningFile
+//       403: PermissionDenied
+
+// swagger:parameters RouteGetProvisioningExport
+type ProvisioningExportParams struct {
+	// Include the decrypted secure settings of the contact points. Only organization
+	// administrators can export them.
+	// in: query
+	// required: false
+	// default: false
+	Decrypt bool `json:"decrypt"`
+}
+
+// ProvisioningFile is a provisioning file of alerting resources in YAML.
+// swagger:model
+type ProvisioningFile string
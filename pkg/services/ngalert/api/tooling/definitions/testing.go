@@ -9,6 +9,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/promql"
 )
 
@@ -38,6 +39,20 @@ import (
 //     Responses:
 //       200: EvalQueriesResponse
 
+// swagger:route Post /api/v1/rule/backtest testing RouteBacktestRule
+//
+// Backtest a Grafana managed alert rule over a time range
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: BacktestResult
+//       400: ValidationError
+
 // swagger:parameters RouteTestReceiverConfig
 type TestReceiverRequest struct {
 	// in:body
@@ -70,6 +85,60 @@ type EvalQueriesPayload struct {
 	Now  time.Time           `json:"now"`
 }
 
+// swagger:parameters RouteBacktestRule
+type BacktestRequest struct {
+	// in:body
+	Body BacktestConfig
+}
+
+// swagger:model
+type BacktestConfig struct {
+	// The start of the time range to evaluate the alert rule over
+	From time.Time `json:"from"`
+	// The end of the time range to evaluate the alert rule over
+	To time.Time `json:"to"`
+	// The interval at which the alert rule is evaluated
+	Interval     model.Duration      `json:"interval,omitempty"`
+	Title        string              `json:"title"`
+	Condition    string              `json:"condition"`
+	Data         []models.AlertQuery `json:"data"`
+	For          model.Duration      `json:"for,omitempty"`
+	Labels       map[string]string   `json:"labels,omitempty"`
+	NoDataState  NoDataState         `json:"no_data_state"`
+	ExecErrState ExecutionErrorState `json:"exec_err_state"`
+}
+
+// swagger:model
+type BacktestResult struct {
+	// Instances are the alert instances of the alert rule, with their state at each evaluation.
+	Instances []BacktestInstance `json:"instances"`
+	// Notifications are the alerts that would have started firing or been resolved,
+	// ordered by time.
+	Notifications []BacktestNotification `json:"notifications"`
+}
+
+type BacktestInstance struct {
+	Labels map[string]string `json:"labels"`
+	States []BacktestState   `json:"states"`
+}
+
+type BacktestState struct {
+	Time  time.Time `json:"time"`
+	State string    `json:"state"`
+	// Reason explains the state when it is not the result of the evaluation,
+	// such as the error of a failed evaluation.
+	Reason string `json:"reason,omitempty"`
+	// Value is the string representation of the values of the evaluation.
+	Value string `json:"value,omitempty"`
+}
+
+type BacktestNotification struct {
+	Time time.Time `json:"time"`
+	// Status is either firing or resolved.
+	Status string            `json:"status"`
+	Labels map[string]string `json:"labels"`
+}
+
 func (p *TestRulePayload) UnmarshalJSON(b []byte) error {
 	type plain TestRulePayload
 	if err := json.Unmarshal(b, (*plain)(p)); err != nil {
@@ -0,0 +1,153 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// MaxEvaluations is the maximum number of evaluations of a backtest.
+const MaxEvaluations = 1000
+
+var (
+	ErrInvalidTimeRange   = errors.New("the start of the time range must be before its end")
+	ErrInvalidInterval    = errors.New("the interval must be a positive number of seconds")
+	ErrTooManyEvaluations = errors.New("the time range has too many evaluations, increase the interval or shorten the time range")
+)
+
+// EvaluateFunc evaluates the condition of the alert rule at a point in time.
+type EvaluateFunc func(now time.Time) eval.Results
+
+// Validate returns an error if the alert rule cannot be backtested over the time range.
+func Validate(rule *ngmodels.AlertRule, from, to time.Time) error {
+	if !from.Before(to) {
+		return ErrInvalidTimeRange
+	}
+	if rule.IntervalSeconds <= 0 {
+		return ErrInvalidInterval
+	}
+	if evaluations := to.Sub(from)/(time.Duration(rule.IntervalSeconds)*time.Second) + 1; evaluations > MaxEvaluations {
+		return ErrTooManyEvaluations
+	}
+	return nil
+}
+
+// Run evaluates the alert rule at its interval from the start to the end of the time range,
+// and processes the results the same way the scheduler does, including the For duration and
+// the NoData and error handling of the alert rule. The states are kept in memory and nothing
+// is saved or sent to the Alertmanager.
+//
+// The notifications are the alerts that start firing or are resolved. They do not take into
+// account the grouping, inhibitions and repeat interval of the notification policies.
+func Run(logger log.Logger, rule *ngmodels.AlertRule, from, to time.Time, evaluate EvaluateFunc) apimodels.BacktestResult {
+	manager := state.NewManager(logger, metrics.NewNGAlert(prometheus.NewRegistry()).GetStateMetrics(), nil, nil, nil, nil, nil)
+	defer manager.Close()
+
+	var (
+		instances     = make(map[string]*apimodels.BacktestInstance)
+		lastStates    = make(map[string]eval.State)
+		notifications = make([]apimodels.BacktestNotification, 0)
+		interval      = time.Duration(rule.IntervalSeconds) * time.Second
+	)
+
+	for now := from; !now.After(to); now = now.Add(interval) {
+		states := manager.ProcessEvalResults(context.Background(), rule, evaluate(now))
+		for _, s := range states {
+			instance, ok := instances[s.CacheId]
+			if !ok {
+				instance = &apimodels.BacktestInstance{Labels: instanceLabels(s)}
+				instances[s.CacheId] = instance
+			}
+			instance.States = append(instance.States, toBacktestState(s, now))
+
+			lastState, ok := lastStates[s.CacheId]
+			if !ok {
+				lastState = eval.Normal
+			}
+			if lastState != s.State {
+				if isFiring(lastState) {
+					notifications = append(notifications, apimodels.BacktestNotification{
+						Time:   now,
+						Status: string(model.AlertResolved),
+						Labels: alertLabels(s, lastState, rule),
+					})
+				}
+				if isFiring(s.State) {
+					notifications = append(notifications, apimodels.BacktestNotification{
+						Time:   now,
+						Status: string(model.AlertFiring),
+						Labels: alertLabels(s, s.State, rule),
+					})
+				}
+			}
+			lastStates[s.CacheId] = s.State
+		}
+	}
+
+	result := apimodels.BacktestResult{
+		Instances:     make([]apimodels.BacktestInstance, 0, len(instances)),
+		Notifications: notifications,
+	}
+	for _, instance := range instances {
+		result.Instances = append(result.Instances, *instance)
+	}
+	sort.Slice(result.Instances, func(i, j int) bool {
+		return data.Labels(result.Instances[i].Labels).String() < data.Labels(result.Instances[j].Labels).String()
+	})
+	return result
+}
+
+func toBacktestState(s *state.State, now time.Time) apimodels.BacktestState {
+	result := apimodels.BacktestState{
+		Time:  now,
+		State: s.State.String(),
+	}
+	if len(s.Results) > 0 {
+		last := s.Results[len(s.Results)-1]
+		result.Value = last.EvaluationString
+		if last.EvaluationState != s.State && (last.EvaluationState == eval.NoData || last.EvaluationState == eval.Error) {
+			// the state was set by the no data or error handling of the alert rule
+			result.Reason = last.EvaluationState.String()
+		}
+	}
+	if s.Error != nil {
+		result.Reason = s.Error.Error()
+	}
+	return result
+}
+
+// instanceLabels returns the labels of the alert instance without the labels of the UID and
+// folder of the alert rule, as the backtested alert rule is not saved.
+func instanceLabels(s *state.State) map[string]string {
+	lbls := s.Labels.Copy()
+	delete(lbls, ngmodels.RuleUIDLabel)
+	delete(lbls, ngmodels.NamespaceUIDLabel)
+	return lbls
+}
+
+// alertLabels returns the labels of the alert sent for the alert instance in the state.
+func alertLabels(s *state.State, st eval.State, rule *ngmodels.AlertRule) map[string]string {
+	lbls := instanceLabels(s)
+	if st == eval.Error {
+		// alerts for failed evaluations have their own name, as in the scheduler
+		lbls[ngmodels.RuleNameLabel] = rule.Title
+		lbls[model.AlertNameLabel] = ngmodels.ErrorAlertName
+	}
+	return lbls
+}
+
+func isFiring(s eval.State) bool {
+	return s == eval.Alerting || s == eval.Error
+}
@@ -0,0 +1,140 @@
+package backtesting
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestValidate(t *testing.T) {
+	from := time.Unix(0, 0)
+	rule := &models.AlertRule{IntervalSeconds: 10}
+
+	require.NoError(t, Validate(rule, from, from.Add(time.Hour)))
+	require.NoError(t, Validate(rule, from, from.Add((MaxEvaluations-1)*10*time.Second)))
+	require.ErrorIs(t, Validate(rule, from, from.Add(MaxEvaluations*10*time.Second)), ErrTooManyEvaluations)
+	require.ErrorIs(t, Validate(rule, from, from), ErrInvalidTimeRange)
+	require.ErrorIs(t, Validate(rule, from.Add(time.Hour), from), ErrInvalidTimeRange)
+	require.ErrorIs(t, Validate(&models.AlertRule{}, from, from.Add(time.Hour)), ErrInvalidInterval)
+}
+
+func TestRun(t *testing.T) {
+	from := time.Unix(1634000000, 0)
+	at := func(i int) time.Time {
+		return from.Add(time.Duration(i) * 10 * time.Second)
+	}
+
+	evalErr := errors.New("failed to execute query")
+
+	testCases := []struct {
+		desc                  string
+		rule                  *models.AlertRule
+		results               []eval.State
+		expectedStates        []apimodels.BacktestState
+		expectedNotifications []apimodels.BacktestNotification
+	}{
+		{
+			desc: "alert instance is pending for the For duration before it fires",
+			rule: &models.AlertRule{
+				Title:           "test",
+				IntervalSeconds: 10,
+				For:             20 * time.Second,
+				NoDataState:     models.NoData,
+				ExecErrState:    models.AlertingErrState,
+			},
+			results: []eval.State{eval.Normal, eval.Alerting, eval.Alerting, eval.Alerting, eval.Alerting, eval.Normal},
+			expectedStates: []apimodels.BacktestState{
+				{Time: at(0), State: "Normal"},
+				{Time: at(1), State: "Pending"},
+				{Time: at(2), State: "Pending"},
+				{Time: at(3), State: "Pending"},
+				{Time: at(4), State: "Alerting"},
+				{Time: at(5), State: "Normal"},
+			},
+			expectedNotifications: []apimodels.BacktestNotification{
+				{Time: at(4), Status: "firing", Labels: map[string]string{"alertname": "test", "team": "a"}},
+				{Time: at(5), Status: "resolved", Labels: map[string]string{"alertname": "test", "team": "a"}},
+			},
+		},
+		{
+			desc: "failed evaluations fire a separate alert when the error state is Error",
+			rule: &models.AlertRule{
+				Title:           "test",
+				IntervalSeconds: 10,
+				NoDataState:     models.NoData,
+				ExecErrState:    models.ErrorErrState,
+			},
+			results: []eval.State{eval.Alerting, eval.Error, eval.Normal},
+			expectedStates: []apimodels.BacktestState{
+				{Time: at(0), State: "Alerting"},
+				{Time: at(1), State: "Error", Reason: evalErr.Error()},
+				{Time: at(2), State: "Normal"},
+			},
+			expectedNotifications: []apimodels.BacktestNotification{
+				{Time: at(0), Status: "firing", Labels: map[string]string{"alertname": "test", "team": "a"}},
+				{Time: at(1), Status: "resolved", Labels: map[string]string{"alertname": "test", "team": "a"}},
+				{Time: at(1), Status: "firing", Labels: map[string]string{"alertname": models.ErrorAlertName, "rulename": "test", "team": "a"}},
+				{Time: at(2), Status: "resolved", Labels: map[string]string{"alertname": models.ErrorAlertName, "rulename": "test", "team": "a"}},
+			},
+		},
+		{
+			desc: "failed evaluations keep the last state when the error state is KeepLastState",
+			rule: &models.AlertRule{
+				Title:           "test",
+				IntervalSeconds: 10,
+				NoDataState:     models.NoData,
+				ExecErrState:    models.KeepLastStateErrState,
+			},
+			results: []eval.State{eval.Alerting, eval.Error, eval.Error, eval.Normal},
+			expectedStates: []apimodels.BacktestState{
+				{Time: at(0), State: "Alerting"},
+				{Time: at(1), State: "Alerting", Reason: evalErr.Error()},
+				{Time: at(2), State: "Alerting", Reason: evalErr.Error()},
+				{Time: at(3), State: "Normal"},
+			},
+			expectedNotifications: []apimodels.BacktestNotification{
+				{Time: at(0), Status: "firing", Labels: map[string]string{"alertname": "test", "team": "a"}},
+				{Time: at(3), Status: "resolved", Labels: map[string]string{"alertname": "test", "team": "a"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			i := 0
+			evaluate := func(now time.Time) eval.Results {
+				require.Equal(t, at(i), now)
+				result := eval.Result{
+					State:       tc.results[i],
+					EvaluatedAt: now,
+				}
+				if result.State == eval.Error {
+					result.Error = evalErr
+				}
+				// a failed evaluation has no labels, so it only applies to the alert
+				// instance when the alert rule keeps the last state
+				if result.State != eval.Error || tc.rule.ExecErrState != models.KeepLastStateErrState {
+					result.Instance = data.Labels{"team": "a"}
+				}
+				i++
+				return eval.Results{result}
+			}
+
+			result := Run(log.New("test"), tc.rule, from, at(len(tc.results)-1), evaluate)
+
+			require.Equal(t, len(tc.results), i)
+			require.Len(t, result.Instances, 1)
+			require.Equal(t, map[string]string{"alertname": "test", "team": "a"}, result.Instances[0].Labels)
+			require.Equal(t, tc.expectedStates, result.Instances[0].States)
+			require.Equal(t, tc.expectedNotifications, result.Notifications)
+		})
+	}
+}
@@ -4,6 +4,7 @@ package eval
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"runtime/debug"
 	"sort"
@@ -24,6 +25,8 @@ import (
 type Evaluator struct {
 	Cfg *setting.Cfg
 	Log log.Logger
+	// QueryCache, if set, is shared by all evaluations to deduplicate data source queries.
+	QueryCache *expr.QueryCache
 }
 
 // invalidEvalResultFormatError is an error for invalid format of the alert definition evaluation results.
@@ -45,6 +48,20 @@ func (e *invalidEvalResultFormatError) Unwrap() error {
 	return e.err
 }
 
+// ErrorRefID returns the RefID of the query or expression that caused the error of an
+// evaluation, or an empty string if the error is not specific to a query or expression.
+func ErrorRefID(err error) string {
+	var formatErr *invalidEvalResultFormatError
+	if errors.As(err, &formatErr) {
+		return formatErr.refID
+	}
+	var nodeErr *expr.NodeError
+	if errors.As(err, &nodeErr) {
+		return nodeErr.RefID
+	}
+	return ""
+}
+
 // ExecutionResults contains the unevaluated results from executing
 // a condition.
 type ExecutionResults struct {
@@ -112,6 +129,7 @@ type AlertExecCtx struct {
 	OrgID              int64
 	ExpressionsEnabled bool
 	Log                log.Logger
+	QueryCache         *expr.QueryCache
 
 	Ctx context.Context
 }
@@ -253,6 +271,7 @@ func executeQueriesAndExpressions(ctx AlertExecCtx, data []models.AlertQuery, no
 	exprService := expr.Service{
 		Cfg:         &setting.Cfg{ExpressionsEnabled: ctx.ExpressionsEnabled},
 		DataService: dataService,
+		QueryCache:  ctx.QueryCache,
 	}
 	return exprService.TransformData(ctx.Ctx, queryDataReq)
 }
@@ -435,7 +454,7 @@ func (e *Evaluator) ConditionEval(condition *models.Condition, now time.Time, da
 	alertCtx, cancelFn := context.WithTimeout(context.Background(), e.Cfg.UnifiedAlerting.EvaluationTimeout)
 	defer cancelFn()
 
-	alertExecCtx := AlertExecCtx{OrgID: condition.OrgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled, Log: e.Log}
+	alertExecCtx := AlertExecCtx{OrgID: condition.OrgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled, Log: e.Log, QueryCache: e.QueryCache}
 
 	execResult := executeCondition(alertExecCtx, condition, now, dataService)
 
@@ -443,12 +462,27 @@ func (e *Evaluator) ConditionEval(condition *models.Condition, now time.Time, da
 	return evalResults, nil
 }
 
+// ConditionRecord executes the condition of a recording rule and returns the frames of
+// the condition, which have a single number for each series.
+func (e *Evaluator) ConditionRecord(condition *models.Condition, now time.Time, dataService *tsdb.Service) (data.Frames, error) {
+	alertCtx, cancelFn := context.WithTimeout(context.Background(), e.Cfg.UnifiedAlerting.EvaluationTimeout)
+	defer cancelFn()
+
+	alertExecCtx := AlertExecCtx{OrgID: condition.OrgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled, Log: e.Log, QueryCache: e.QueryCache}
+
+	execResult := executeCondition(alertExecCtx, condition, now, dataService)
+	if execResult.Error != nil {
+		return nil, execResult.Error
+	}
+	return execResult.Results, nil
+}
+
 // QueriesAndExpressionsEval executes queries and expressions and returns the result.
 func (e *Evaluator) QueriesAndExpressionsEval(orgID int64, data []models.AlertQuery, now time.Time, dataService *tsdb.Service) (*backend.QueryDataResponse, error) {
 	alertCtx, cancelFn := context.WithTimeout(context.Background(), e.Cfg.UnifiedAlerting.EvaluationTimeout)
 	defer cancelFn()
 
-	alertExecCtx := AlertExecCtx{OrgID: orgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled, Log: e.Log}
+	alertExecCtx := AlertExecCtx{OrgID: orgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled, Log: e.Log, QueryCache: e.QueryCache}
 
 	execResult, err := executeQueriesAndExpressions(alertExecCtx, data, now, dataService)
 	if err != nil {
@@ -1,6 +1,7 @@
 package eval
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -8,6 +9,8 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
 	ptr "github.com/xorcare/pointer"
+
+	"github.com/grafana/grafana/pkg/expr"
 )
 
 func TestEvaluateExecutionResult(t *testing.T) {
@@ -294,3 +297,32 @@ func TestEvaluateExecutionResult(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorRefID(t *testing.T) {
+	cases := []struct {
+		desc          string
+		err           error
+		expectedRefID string
+	}{
+		{
+			desc:          "invalid format of the results",
+			err:           &invalidEvalResultFormatError{refID: "B", reason: "unexpected row length"},
+			expectedRefID: "B",
+		},
+		{
+			desc:          "failed query",
+			err:           fmt.Errorf("queries and expressions execution failed: %w", &expr.NodeError{RefID: "A", Err: errors.New("timeout")}),
+			expectedRefID: "A",
+		},
+		{
+			desc: "other error",
+			err:  errors.New("alert rule panic"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.Equal(t, tc.expectedRefID, ErrorRefID(tc.err))
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/imguploader"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	screenshotWidth  = 1000
+	screenshotHeight = 500
+
+	// imageExpiration is how long images are kept. Notifications that are sent again
+	// after that do not have an image.
+	imageExpiration = 24 * time.Hour
+)
+
+var (
+	// ErrNoDashboard is returned when the alert rule is not linked to a dashboard.
+	ErrNoDashboard = errors.New("no dashboard")
+	// ErrNoPanel is returned when the alert rule is not linked to a panel.
+	ErrNoPanel = errors.New("no panel")
+)
+
+// ImageService takes screenshots of the panels of alert rules.
+type ImageService interface {
+	// NewImage takes a screenshot of the panel of the alert rule for an evaluation, and
+	// saves it. The alert instances of the same evaluation share the same image.
+	NewImage(ctx context.Context, r *ngmodels.AlertRule, evaluatedAt time.Time) (*ngmodels.Image, error)
+	// ForgetAlertRule forgets the image of the last evaluation of the alert rule. It is
+	// called when the alert rule is deleted or not evaluated by this instance anymore.
+	ForgetAlertRule(key ngmodels.AlertRuleKey)
+}
+
+type cachedImage struct {
+	evaluatedAt time.Time
+	image       *ngmodels.Image
+	err         error
+}
+
+// ScreenshotImageService takes screenshots with the image renderer, and optionally uploads
+// them to the external image storage.
+type ScreenshotImageService struct {
+	renderService rendering.Service
+	uploader      imguploader.ImageUploader
+	store         store.ImageStore
+	timeout       time.Duration
+	limit         chan struct{}
+	log           log.Logger
+
+	mu sync.Mutex
+	// cache has the image of the last evaluation of each alert rule.
+	cache map[ngmodels.AlertRuleKey]cachedImage
+}
+
+// NewScreenshotImageService returns an ImageService that takes screenshots of panels
+// with the image renderer. The uploader is nil if the images are not uploaded.
+func NewScreenshotImageService(cfg setting.UnifiedAlertingSettings, renderService rendering.Service, uploader imguploader.ImageUploader, imageStore store.ImageStore, l log.Logger) *ScreenshotImageService {
+	maxConcurrent := cfg.ScreenshotsMaxConcurrent
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+	return &ScreenshotImageService{
+		renderService: renderService,
+		uploader:      uploader,
+		store:         imageStore,
+		timeout:       cfg.ScreenshotsCaptureTimeout,
+		limit:         make(chan struct{}, maxConcurrent),
+		log:           l,
+		cache:         make(map[ngmodels.AlertRuleKey]cachedImage),
+	}
+}
+
+// NewImage takes a screenshot of the panel of the alert rule for an evaluation, or returns
+// the image already taken for the same evaluation. The evaluation waits for the screenshot,
+// so taking it, including the wait for a free renderer, is limited to the capture timeout.
+func (s *ScreenshotImageService) NewImage(ctx context.Context, r *ngmodels.AlertRule, evaluatedAt time.Time) (*ngmodels.Image, error) {
+	key := r.GetKey()
+	s.mu.Lock()
+	cached, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && cached.evaluatedAt.Equal(evaluatedAt) {
+		return cached.image, cached.err
+	}
+
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	// the results of an evaluation are processed sequentially, so there is no concurrent
+	// screenshot of the same alert rule
+	image, err := s.newImage(ctx, r)
+	s.mu.Lock()
+	s.cache[key] = cachedImage{evaluatedAt: evaluatedAt, image: image, err: err}
+	s.mu.Unlock()
+	return image, err
+}
+
+// ForgetAlertRule forgets the image of the last evaluation of the alert rule.
+func (s *ScreenshotImageService) ForgetAlertRule(key ngmodels.AlertRuleKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cache, key)
+}
+
+func (s *ScreenshotImageService) newImage(ctx context.Context, r *ngmodels.AlertRule) (*ngmodels.Image, error) {
+	dashboardUID := r.Annotations[ngmodels.DashboardUIDAnnotation]
+	if dashboardUID == "" {
+		return nil, ErrNoDashboard
+	}
+	panelID := r.Annotations[ngmodels.PanelIDAnnotation]
+	if panelID == "" {
+		return nil, ErrNoPanel
+	}
+
+	select {
+	case s.limit <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-s.limit }()
+
+	start := time.Now()
+	result, err := s.renderService.Render(ctx, rendering.Opts{
+		Width:   screenshotWidth,
+		Height:  screenshotHeight,
+		Timeout: s.timeout,
+		OrgID:   r.OrgID,
+		OrgRole: models.ROLE_ADMIN,
+		Path:    fmt.Sprintf("d-solo/%s?orgId=%d&panelId=%s", dashboardUID, r.OrgID, panelID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to take screenshot: %w", err)
+	}
+	s.log.Debug("took screenshot of alert rule panel", "rule_uid", r.UID, "path", result.FilePath, "took", time.Since(start))
+
+	now := time.Now()
+	image := &ngmodels.Image{
+		Path:      result.FilePath,
+		CreatedAt: now,
+		ExpiresAt: now.Add(imageExpiration),
+	}
+	if s.uploader != nil {
+		image.URL, err = s.uploader.Upload(ctx, result.FilePath)
+		if err != nil {
+			// the image can still be attached to notifications
+			s.log.Warn("failed to upload screenshot to external image storage", "rule_uid", r.UID, "err", err)
+		}
+	}
+
+	if err := s.store.SaveImage(ctx, image); err != nil {
+		return nil, fmt.Errorf("failed to save image: %w", err)
+	}
+	return image, nil
+}
+
+// DeleteExpiredImages deletes the expired images and their files, and returns the number
+// of deleted images.
+func (s *ScreenshotImageService) DeleteExpiredImages(ctx context.Context) (int64, error) {
+	paths, err := s.store.DeleteExpiredImages(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			s.log.Warn("failed to delete image file", "path", path, "err", err)
+		}
+	}
+	return int64(len(paths)), nil
+}
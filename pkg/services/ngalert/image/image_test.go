@@ -0,0 +1,176 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type testRenderService struct {
+	rendering.Service
+	dir   string
+	paths []string
+	// block makes Render wait until the context is done.
+	block bool
+}
+
+func (s *testRenderService) Render(ctx context.Context, opts rendering.Opts) (*rendering.RenderResult, error) {
+	s.paths = append(s.paths, opts.Path)
+	if s.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	path := filepath.Join(s.dir, "screenshot.png")
+	if err := os.WriteFile(path, []byte("png"), 0600); err != nil {
+		return nil, err
+	}
+	return &rendering.RenderResult{FilePath: path}, nil
+}
+
+type testUploader struct {
+	err error
+}
+
+func (u *testUploader) Upload(_ context.Context, path string) (string, error) {
+	if u.err != nil {
+		return "", u.err
+	}
+	return "http://images/" + filepath.Base(path), nil
+}
+
+type testImageStore struct {
+	images  []*ngmodels.Image
+	expired []string
+}
+
+func (s *testImageStore) GetImage(_ context.Context, token string) (*ngmodels.Image, error) {
+	for _, image := range s.images {
+		if image.Token == token {
+			return image, nil
+		}
+	}
+	return nil, ngmodels.ErrImageNotFound
+}
+
+func (s *testImageStore) SaveImage(_ context.Context, image *ngmodels.Image) error {
+	image.Token = filepath.Base(image.Path)
+	s.images = append(s.images, image)
+	return nil
+}
+
+func (s *testImageStore) DeleteExpiredImages(context.Context) ([]string, error) {
+	return s.expired, nil
+}
+
+func TestScreenshotImageService(t *testing.T) {
+	cfg := setting.UnifiedAlertingSettings{ScreenshotsCaptureTimeout: time.Second, ScreenshotsMaxConcurrent: 1}
+	rule := &ngmodels.AlertRule{
+		OrgID:       1,
+		UID:         "rule",
+		Annotations: map[string]string{ngmodels.DashboardUIDAnnotation: "dashboard", ngmodels.PanelIDAnnotation: "2"},
+	}
+	evaluatedAt := time.Unix(0, 0)
+
+	t.Run("alert rules without a panel do not have an image", func(t *testing.T) {
+		renderer := &testRenderService{dir: t.TempDir()}
+		s := NewScreenshotImageService(cfg, renderer, nil, &testImageStore{}, log.New("test"))
+
+		_, err := s.NewImage(context.Background(), &ngmodels.AlertRule{OrgID: 1, UID: "no-dashboard"}, evaluatedAt)
+		require.ErrorIs(t, err, ErrNoDashboard)
+		_, err = s.NewImage(context.Background(), &ngmodels.AlertRule{
+			OrgID:       1,
+			UID:         "no-panel",
+			Annotations: map[string]string{ngmodels.DashboardUIDAnnotation: "dashboard"},
+		}, evaluatedAt)
+		require.ErrorIs(t, err, ErrNoPanel)
+		require.Empty(t, renderer.paths)
+	})
+
+	t.Run("one screenshot is taken per evaluation", func(t *testing.T) {
+		renderer := &testRenderService{dir: t.TempDir()}
+		store := &testImageStore{}
+		s := NewScreenshotImageService(cfg, renderer, nil, store, log.New("test"))
+
+		image, err := s.NewImage(context.Background(), rule, evaluatedAt)
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(renderer.dir, "screenshot.png"), image.Path)
+		require.False(t, image.HasURL())
+		require.Equal(t, []string{"d-solo/dashboard?orgId=1&panelId=2"}, renderer.paths)
+
+		cached, err := s.NewImage(context.Background(), rule, evaluatedAt)
+		require.NoError(t, err)
+		require.Same(t, image, cached)
+		require.Len(t, renderer.paths, 1)
+
+		_, err = s.NewImage(context.Background(), rule, evaluatedAt.Add(time.Minute))
+		require.NoError(t, err)
+		require.Len(t, renderer.paths, 2)
+		require.Len(t, store.images, 2)
+	})
+
+	t.Run("screenshots are limited to the capture timeout", func(t *testing.T) {
+		cfg := setting.UnifiedAlertingSettings{ScreenshotsCaptureTimeout: 10 * time.Millisecond, ScreenshotsMaxConcurrent: 1}
+		s := NewScreenshotImageService(cfg, &testRenderService{dir: t.TempDir(), block: true}, nil, &testImageStore{}, log.New("test"))
+
+		_, err := s.NewImage(context.Background(), rule, evaluatedAt)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("images of forgotten alert rules are not cached", func(t *testing.T) {
+		renderer := &testRenderService{dir: t.TempDir()}
+		s := NewScreenshotImageService(cfg, renderer, nil, &testImageStore{}, log.New("test"))
+
+		_, err := s.NewImage(context.Background(), rule, evaluatedAt)
+		require.NoError(t, err)
+		require.Len(t, s.cache, 1)
+
+		s.ForgetAlertRule(rule.GetKey())
+		require.Empty(t, s.cache)
+		_, err = s.NewImage(context.Background(), rule, evaluatedAt)
+		require.NoError(t, err)
+		require.Len(t, renderer.paths, 2)
+	})
+
+	t.Run("screenshots are uploaded to the external image storage", func(t *testing.T) {
+		renderer := &testRenderService{dir: t.TempDir()}
+		s := NewScreenshotImageService(cfg, renderer, &testUploader{}, &testImageStore{}, log.New("test"))
+
+		image, err := s.NewImage(context.Background(), rule, evaluatedAt)
+		require.NoError(t, err)
+		require.Equal(t, "http://images/screenshot.png", image.URL)
+	})
+
+	t.Run("screenshots that fail to upload are kept", func(t *testing.T) {
+		renderer := &testRenderService{dir: t.TempDir()}
+		s := NewScreenshotImageService(cfg, renderer, &testUploader{err: errors.New("access denied")}, &testImageStore{}, log.New("test"))
+
+		image, err := s.NewImage(context.Background(), rule, evaluatedAt)
+		require.NoError(t, err)
+		require.False(t, image.HasURL())
+		require.NotEmpty(t, image.Path)
+	})
+
+	t.Run("the files of expired images are deleted", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "expired.png")
+		require.NoError(t, os.WriteFile(path, []byte("png"), 0600))
+		store := &testImageStore{expired: []string{path, filepath.Join(dir, "missing.png")}}
+		s := NewScreenshotImageService(cfg, &testRenderService{dir: dir}, nil, store, log.New("test"))
+
+		deleted, err := s.DeleteExpiredImages(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, int64(2), deleted)
+		_, err = os.Stat(path)
+		require.True(t, errors.Is(err, os.ErrNotExist))
+	})
+}
@@ -49,6 +49,13 @@ type Scheduler struct {
 	EvalTotal    *prometheus.CounterVec
 	EvalFailures *prometheus.CounterVec
 	EvalDuration *prometheus.SummaryVec
+	EvalSkipped  *prometheus.CounterVec
+	EvalWait     *prometheus.HistogramVec
+
+	GroupEvalDuration *prometheus.HistogramVec
+	GroupEvalFailures *prometheus.CounterVec
+	GroupEvalAttempts *prometheus.CounterVec
+	GroupRulesHealth  *prometheus.GaugeVec
 }
 
 type MultiOrgAlertmanager struct {
@@ -152,6 +159,62 @@ func newSchedulerMetrics(r prometheus.Registerer) *Scheduler {
 			},
 			[]string{"org"},
 		),
+		EvalSkipped: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "rule_evaluations_skipped_total",
+				Help:      "The total number of rule evaluations skipped because the previous evaluation was still running or because of the concurrency limits.",
+			},
+			[]string{"org", "reason"},
+		),
+		EvalWait: promauto.With(r).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "rule_evaluation_queue_wait_seconds",
+				Help:      "The time rule evaluations wait for the concurrency limits before they start.",
+				Buckets:   []float64{.01, .1, .5, 1, 5, 10, 30, 60, 120},
+			},
+			[]string{"org"},
+		),
+		GroupEvalDuration: promauto.With(r).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "rule_group_evaluation_duration_seconds",
+				Help:      "The duration of the evaluations of the rules of a rule group.",
+				Buckets:   []float64{.01, .1, .5, 1, 5, 10, 30, 60, 120},
+			},
+			[]string{"org", "namespace", "rule_group"},
+		),
+		GroupEvalFailures: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "rule_group_evaluation_failures_total",
+				Help:      "The total number of evaluations of the rules of a rule group that failed after all their attempts, or that returned errors.",
+			},
+			[]string{"org", "namespace", "rule_group"},
+		),
+		GroupEvalAttempts: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "rule_group_evaluation_attempts_total",
+				Help:      "The total number of attempts to evaluate the rules of a rule group, including retries.",
+			},
+			[]string{"org", "namespace", "rule_group"},
+		),
+		GroupRulesHealth: promauto.With(r).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "rule_group_rules_health",
+				Help:      "The number of rules of a rule group by the health of their last evaluation.",
+			},
+			[]string{"org", "namespace", "rule_group", "health"},
+		),
 	}
 }
 
@@ -28,11 +28,21 @@ func (noDataState NoDataState) String() string {
 }
 
 const (
-	Alerting NoDataState = "Alerting"
-	NoData   NoDataState = "NoData"
-	OK       NoDataState = "OK"
+	Alerting      NoDataState = "Alerting"
+	NoData        NoDataState = "NoData"
+	OK            NoDataState = "OK"
+	KeepLastState NoDataState = "KeepLastState"
 )
 
+// NoDataStateFromString returns the NoDataState of a string, or an error if it is not valid.
+func NoDataStateFromString(state string) (NoDataState, error) {
+	switch NoDataState(state) {
+	case Alerting, NoData, OK, KeepLastState:
+		return NoDataState(state), nil
+	}
+	return "", fmt.Errorf("unknown NoData state option %q", state)
+}
+
 type ExecutionErrorState string
 
 func (executionErrorState ExecutionErrorState) String() string {
@@ -40,16 +50,61 @@ func (executionErrorState ExecutionErrorState) String() string {
 }
 
 const (
-	AlertingErrState ExecutionErrorState = "Alerting"
+	AlertingErrState      ExecutionErrorState = "Alerting"
+	ErrorErrState         ExecutionErrorState = "Error"
+	OkErrState            ExecutionErrorState = "OK"
+	KeepLastStateErrState ExecutionErrorState = "KeepLastState"
 )
 
+// ErrStateFromString returns the ExecutionErrorState of a string, or an error if it is not valid.
+func ErrStateFromString(state string) (ExecutionErrorState, error) {
+	switch ExecutionErrorState(state) {
+	case AlertingErrState, ErrorErrState, OkErrState, KeepLastStateErrState:
+		return ExecutionErrorState(state), nil
+	}
+	return "", fmt.Errorf("unknown Error state option %q", state)
+}
+
+// RecordTarget is where a recording rule writes its time series.
+type RecordTarget string
+
+const (
+	// RecordTargetRemoteWrite writes the time series to the Prometheus remote write
+	// endpoint of the configuration.
+	RecordTargetRemoteWrite RecordTarget = "remote_write"
+	// RecordTargetLive publishes the time series to a Grafana Live channel.
+	RecordTargetLive RecordTarget = "live"
+)
+
+// RecordTargetFromString returns the RecordTarget of a string, or an error if it is not valid.
+func RecordTargetFromString(target string) (RecordTarget, error) {
+	switch RecordTarget(target) {
+	case RecordTargetRemoteWrite, RecordTargetLive:
+		return RecordTarget(target), nil
+	}
+	return "", fmt.Errorf("unknown record target %q", target)
+}
+
 const (
 	RuleUIDLabel      = "__alert_rule_uid__"
 	NamespaceUIDLabel = "__alert_rule_namespace_uid__"
 
+	// RuleNameLabel holds the name of the alert rule in alerts for failed evaluations.
+	RuleNameLabel = "rulename"
+
+	// ErrorAlertName is the name of the alerts sent for alert rules whose evaluation
+	// failed, and that are in the Error state.
+	ErrorAlertName = "DatasourceError"
+
 	// Annotations are actually a set of labels, so technically this is the label name of an annotation.
 	DashboardUIDAnnotation = "__dashboardUid__"
 	PanelIDAnnotation      = "__panelId__"
+
+	// ImageTokenAnnotation is the token of the image of the panel of the alert rule, taken
+	// when the alert started firing, and ImageURLAnnotation its public URL if it was
+	// uploaded to the external image storage.
+	ImageTokenAnnotation = "__alertImageToken__"
+	ImageURLAnnotation   = "__alertImageUrl__"
 )
 
 // AlertRule is the model for alert rules in unified alerting.
@@ -74,6 +129,39 @@ type AlertRule struct {
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	// IsPaused is true if the alert rule is not evaluated.
+	IsPaused bool
+	// IsGroupPaused is true if the rule group of the alert rule is paused. Like the
+	// interval, it is stored with each alert rule of the group.
+	IsGroupPaused bool
+	// Record is the name of the time series written by a recording rule. It is empty
+	// for alert rules.
+	Record string
+	// RecordTarget is where a recording rule writes its time series.
+	RecordTarget RecordTarget
+	// SuppressedByRuleUIDs are the UIDs of the alert rules that suppress the alert
+	// instances of this alert rule while any of their alert instances is firing.
+	SuppressedByRuleUIDs []string `xorm:"suppressed_by_rule_uids"`
+	// SuppressedByLabels suppresses the alert instances of this alert rule while a
+	// firing alert instance of another alert rule has all of these labels.
+	SuppressedByLabels map[string]string
+}
+
+// IsRecording returns true if the alert rule is a recording rule, which writes the
+// result of its condition as a time series instead of creating alert instances.
+func (alertRule *AlertRule) IsRecording() bool {
+	return alertRule.Record != ""
+}
+
+// IsEvaluationPaused returns true if the alert rule or its rule group is paused.
+func (alertRule *AlertRule) IsEvaluationPaused() bool {
+	return alertRule.IsPaused || alertRule.IsGroupPaused
+}
+
+// IsSuppressible returns true if the alert instances of the alert rule can be
+// suppressed by the alert instances of other alert rules.
+func (alertRule *AlertRule) IsSuppressible() bool {
+	return len(alertRule.SuppressedByRuleUIDs) > 0 || len(alertRule.SuppressedByLabels) > 0
 }
 
 // AlertRuleKey is the alert definition identifier
@@ -127,6 +215,22 @@ type AlertRuleVersion struct {
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	// IsPaused is true if the alert rule is not evaluated.
+	IsPaused bool
+	// IsGroupPaused is true if the rule group of the alert rule is paused. Like the
+	// interval, it is stored with each alert rule of the group.
+	IsGroupPaused bool
+	// Record is the name of the time series written by a recording rule. It is empty
+	// for alert rules.
+	Record string
+	// RecordTarget is where a recording rule writes its time series.
+	RecordTarget RecordTarget
+	// SuppressedByRuleUIDs are the UIDs of the alert rules that suppress the alert
+	// instances of this alert rule while any of their alert instances is firing.
+	SuppressedByRuleUIDs []string `xorm:"suppressed_by_rule_uids"`
+	// SuppressedByLabels suppresses the alert instances of this alert rule while a
+	// firing alert instance of another alert rule has all of these labels.
+	SuppressedByLabels map[string]string
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
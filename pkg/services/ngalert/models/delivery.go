@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+)
+
+// NotificationDeliveryStatus is the outcome of an attempt to send a notification.
+type NotificationDeliveryStatus string
+
+const (
+	// NotificationDelivered is an attempt that sent the notification.
+	NotificationDelivered NotificationDeliveryStatus = "delivered"
+	// NotificationFailed is an attempt that failed and is retried, until the
+	// notification times out.
+	NotificationFailed NotificationDeliveryStatus = "failed"
+	// NotificationDropped is an attempt that failed and is not retried, so the
+	// notification is not sent.
+	NotificationDropped NotificationDeliveryStatus = "dropped"
+)
+
+// NotificationDelivery is an attempt of an integration of a contact point to send the
+// notification of an alert group.
+type NotificationDelivery struct {
+	ID              int64  `xorm:"pk autoincr 'id'"`
+	OrgID           int64  `xorm:"org_id"`
+	Receiver        string `xorm:"receiver"`
+	IntegrationType string `xorm:"integration_type"`
+	IntegrationUID  string `xorm:"integration_uid"`
+	// GroupKey identifies the alert group of the notification.
+	GroupKey string `xorm:"group_key"`
+	// Alerts is the number of alerts in the notification.
+	Alerts int `xorm:"alerts"`
+	// Attempt is the number of the attempt to send the notification, starting from 1.
+	Attempt int                        `xorm:"attempt"`
+	Status  NotificationDeliveryStatus `xorm:"status"`
+	// StatusCode is the HTTP status code of the last request of the integration, or 0
+	// if it did not get a response.
+	StatusCode int    `xorm:"status_code"`
+	Error      string `xorm:"error"`
+	// DurationMs is the time the attempt took in milliseconds.
+	DurationMs int64     `xorm:"duration_ms"`
+	SentAt     time.Time `xorm:"sent_at"`
+}
+
+// ListNotificationDeliveriesQuery is the query for listing the attempts of the contact
+// points of an organization to send notifications.
+type ListNotificationDeliveriesQuery struct {
+	OrgID    int64
+	Receiver string
+	// IntegrationUID only returns the attempts of an integration of the contact point.
+	IntegrationUID string
+	Status         NotificationDeliveryStatus
+	From           time.Time
+	To             time.Time
+	// Limit is the maximum number of attempts returned, starting from the most recent.
+	Limit int
+
+	Result []*NotificationDelivery
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// RuleHealth is the health of the last evaluation of an alert rule.
+type RuleHealth string
+
+const (
+	// RuleHealthOK means that the last evaluation succeeded.
+	RuleHealthOK RuleHealth = "ok"
+	// RuleHealthError means that the last evaluation failed, or that it returned an error
+	// for some of the alert instances.
+	RuleHealthError RuleHealth = "error"
+	// RuleHealthNoData means that the last evaluation returned no data.
+	RuleHealthNoData RuleHealth = "nodata"
+)
+
+// AlertRuleHealth is the status of the last evaluation of an alert rule by the scheduler.
+type AlertRuleHealth struct {
+	OrgID        int64
+	RuleUID      string
+	NamespaceUID string
+	RuleGroup    string
+
+	Health             RuleHealth
+	LastEvaluation     time.Time
+	EvaluationDuration time.Duration
+	// LastError is the error of the last evaluation, and LastErrorRefID is the RefID of
+	// the query or expression that failed, if the error is specific to one.
+	LastError      string
+	LastErrorRefID string
+	// Attempts is the number of attempts of the last evaluation.
+	Attempts int64
+	// ConsecutiveFailures is the number of evaluations that failed since FailingSince.
+	ConsecutiveFailures int64
+	FailingSince        time.Time
+}
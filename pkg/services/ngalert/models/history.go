@@ -0,0 +1,71 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+)
+
+// AlertStateHistoryEntry is a transition of an alert instance from one state to another.
+type AlertStateHistoryEntry struct {
+	ID            int64  `xorm:"pk autoincr 'id'"`
+	RuleOrgID     int64  `xorm:"rule_org_id"`
+	RuleUID       string `xorm:"rule_uid"`
+	Labels        InstanceLabels
+	LabelsHash    string
+	PreviousState InstanceStateType
+	CurrentState  InstanceStateType
+	// Reason explains the current state when it is not the result of the evaluation,
+	// such as the error of a failed evaluation.
+	Reason           string
+	EvaluationValues StateHistoryValues
+	EvaluatedAt      time.Time
+}
+
+// StateHistoryValues are the values of the queries and expressions of an evaluation
+// by RefID, with methods for database serialization.
+type StateHistoryValues map[string]float64
+
+// FromDB loads values stored in the database as a json object into StateHistoryValues.
+// FromDB is part of the xorm Conversion interface.
+func (v *StateHistoryValues) FromDB(b []byte) error {
+	values := StateHistoryValues{}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+	}
+	*v = values
+	return nil
+}
+
+// ToDB stores StateHistoryValues as a json object.
+// ToDB is part of the xorm Conversion interface.
+func (v *StateHistoryValues) ToDB() ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// ListAlertStateHistoryQuery is the query for listing the state history of alert instances.
+type ListAlertStateHistoryQuery struct {
+	OrgID   int64
+	RuleUID string
+	// Matchers filter the alert instances by labels.
+	Matchers []*labels.Matcher
+	From     time.Time
+	To       time.Time
+	// Limit is the maximum number of entries returned, starting from the most recent.
+	Limit int
+
+	Result []*AlertStateHistoryEntry
+}
+
+// Matches returns true if the labels of the alert instance of the entry match all matchers.
+func (q *ListAlertStateHistoryQuery) Matches(entry *AlertStateHistoryEntry) bool {
+	for _, m := range q.Matchers {
+		if !m.Matches(entry.Labels[m.Name]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrImageNotFound is returned when an image does not exist or has expired.
+var ErrImageNotFound = errors.New("image not found")
+
+// Image is a screenshot of the panel of an alert rule, taken when its alert instances
+// start firing. It is referenced by its token in the ImageTokenAnnotation of alerts.
+type Image struct {
+	ID    int64  `xorm:"pk autoincr 'id'"`
+	Token string `xorm:"token"`
+	// Path is the path of the image on the disk of the Grafana instance that took it.
+	Path string `xorm:"path"`
+	// URL is the public URL of the image in the external image storage, if it was
+	// uploaded.
+	URL       string    `xorm:"url"`
+	CreatedAt time.Time `xorm:"created_at"`
+	ExpiresAt time.Time `xorm:"expires_at"`
+}
+
+// HasURL returns true if the image was uploaded to the external image storage.
+func (i *Image) HasURL() bool {
+	return i.URL != ""
+}
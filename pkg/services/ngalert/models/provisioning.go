@@ -0,0 +1,37 @@
+package models
+
+import "errors"
+
+// ErrProvisionedResource is an error for changes of a resource through the API while it
+// is provisioned from files.
+var ErrProvisionedResource = errors.New("the resource is provisioned from files and cannot be changed through the API")
+
+// Provenance is where a resource is managed from.
+type Provenance string
+
+const (
+	// ProvenanceNone is the provenance of resources managed through the API and the UI.
+	ProvenanceNone Provenance = ""
+	// ProvenanceFile is the provenance of resources provisioned from files.
+	ProvenanceFile Provenance = "file"
+)
+
+// ProvisionedResourceType is the type of resources whose provenance is recorded.
+type ProvisionedResourceType string
+
+const (
+	// ProvisionedAlertRule is the type of alert rules, they are recorded by UID.
+	ProvisionedAlertRule ProvisionedResourceType = "alertRule"
+	// ProvisionedAlertmanagerConfiguration is the type of the Alertmanager configuration
+	// of an organization, it is recorded with an empty key.
+	ProvisionedAlertmanagerConfiguration ProvisionedResourceType = "alertmanagerConfiguration"
+)
+
+// ProvenanceRecord is the provenance of a resource of an organization.
+type ProvenanceRecord struct {
+	ID         int64 `xorm:"pk autoincr 'id'"`
+	OrgID      int64 `xorm:"org_id"`
+	RecordKey  string
+	RecordType ProvisionedResourceType
+	Provenance Provenance
+}
@@ -7,19 +7,26 @@ import (
 
 	"github.com/benbjohnson/clock"
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/imguploader"
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/ngalert/api"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/image"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/recording"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb"
@@ -39,7 +46,8 @@ const (
 
 func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService, routeRegister routing.RouteRegister,
 	sqlStore *sqlstore.SQLStore, kvStore kvstore.KVStore, dataService *tsdb.Service, dataProxy *datasourceproxy.DataSourceProxyService,
-	quotaService *quota.QuotaService, encryptionService encryption.Service, m *metrics.NGAlert) (*AlertNG, error) {
+	quotaService *quota.QuotaService, encryptionService encryption.Service, liveService *live.GrafanaLive, renderService rendering.Service,
+	m *metrics.NGAlert) (*AlertNG, error) {
 	ng := &AlertNG{
 		Cfg:               cfg,
 		DataSourceCache:   dataSourceCache,
@@ -50,6 +58,8 @@ func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService,
 		DataProxy:         dataProxy,
 		QuotaService:      quotaService,
 		EncryptionService: encryptionService,
+		Live:              liveService,
+		RenderService:     renderService,
 		Metrics:           m,
 		Log:               log.New("ngalert"),
 	}
@@ -76,13 +86,21 @@ type AlertNG struct {
 	DataProxy         *datasourceproxy.DataSourceProxyService
 	QuotaService      *quota.QuotaService
 	EncryptionService encryption.Service
+	Live              *live.GrafanaLive
+	RenderService     rendering.Service
 	Metrics           *metrics.NGAlert
 	Log               log.Logger
 	schedule          schedule.ScheduleService
 	stateManager      *state.Manager
+	stateHistoryStore store.StateHistoryStore
+	deliveryStore     store.DeliveryStore
+	imageService      *image.ScreenshotImageService
 
 	// Alerting notification services
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
+
+	// ProvisioningService provisions alerting resources from files
+	ProvisioningService *provisioning.Service
 }
 
 func (ng *AlertNG) init() error {
@@ -118,7 +136,7 @@ func (ng *AlertNG) init() error {
 		BaseInterval:            baseInterval,
 		Logger:                  ng.Log,
 		MaxAttempts:             ng.Cfg.UnifiedAlerting.MaxAttempts,
-		Evaluator:               eval.Evaluator{Cfg: ng.Cfg, Log: ng.Log},
+		Evaluator:               eval.Evaluator{Cfg: ng.Cfg, Log: ng.Log, QueryCache: expr.NewQueryCache(ng.Cfg.UnifiedAlerting.QueryCacheTTL, ng.Cfg.UnifiedAlerting.EvaluationTimeout)},
 		InstanceStore:           store,
 		RuleStore:               store,
 		AdminConfigStore:        store,
@@ -128,6 +146,25 @@ func (ng *AlertNG) init() error {
 		AdminConfigPollInterval: ng.Cfg.UnifiedAlerting.AdminConfigPollInterval,
 		DisabledOrgs:            ng.Cfg.UnifiedAlerting.DisabledOrgs,
 		MinRuleInterval:         ng.getRuleMinInterval(),
+		EvaluationJitter:        ng.Cfg.UnifiedAlerting.EvaluationJitter,
+
+		MaxConcurrentEvaluations:              ng.Cfg.UnifiedAlerting.MaxConcurrentEvaluations,
+		MaxConcurrentEvaluationsPerOrg:        ng.Cfg.UnifiedAlerting.MaxConcurrentEvaluationsPerOrg,
+		MaxConcurrentEvaluationsPerDatasource: ng.Cfg.UnifiedAlerting.MaxConcurrentEvaluationsPerDatasource,
+	}
+
+	var streams recording.StreamGetter
+	if ng.Live != nil && ng.Live.ManagedStreamRunner != nil {
+		streams = ng.Live.ManagedStreamRunner
+	}
+	schedCfg.RecordingWriter = recording.NewTargetWriter(recording.NewRemoteWriteWriter(ng.Cfg.UnifiedAlerting), recording.NewLiveWriter(streams))
+
+	if ng.Cfg.UnifiedAlerting.HAShardRuleEvaluation {
+		if len(ng.Cfg.UnifiedAlerting.HAPeers) == 0 {
+			ng.Log.Warn("Sharding the evaluation of alert rules requires high availability to be enabled with ha_peers. All alert rules are evaluated by this instance.")
+		} else {
+			schedCfg.ClusterMembership = ng.MultiOrgAlertmanager
+		}
 	}
 
 	appUrl, err := url.Parse(ng.Cfg.AppURL)
@@ -135,11 +172,34 @@ func (ng *AlertNG) init() error {
 		ng.Log.Error("Failed to parse application URL. Continue without it.", "error", err)
 		appUrl = nil
 	}
-	stateManager := state.NewManager(ng.Log, ng.Metrics.GetStateMetrics(), appUrl, store, store)
+	if ng.Cfg.UnifiedAlerting.StateHistoryEnabled {
+		ng.stateHistoryStore = store
+	}
+	if ng.Cfg.UnifiedAlerting.DeliveryLogEnabled {
+		ng.deliveryStore = store
+	}
+	var imageService image.ImageService
+	if ng.Cfg.UnifiedAlerting.ScreenshotsCapture {
+		if ng.RenderService == nil || !ng.RenderService.IsAvailable() {
+			ng.Log.Warn("Taking screenshots of the panels of alert rules requires the image renderer. Alert notifications do not have images.")
+		} else {
+			var uploader imguploader.ImageUploader
+			if ng.Cfg.UnifiedAlerting.ScreenshotsUploadExternalImageStorage {
+				uploader, err = imguploader.NewImageUploader()
+				if err != nil {
+					return err
+				}
+			}
+			ng.imageService = image.NewScreenshotImageService(ng.Cfg.UnifiedAlerting, ng.RenderService, uploader, store, log.New("ngalert.image"))
+			imageService = ng.imageService
+		}
+	}
+	stateManager := state.NewManager(ng.Log, ng.Metrics.GetStateMetrics(), appUrl, store, store, ng.stateHistoryStore, imageService)
 	scheduler := schedule.NewScheduler(schedCfg, ng.DataService, appUrl, stateManager)
 
 	ng.stateManager = stateManager
 	ng.schedule = scheduler
+	ng.ProvisioningService = provisioning.NewService(store, store, store, ng.SQLStore, ng.MultiOrgAlertmanager, ng.EncryptionService, log.New("ngalert.provisioning"))
 
 	api := api.API{
 		Cfg:                  ng.Cfg,
@@ -154,8 +214,12 @@ func (ng *AlertNG) init() error {
 		RuleStore:            store,
 		AlertingStore:        store,
 		AdminConfigStore:     store,
+		StateHistoryStore:    ng.stateHistoryStore,
+		DeliveryStore:        ng.deliveryStore,
+		ProvenanceStore:      store,
 		MultiOrgAlertmanager: ng.MultiOrgAlertmanager,
 		StateManager:         ng.stateManager,
+		ProvisioningService:  ng.ProvisioningService,
 	}
 	api.RegisterAPIEndpoints(ng.Metrics.GetAPIMetrics())
 
@@ -180,6 +244,33 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	return children.Wait()
 }
 
+// DeleteExpiredStateHistory deletes the state transitions of alert instances that are older
+// than the configured maximum age, and returns the number of deleted transitions.
+func (ng *AlertNG) DeleteExpiredStateHistory() (int64, error) {
+	if ng.IsDisabled() || ng.stateHistoryStore == nil || ng.Cfg.UnifiedAlerting.StateHistoryMaxAge <= 0 {
+		return 0, nil
+	}
+	return ng.stateHistoryStore.DeleteAlertStateHistory(time.Now().Add(-ng.Cfg.UnifiedAlerting.StateHistoryMaxAge))
+}
+
+// DeleteExpiredNotificationDeliveries deletes the attempts to send notifications that are older
+// than the configured maximum age, and returns the number of deleted attempts.
+func (ng *AlertNG) DeleteExpiredNotificationDeliveries(ctx context.Context) (int64, error) {
+	if ng.IsDisabled() || ng.deliveryStore == nil || ng.Cfg.UnifiedAlerting.DeliveryLogMaxAge <= 0 {
+		return 0, nil
+	}
+	return ng.deliveryStore.DeleteNotificationDeliveries(ctx, time.Now().Add(-ng.Cfg.UnifiedAlerting.DeliveryLogMaxAge))
+}
+
+// DeleteExpiredImages deletes the expired screenshots of the panels of alert rules, and
+// returns the number of deleted screenshots.
+func (ng *AlertNG) DeleteExpiredImages(ctx context.Context) (int64, error) {
+	if ng.IsDisabled() || ng.imageService == nil {
+		return 0, nil
+	}
+	return ng.imageService.DeleteExpiredImages(ctx)
+}
+
 // IsDisabled returns true if the alerting service is disable for this instance.
 func (ng *AlertNG) IsDisabled() bool {
 	if ng.Cfg == nil {
@@ -27,6 +27,7 @@ import (
 	"github.com/prometheus/alertmanager/provider/mem"
 	"github.com/prometheus/alertmanager/silence"
 	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/timeinterval"
 	"github.com/prometheus/alertmanager/types"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
@@ -77,6 +78,14 @@ func init() {
 	}
 }
 
+// AlertingStore is the store of the Alertmanager configurations, and of the images of
+// alerts that are attached to notifications.
+type AlertingStore interface {
+	store.AlertingStore
+	store.ImageStore
+	store.DeliveryStore
+}
+
 type ClusterPeer interface {
 	AddState(string, cluster.State, prometheus.Registerer) cluster.ClusterChannel
 	Position() int
@@ -88,7 +97,7 @@ type Alertmanager struct {
 	gokitLogger gokit_log.Logger
 
 	Settings  *setting.Cfg
-	Store     store.AlertingStore
+	Store     AlertingStore
 	fileStore *FileStore
 	Metrics   *metrics.Alertmanager
 
@@ -119,9 +128,16 @@ type Alertmanager struct {
 	orgID           int64
 
 	decryptFn channels.GetDecryptedValueFn
+
+	// deliveryStore records the attempts to send notifications, it is nil when the
+	// delivery log is disabled.
+	deliveryStore store.DeliveryStore
+
+	// slackThreads persists the messages of alert groups posted by the Slack notifiers.
+	slackThreads *slackThreadStore
 }
 
-func newAlertmanager(orgID int64, cfg *setting.Cfg, store store.AlertingStore, kvStore kvstore.KVStore,
+func newAlertmanager(orgID int64, cfg *setting.Cfg, store AlertingStore, kvStore kvstore.KVStore,
 	peer ClusterPeer, decryptFn channels.GetDecryptedValueFn, m *metrics.Alertmanager) (*Alertmanager, error) {
 	am := &Alertmanager{
 		Settings:          cfg,
@@ -138,8 +154,13 @@ func newAlertmanager(orgID int64, cfg *setting.Cfg, store store.AlertingStore, k
 		decryptFn:         decryptFn,
 	}
 
+	if cfg.UnifiedAlerting.DeliveryLogEnabled {
+		am.deliveryStore = store
+	}
+
 	am.gokitLogger = gokit_log.NewLogfmtLogger(logging.NewWrapper(am.logger))
 	am.fileStore = NewFileStore(am.orgID, kvStore, am.WorkingDirPath())
+	am.slackThreads = newSlackThreadStore(am.orgID, kvStore)
 
 	nflogFilepath, err := am.fileStore.FilepathFor(context.TODO(), notificationLogFilename)
 	if err != nil {
@@ -181,6 +202,12 @@ func newAlertmanager(orgID int64, cfg *setting.Cfg, store store.AlertingStore, k
 		am.wg.Done()
 	}()
 
+	am.wg.Add(1)
+	go func() {
+		am.slackThreads.maintenance(maintenanceNotificationAndSilences, am.stopc)
+		am.wg.Done()
+	}()
+
 	// Initialize in-memory alerts
 	am.alerts, err = mem.NewAlerts(context.Background(), am.marker, memoryAlertsGCInterval, nil, am.gokitLogger)
 	if err != nil {
@@ -408,12 +435,18 @@ func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 	am.inhibitor = inhibit.NewInhibitor(am.alerts, cfg.AlertmanagerConfig.InhibitRules, am.marker, am.gokitLogger)
 	am.silencer = silence.NewSilencer(am.silences, am.marker, am.gokitLogger)
 
+	muteTimes := make(map[string][]timeinterval.TimeInterval, len(cfg.AlertmanagerConfig.MuteTimeIntervals))
+	for _, ti := range cfg.AlertmanagerConfig.MuteTimeIntervals {
+		muteTimes[ti.Name] = ti.TimeIntervals
+	}
+
 	meshStage := notify.NewGossipSettleStage(am.peer)
 	inhibitionStage := notify.NewMuteStage(am.inhibitor)
 	silencingStage := notify.NewMuteStage(am.silencer)
+	timeMuteStage := notify.NewTimeMuteStage(muteTimes)
 	for name := range integrationsMap {
 		stage := am.createReceiverStage(name, integrationsMap[name], am.waitFunc, am.notificationLog)
-		routingStage[name] = notify.MultiStage{meshStage, silencingStage, inhibitionStage, stage}
+		routingStage[name] = notify.MultiStage{meshStage, silencingStage, timeMuteStage, inhibitionStage, stage}
 	}
 
 	am.route = dispatch.NewRoute(cfg.AlertmanagerConfig.Route.AsAMRoute(), nil)
@@ -468,6 +501,9 @@ func (am *Alertmanager) buildReceiverIntegrations(receiver *apimodels.PostableAp
 		if err != nil {
 			return nil, err
 		}
+		if am.deliveryStore != nil {
+			n = newDeliveryLogNotifier(n, am.deliveryStore, am.logger, am.orgID, receiver.Name, r)
+		}
 		integrations = append(integrations, notify.NewIntegration(n, n, r.Type, i))
 	}
 	return integrations, nil
@@ -503,15 +539,15 @@ func (am *Alertmanager) buildReceiverIntegration(r *apimodels.PostableGrafanaRec
 	)
 	switch r.Type {
 	case "email":
-		n, err = channels.NewEmailNotifier(cfg, tmpl) // Email notifier already has a default template.
+		n, err = channels.NewEmailNotifier(cfg, am.Store, tmpl) // Email notifier already has a default template.
 	case "pagerduty":
 		n, err = channels.NewPagerdutyNotifier(cfg, tmpl, am.decryptFn)
 	case "pushover":
-		n, err = channels.NewPushoverNotifier(cfg, tmpl, am.decryptFn)
+		n, err = channels.NewPushoverNotifier(cfg, am.Store, tmpl, am.decryptFn)
 	case "slack":
-		n, err = channels.NewSlackNotifier(cfg, tmpl, am.decryptFn)
+		n, err = channels.NewSlackNotifier(cfg, tmpl, am.decryptFn, am.slackThreads)
 	case "telegram":
-		n, err = channels.NewTelegramNotifier(cfg, tmpl, am.decryptFn)
+		n, err = channels.NewTelegramNotifier(cfg, am.Store, tmpl, am.decryptFn)
 	case "victorops":
 		n, err = channels.NewVictoropsNotifier(cfg, tmpl)
 	case "teams":
@@ -522,6 +558,8 @@ func (am *Alertmanager) buildReceiverIntegration(r *apimodels.PostableGrafanaRec
 		n, err = channels.NewKafkaNotifier(cfg, tmpl)
 	case "webhook":
 		n, err = channels.NewWebHookNotifier(cfg, tmpl, am.decryptFn)
+	case "http":
+		n, err = channels.NewHTTPNotifier(cfg, tmpl, am.decryptFn)
 	case "sensugo":
 		n, err = channels.NewSensuGoNotifier(cfg, tmpl, am.decryptFn)
 	case "discord":
@@ -703,6 +741,9 @@ func (am *Alertmanager) createReceiverStage(name string, integrations []notify.I
 		var s notify.MultiStage
 		s = append(s, notify.NewWaitStage(wait))
 		s = append(s, notify.NewDedupStage(&integrations[i], notificationLog, recv))
+		if am.deliveryStore != nil {
+			s = append(s, deliveryAttemptStage{})
+		}
 		s = append(s, notify.NewRetryStage(integrations[i], name, am.stageMetrics))
 		s = append(s, notify.NewSetNotifiesStage(notificationLog, recv))
 
@@ -2,6 +2,7 @@ package notifier
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io/ioutil"
 	"os"
@@ -11,7 +12,9 @@ import (
 
 	gokit_log "github.com/go-kit/kit/log"
 	"github.com/go-openapi/strfmt"
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
+	models2 "github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/logging"
@@ -344,3 +347,61 @@ func TestPutAlert(t *testing.T) {
 		})
 	}
 }
+
+func TestMuteTimeIntervals(t *testing.T) {
+	am := setupAMTest(t)
+
+	notified := make(chan string, 10)
+	bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models2.SendWebhookSync) error {
+		notified <- webhook.Url
+		return nil
+	})
+
+	// the mute time interval covers every day of the week, so the muted route never notifies
+	var cfg apimodels.PostableUserConfig
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"alertmanager_config": {
+			"route": {
+				"receiver": "notified",
+				"group_by": ["team"],
+				"group_wait": "0s",
+				"routes": [{
+					"receiver": "muted",
+					"object_matchers": [["team", "=", "muted"]],
+					"mute_time_intervals": ["always"]
+				}]
+			},
+			"mute_time_intervals": [{
+				"name": "always",
+				"time_intervals": [{"weekdays": ["sunday:saturday"]}]
+			}],
+			"receivers": [{
+				"name": "notified",
+				"grafana_managed_receiver_configs": [{"uid": "notified", "name": "notified", "type": "webhook", "settings": {"url": "http://notified"}}]
+			}, {
+				"name": "muted",
+				"grafana_managed_receiver_configs": [{"uid": "muted", "name": "muted", "type": "webhook", "settings": {"url": "http://muted"}}]
+			}]
+		}
+	}`), &cfg))
+	require.NoError(t, am.SaveAndApplyConfig(&cfg))
+
+	alert := func(team string) models.PostableAlert {
+		return models.PostableAlert{
+			Alert: models.Alert{Labels: models.LabelSet{"alertname": "test", "team": team}},
+		}
+	}
+	require.NoError(t, am.PutAlerts(apimodels.PostableAlerts{PostableAlerts: []models.PostableAlert{alert("muted"), alert("notified")}}))
+
+	select {
+	case url := <-notified:
+		require.Equal(t, "http://notified", url)
+	case <-time.After(10 * time.Second):
+		t.Fatal("the route that is not muted did not notify")
+	}
+	select {
+	case url := <-notified:
+		t.Fatalf("unexpected notification to %s", url)
+	case <-time.After(time.Second):
+	}
+}
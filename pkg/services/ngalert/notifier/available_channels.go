@@ -637,6 +637,124 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 				},
 			},
 		},
+		{
+			Type:        "http",
+			Name:        "HTTP",
+			Description: "Sends a templated HTTP request to a URL",
+			Heading:     "HTTP settings",
+			Info:        "The URL, HTTP method, headers and body are templates, which have the same data and functions as the templates of notifications.",
+			Options: []alerting.NotifierOption{
+				{
+					Label:        "URL",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "https://example.com/api/tickets",
+					PropertyName: "url",
+					Required:     true,
+				},
+				{
+					Label:        "HTTP Method",
+					Description:  "POST, PUT or PATCH.",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "POST",
+					PropertyName: "httpMethod",
+				},
+				{
+					Label:       "Content Type",
+					Description: "The body of JSON payloads must be valid JSON. The body of form payloads has a key=value field on each line, which are URL encoded.",
+					Element:     alerting.ElementTypeSelect,
+					SelectOptions: []alerting.SelectOption{
+						{
+							Value: channels.HTTPContentTypeJSON,
+							Label: "JSON",
+						},
+						{
+							Value: channels.HTTPContentTypeForm,
+							Label: "Form",
+						},
+						{
+							Value: channels.HTTPContentTypeText,
+							Label: "Plain text",
+						},
+					},
+					PropertyName: "contentType",
+				},
+				{
+					Label:        "Headers",
+					Description:  "A header on each line, such as X-Priority: {{ .CommonLabels.severity }}",
+					Element:      alerting.ElementTypeTextArea,
+					PropertyName: "headers",
+				},
+				{
+					Label:        "Body",
+					Description:  "Defaults to the alerts as JSON for JSON payloads, and to the default message for plain text payloads.",
+					Element:      alerting.ElementTypeTextArea,
+					PropertyName: "body",
+				},
+				{
+					Label:        "Username",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					PropertyName: "username",
+				},
+				{
+					Label:        "Password",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypePassword,
+					PropertyName: "password",
+					Secure:       true,
+				},
+				{
+					Label:        "HMAC Secret",
+					Description:  "Signs the body of the requests with HMAC-SHA256. The signature is sent as sha256=<hex signature>.",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypePassword,
+					PropertyName: "hmacSecret",
+					Secure:       true,
+				},
+				{
+					Label:        "HMAC Header",
+					Description:  "The header of the signature.",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  channels.DefaultHTTPSignatureHeader,
+					PropertyName: "hmacHeader",
+				},
+				{
+					Label:        "TLS CA Certificate",
+					Description:  "PEM encoded certificate of the CA that signed the certificate of the server.",
+					Element:      alerting.ElementTypeTextArea,
+					PropertyName: "tlsCACert",
+				},
+				{
+					Label:        "TLS Client Certificate",
+					Description:  "PEM encoded client certificate.",
+					Element:      alerting.ElementTypeTextArea,
+					PropertyName: "tlsClientCert",
+				},
+				{
+					Label:        "TLS Client Key",
+					Description:  "PEM encoded key of the client certificate.",
+					Element:      alerting.ElementTypeTextArea,
+					PropertyName: "tlsClientKey",
+					Secure:       true,
+				},
+				{
+					Label:        "Skip TLS Verify",
+					Description:  "Do not verify the certificate of the server.",
+					Element:      alerting.ElementTypeCheckbox,
+					PropertyName: "tlsSkipVerify",
+				},
+				{
+					Label:        "Max Alerts",
+					Description:  "Max alerts to include in a notification. Remaining alerts in the same batch will be ignored above this number. 0 means no limit.",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					PropertyName: "maxAlerts",
+				},
+			},
+		},
 		{
 			Type:        "prometheus-alertmanager",
 			Name:        "Alertmanager",
@@ -3,7 +3,9 @@ package channels
 import (
 	"context"
 	"net/url"
+	"os"
 	"path"
+	"path/filepath"
 
 	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
@@ -11,6 +13,7 @@ import (
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -22,12 +25,13 @@ type EmailNotifier struct {
 	SingleEmail bool
 	Message     string
 	log         log.Logger
+	images      ImageStore
 	tmpl        *template.Template
 }
 
 // NewEmailNotifier is the constructor function
 // for the EmailNotifier.
-func NewEmailNotifier(model *NotificationChannelConfig, t *template.Template) (*EmailNotifier, error) {
+func NewEmailNotifier(model *NotificationChannelConfig, images ImageStore, t *template.Template) (*EmailNotifier, error) {
 	if model.Settings == nil {
 		return nil, receiverInitError{Reason: "no settings supplied", Cfg: *model}
 	}
@@ -54,6 +58,7 @@ func NewEmailNotifier(model *NotificationChannelConfig, t *template.Template) (*
 		SingleEmail: singleEmail,
 		Message:     model.Settings.Get("message").MustString(),
 		log:         log.New("alerting.notifier.email"),
+		images:      images,
 		tmpl:        t,
 	}, nil
 }
@@ -78,6 +83,21 @@ func (en *EmailNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 		en.log.Debug("failed to parse external URL", "url", en.tmpl.ExternalURL.String(), "err", err.Error())
 	}
 
+	// embed the images that were not uploaded to the external image storage
+	var embeddedFiles []string
+	_ = forEachImage(ctx, en.log, en.images, func(index int, image *ngmodels.Image) error {
+		if image.HasURL() || image.Path == "" {
+			return nil
+		}
+		if _, err := os.Stat(image.Path); err != nil {
+			en.log.Debug("image file of alert not found", "path", image.Path, "err", err)
+			return nil
+		}
+		data.Alerts[index].EmbeddedImage = filepath.Base(image.Path)
+		embeddedFiles = append(embeddedFiles, image.Path)
+		return nil
+	}, as...)
+
 	cmd := &models.SendEmailCommandSync{
 		SendEmailCommand: models.SendEmailCommand{
 			Subject: title,
@@ -93,9 +113,10 @@ func (en *EmailNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 				"RuleUrl":           ruleURL,
 				"AlertPageUrl":      alertPageURL,
 			},
-			To:          en.Addresses,
-			SingleEmail: en.SingleEmail,
-			Template:    "ng_alert_notification",
+			To:            en.Addresses,
+			SingleEmail:   en.SingleEmail,
+			Template:      "ng_alert_notification",
+			EmbeddedFiles: embeddedFiles,
 		},
 	}
 
@@ -3,6 +3,8 @@ package channels
 import (
 	"context"
 	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/prometheus/alertmanager/template"
@@ -13,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
 func TestEmailNotifier(t *testing.T) {
@@ -32,7 +35,7 @@ func TestEmailNotifier(t *testing.T) {
 			Settings: settingsJSON,
 		}
 
-		_, err := NewEmailNotifier(model, tmpl)
+		_, err := NewEmailNotifier(model, &UnavailableImageStore{}, tmpl)
 		require.Error(t, err)
 	})
 
@@ -48,7 +51,7 @@ func TestEmailNotifier(t *testing.T) {
 			Name:     "ops",
 			Type:     "email",
 			Settings: settingsJSON,
-		}, tmpl)
+		}, &UnavailableImageStore{}, tmpl)
 
 		require.NoError(t, err)
 
@@ -105,4 +108,54 @@ func TestEmailNotifier(t *testing.T) {
 			},
 		}, expected)
 	})
+
+	t.Run("images are embedded unless they were uploaded", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "image.png")
+		require.NoError(t, os.WriteFile(path, []byte("png"), 0600))
+		images := &fakeImageStore{images: map[string]*ngmodels.Image{
+			"embedded": {Token: "embedded", Path: path},
+			"uploaded": {Token: "uploaded", Path: path, URL: "http://images/uploaded.png"},
+		}}
+
+		settingsJSON, err := simplejson.NewJson([]byte(`{"addresses": "someops@example.com"}`))
+		require.NoError(t, err)
+		emailNotifier, err := NewEmailNotifier(&NotificationChannelConfig{
+			Name:     "ops",
+			Type:     "email",
+			Settings: settingsJSON,
+		}, images, tmpl)
+		require.NoError(t, err)
+
+		var sent *models.SendEmailCommandSync
+		bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SendEmailCommandSync) error {
+			sent = cmd
+			return nil
+		})
+
+		alerts := []*types.Alert{
+			{
+				Alert: model.Alert{
+					Labels:      model.LabelSet{"alertname": "alert1"},
+					Annotations: model.LabelSet{ngmodels.ImageTokenAnnotation: "embedded"},
+				},
+			},
+			{
+				Alert: model.Alert{
+					Labels:      model.LabelSet{"alertname": "alert2"},
+					Annotations: model.LabelSet{ngmodels.ImageTokenAnnotation: "uploaded", ngmodels.ImageURLAnnotation: "http://images/uploaded.png"},
+				},
+			},
+		}
+
+		ok, err := emailNotifier.Notify(context.Background(), alerts...)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Equal(t, []string{path}, sent.EmbeddedFiles)
+		extended := sent.Data["Alerts"].(ExtendedAlerts)
+		require.Equal(t, "image.png", extended[0].EmbeddedImage)
+		require.Empty(t, extended[0].ImageURL)
+		require.Empty(t, extended[1].EmbeddedImage)
+		require.Equal(t, "http://images/uploaded.png", extended[1].ImageURL)
+	})
 }
@@ -63,6 +63,14 @@ func (gcn *GoogleChatNotifier) Notify(ctx context.Context, as ...*types.Alert) (
 		})
 	}
 
+	if imageURL := firstImageURL(as...); imageURL != "" {
+		widgets = append(widgets, imageWidget{
+			Image: imageData{
+				ImageURL: imageURL,
+			},
+		})
+	}
+
 	ruleURL := joinUrlPath(gcn.tmpl.ExternalURL.String(), "/alerting/list", gcn.log)
 	// Add a button widget (link to Grafana).
 	widgets = append(widgets, buttonWidget{
@@ -168,6 +176,14 @@ type textParagraphWidget struct {
 	Text text `json:"textParagraph"`
 }
 
+type imageWidget struct {
+	Image imageData `json:"image"`
+}
+
+type imageData struct {
+	ImageURL string `json:"imageUrl"`
+}
+
 type text struct {
 	Text string `json:"text"`
 }
@@ -0,0 +1,319 @@
+package channels
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	tmpltext "text/template"
+	"time"
+
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	HTTPContentTypeJSON = "json"
+	HTTPContentTypeForm = "form"
+	HTTPContentTypeText = "text"
+
+	// DefaultHTTPSignatureHeader is the header of the HMAC signature of the body of the
+	// requests, when they are signed.
+	DefaultHTTPSignatureHeader = "X-Grafana-Signature"
+)
+
+var httpContentTypes = map[string]string{
+	HTTPContentTypeJSON: "application/json",
+	HTTPContentTypeForm: "application/x-www-form-urlencoded",
+	HTTPContentTypeText: "text/plain",
+}
+
+var httpMethods = map[string]bool{
+	http.MethodPost:  true,
+	http.MethodPut:   true,
+	http.MethodPatch: true,
+}
+
+// HTTPNotifier is responsible for sending alert notifications as HTTP requests
+// whose URL, method, headers and body are templates.
+type HTTPNotifier struct {
+	*Base
+	URL         string
+	HTTPMethod  string
+	Headers     string
+	Body        string
+	ContentType string
+	User        string
+	Password    string
+	HMACSecret  string
+	HMACHeader  string
+	MaxAlerts   int
+	client      *http.Client
+	log         log.Logger
+	tmpl        *template.Template
+}
+
+// NewHTTPNotifier is the constructor for the HTTP notifier.
+func NewHTTPNotifier(model *NotificationChannelConfig, t *template.Template, fn GetDecryptedValueFn) (*HTTPNotifier, error) {
+	if model.Settings == nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find settings property"}
+	}
+	u := model.Settings.Get("url").MustString()
+	if u == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find url property in settings"}
+	}
+	contentType := model.Settings.Get("contentType").MustString(HTTPContentTypeJSON)
+	if _, ok := httpContentTypes[contentType]; !ok {
+		return nil, receiverInitError{Cfg: *model, Reason: fmt.Sprintf("invalid content type %q, must be one of json, form or text", contentType)}
+	}
+	body := model.Settings.Get("body").MustString()
+	if body == "" && contentType == HTTPContentTypeForm {
+		return nil, receiverInitError{Cfg: *model, Reason: "the body is required for form payloads"}
+	}
+
+	n := &HTTPNotifier{
+		Base: NewBase(&models.AlertNotification{
+			Uid:                   model.UID,
+			Name:                  model.Name,
+			Type:                  model.Type,
+			DisableResolveMessage: model.DisableResolveMessage,
+			Settings:              model.Settings,
+		}),
+		URL:         u,
+		HTTPMethod:  model.Settings.Get("httpMethod").MustString(http.MethodPost),
+		Headers:     model.Settings.Get("headers").MustString(),
+		Body:        body,
+		ContentType: contentType,
+		User:        model.Settings.Get("username").MustString(),
+		Password:    fn(context.Background(), model.SecureSettings, "password", model.Settings.Get("password").MustString(), setting.SecretKey),
+		HMACSecret:  fn(context.Background(), model.SecureSettings, "hmacSecret", model.Settings.Get("hmacSecret").MustString(), setting.SecretKey),
+		HMACHeader:  model.Settings.Get("hmacHeader").MustString(DefaultHTTPSignatureHeader),
+		MaxAlerts:   model.Settings.Get("maxAlerts").MustInt(0),
+		log:         log.New("alerting.notifier.http"),
+		tmpl:        t,
+	}
+
+	templates := []struct{ name, text string }{{"url", n.URL}, {"httpMethod", n.HTTPMethod}, {"headers", n.Headers}, {"body", n.Body}}
+	for _, t := range templates {
+		if _, err := tmpltext.New(t.name).Funcs(tmpltext.FuncMap(template.DefaultFuncs)).Parse(t.text); err != nil {
+			return nil, receiverInitError{Cfg: *model, Reason: fmt.Sprintf("invalid %s template", t.name), Err: err}
+		}
+	}
+
+	tlsConfig, err := newHTTPTLSConfig(
+		model.Settings.Get("tlsCACert").MustString(),
+		model.Settings.Get("tlsClientCert").MustString(),
+		fn(context.Background(), model.SecureSettings, "tlsClientKey", model.Settings.Get("tlsClientKey").MustString(), setting.SecretKey),
+		model.Settings.Get("tlsSkipVerify").MustBool(false),
+	)
+	if err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid TLS configuration", Err: err}
+	}
+	n.client = &http.Client{
+		Timeout: time.Second * 30,
+		Transport: &http.Transport{
+			TLSClientConfig: tlsConfig,
+			Proxy:           http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout: 30 * time.Second,
+			}).DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+		},
+	}
+
+	return n, nil
+}
+
+// newHTTPTLSConfig returns the TLS configuration of the requests, with the PEM encoded
+// CA certificate used to verify the server, and the client certificate and key.
+func newHTTPTLSConfig(caCert, clientCert, clientKey string, skipVerify bool) (*tls.Config, error) {
+	cfg := &tls.Config{
+		Renegotiation:      tls.RenegotiateFreelyAsClient,
+		InsecureSkipVerify: skipVerify, // #nosec G402 -- opt-in for servers with self-signed certificates
+	}
+	if caCert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(caCert)) {
+			return nil, errors.New("failed to parse the CA certificate")
+		}
+		cfg.RootCAs = pool
+	}
+	if clientCert != "" || clientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(clientCert), []byte(clientKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// Notify implements the Notifier interface.
+func (hn *HTTPNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	as, _ = truncateAlerts(hn.MaxAlerts, as)
+	var tmplErr error
+	tmpl, data := TmplText(ctx, hn.tmpl, as, hn.log, &tmplErr)
+
+	u := tmpl(hn.URL)
+	method := strings.ToUpper(strings.TrimSpace(tmpl(hn.HTTPMethod)))
+	headers := tmpl(hn.Headers)
+	body := tmpl(hn.Body)
+	if body == "" && tmplErr == nil {
+		switch hn.ContentType {
+		case HTTPContentTypeJSON:
+			b, err := json.Marshal(data)
+			if err != nil {
+				return false, err
+			}
+			body = string(b)
+		case HTTPContentTypeText:
+			body = tmpl(`{{ template "default.message" . }}`)
+		}
+	}
+	if tmplErr != nil {
+		return false, fmt.Errorf("failed to template HTTP request: %w", tmplErr)
+	}
+
+	request, err := hn.newRequest(ctx, u, method, headers, body)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := hn.client.Do(request)
+	if err != nil {
+		return true, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			hn.log.Warn("Failed to close response body", "err", err)
+		}
+	}()
+	notifications.SetResponseStatusCode(ctx, resp.StatusCode)
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return true, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode/100 != 2 {
+		hn.log.Warn("HTTP request failed", "url", request.URL.String(), "statusCode", resp.Status, "body", string(respBody))
+		// only server errors and rate limits are retried, other requests would fail again
+		retry := resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests
+		return retry, fmt.Errorf("HTTP request failed with status code %d", resp.StatusCode)
+	}
+
+	hn.log.Debug("Sending HTTP request succeeded", "url", request.URL.String(), "statusCode", resp.Status)
+	return true, nil
+}
+
+// newRequest returns the request with the templated URL, method, headers and body.
+func (hn *HTTPNotifier) newRequest(ctx context.Context, rawURL, method, headers, body string) (*http.Request, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, fmt.Errorf("invalid templated URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid templated URL %q: the scheme must be http or https", u.Redacted())
+	}
+
+	if method == "" {
+		method = http.MethodPost
+	}
+	if !httpMethods[method] {
+		return nil, fmt.Errorf("invalid templated HTTP method %q: must be one of POST, PUT or PATCH", method)
+	}
+
+	switch hn.ContentType {
+	case HTTPContentTypeJSON:
+		if !json.Valid([]byte(body)) {
+			return nil, errors.New("the templated body is not valid JSON")
+		}
+	case HTTPContentTypeForm:
+		values, err := parseHTTPFormBody(body)
+		if err != nil {
+			return nil, err
+		}
+		body = values.Encode()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, u.String(), strings.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	request.Header.Set("Content-Type", httpContentTypes[hn.ContentType])
+	request.Header.Set("User-Agent", "Grafana")
+	if hn.User != "" && hn.Password != "" {
+		request.SetBasicAuth(hn.User, hn.Password)
+	}
+
+	for _, line := range strings.Split(headers, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, value, ok := cutString(line, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid templated header %q: must be Name: value", line)
+		}
+		request.Header.Set(name, strings.TrimSpace(value))
+	}
+
+	if hn.HMACSecret != "" {
+		request.Header.Set(hn.HMACHeader, "sha256="+signHTTPBody(hn.HMACSecret, body))
+	}
+
+	return request, nil
+}
+
+// parseHTTPFormBody returns the form values of a templated body with a key=value pair on
+// each line. Empty lines are ignored.
+func parseHTTPFormBody(body string) (url.Values, error) {
+	values := url.Values{}
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		key, value, ok := cutString(line, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid templated form field %q: must be key=value", line)
+		}
+		values.Add(key, value)
+	}
+	return values, nil
+}
+
+// signHTTPBody returns the hex encoded HMAC-SHA256 signature of the body.
+func signHTTPBody(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// cutString slices s around the first instance of sep.
+func cutString(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+func (hn *HTTPNotifier) SendResolved() bool {
+	return !hn.GetDisableResolveMessage()
+}
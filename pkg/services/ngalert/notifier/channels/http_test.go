@@ -0,0 +1,384 @@
+package channels
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
+	"github.com/grafana/grafana/pkg/services/notifications"
+)
+
+type httpTestRequest struct {
+	method  string
+	path    string
+	headers http.Header
+	body    string
+}
+
+func TestHTTPNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	var received *httpTestRequest
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		received = &httpTestRequest{method: r.Method, path: r.URL.RequestURI(), headers: r.Header, body: string(b)}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	alerts := []*types.Alert{
+		{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1", "severity": "critical"},
+				Annotations: model.LabelSet{"summary": "CPU is high"},
+			},
+		},
+	}
+
+	cases := []struct {
+		name          string
+		settings      string
+		secure        map[string]string
+		status        int
+		expRequest    *httpTestRequest
+		expJSONBody   string
+		expInitError  string
+		expMsgError   string
+		expRetry      bool
+		expStatusCode int
+	}{
+		{
+			name:     "Default config sends the alerts as JSON",
+			settings: fmt.Sprintf(`{"url": "%s/alerts"}`, server.URL),
+			expRequest: &httpTestRequest{
+				method:  http.MethodPost,
+				path:    "/alerts",
+				headers: http.Header{"Content-Type": {"application/json"}, "User-Agent": {"Grafana"}},
+			},
+			expJSONBody: `{
+				"receiver": "my_receiver",
+				"status": "firing",
+				"alerts": [{
+					"status": "firing",
+					"labels": {"alertname": "alert1", "severity": "critical"},
+					"annotations": {"summary": "CPU is high"},
+					"startsAt": "0001-01-01T00:00:00Z",
+					"endsAt": "0001-01-01T00:00:00Z",
+					"generatorURL": "",
+					"fingerprint": "0223b772b51c29e1",
+					"silenceURL": "http://localhost/alerting/silence/new?alertmanager=grafana&matchers=alertname%3Dalert1%2Cseverity%3Dcritical",
+					"dashboardURL": "",
+					"panelURL": "",
+					"valueString": ""
+				}],
+				"groupLabels": {"alertname": ""},
+				"commonLabels": {"alertname": "alert1", "severity": "critical"},
+				"commonAnnotations": {"summary": "CPU is high"},
+				"externalURL": "http://localhost"
+			}`,
+			expRetry:      true,
+			expStatusCode: http.StatusOK,
+		},
+		{
+			name: "Templated URL, method, headers and JSON body",
+			settings: fmt.Sprintf(`{
+				"url": "%s/tickets/{{ .CommonLabels.severity }}",
+				"httpMethod": "{{ if eq .Status \"firing\" }}put{{ else }}patch{{ end }}",
+				"headers": "X-Priority: {{ .CommonLabels.severity | toUpper }}\n\nX-Count: {{ len .Alerts }}",
+				"body": "{\"title\": \"{{ .CommonLabels.alertname }}\", \"summary\": {{ printf \"%%q\" .CommonAnnotations.summary }}}"
+			}`, server.URL),
+			expRequest: &httpTestRequest{
+				method:  http.MethodPut,
+				path:    "/tickets/critical",
+				headers: http.Header{"Content-Type": {"application/json"}, "User-Agent": {"Grafana"}, "X-Priority": {"CRITICAL"}, "X-Count": {"1"}},
+			},
+			expJSONBody:   `{"title": "alert1", "summary": "CPU is high"}`,
+			expRetry:      true,
+			expStatusCode: http.StatusOK,
+		},
+		{
+			name: "Form payload",
+			settings: fmt.Sprintf(`{
+				"url": "%s/form",
+				"contentType": "form",
+				"body": "title={{ .CommonLabels.alertname }}\nsummary={{ .CommonAnnotations.summary }}\n"
+			}`, server.URL),
+			expRequest: &httpTestRequest{
+				method:  http.MethodPost,
+				path:    "/form",
+				headers: http.Header{"Content-Type": {"application/x-www-form-urlencoded"}, "User-Agent": {"Grafana"}},
+				body:    "summary=CPU+is+high&title=alert1",
+			},
+			expRetry:      true,
+			expStatusCode: http.StatusOK,
+		},
+		{
+			name: "Plain text payload with basic auth and a signature",
+			settings: fmt.Sprintf(`{
+				"url": "%s/text",
+				"contentType": "text",
+				"body": "{{ .CommonLabels.alertname }} is {{ .Status }}",
+				"username": "user",
+				"hmacHeader": "X-Signature"
+			}`, server.URL),
+			secure: map[string]string{"password": "pass", "hmacSecret": "secret"},
+			expRequest: &httpTestRequest{
+				method: http.MethodPost,
+				path:   "/text",
+				headers: http.Header{
+					"Content-Type":  {"text/plain"},
+					"User-Agent":    {"Grafana"},
+					"Authorization": {"Basic dXNlcjpwYXNz"},
+					"X-Signature":   {"sha256=" + signHTTPBody("secret", "alert1 is firing")},
+				},
+				body: "alert1 is firing",
+			},
+			expRetry:      true,
+			expStatusCode: http.StatusOK,
+		},
+		{
+			name:          "Server errors are retried",
+			settings:      fmt.Sprintf(`{"url": "%s/alerts"}`, server.URL),
+			status:        http.StatusServiceUnavailable,
+			expMsgError:   "HTTP request failed with status code 503",
+			expRetry:      true,
+			expStatusCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:          "Client errors are not retried",
+			settings:      fmt.Sprintf(`{"url": "%s/alerts"}`, server.URL),
+			status:        http.StatusBadRequest,
+			expMsgError:   "HTTP request failed with status code 400",
+			expStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:        "Templated body that is not valid JSON",
+			settings:    fmt.Sprintf(`{"url": "%s/alerts", "body": "{\"summary\": \"{{ .Status }}"}`, server.URL),
+			expMsgError: "the templated body is not valid JSON",
+		},
+		{
+			name:        "Templated method that is not supported",
+			settings:    fmt.Sprintf(`{"url": "%s/alerts", "httpMethod": "{{ if true }}delete{{ end }}"}`, server.URL),
+			expMsgError: `invalid templated HTTP method "DELETE": must be one of POST, PUT or PATCH`,
+		},
+		{
+			name:        "Templated URL that is not HTTP",
+			settings:    `{"url": "{{ .Status }}://localhost"}`,
+			expMsgError: `invalid templated URL "firing://localhost": the scheme must be http or https`,
+		},
+		{
+			name:        "Template that fails to execute",
+			settings:    fmt.Sprintf(`{"url": "%s/alerts", "body": "{{ template \"unknown\" . }}"}`, server.URL),
+			expMsgError: `failed to template HTTP request: template: :1:12: executing "" at <{{template "unknown" .}}>: template "unknown" not defined`,
+		},
+		{
+			name:         "Error in initing, no url",
+			settings:     `{}`,
+			expInitError: `failed to validate receiver "http_testing" of type "http": could not find url property in settings`,
+		},
+		{
+			name:         "Error in initing, invalid content type",
+			settings:     `{"url": "http://localhost", "contentType": "xml"}`,
+			expInitError: `failed to validate receiver "http_testing" of type "http": invalid content type "xml", must be one of json, form or text`,
+		},
+		{
+			name:         "Error in initing, form payload without body",
+			settings:     `{"url": "http://localhost", "contentType": "form"}`,
+			expInitError: `failed to validate receiver "http_testing" of type "http": the body is required for form payloads`,
+		},
+		{
+			name:         "Error in initing, invalid template",
+			settings:     `{"url": "http://localhost", "headers": "X-Status: {{ .Status "}`,
+			expInitError: `failed to validate receiver "http_testing" of type "http": invalid headers template: template: headers:1: unclosed action`,
+		},
+		{
+			name:         "Error in initing, invalid CA certificate",
+			settings:     `{"url": "http://localhost", "tlsCACert": "invalid"}`,
+			expInitError: `failed to validate receiver "http_testing" of type "http": invalid TLS configuration: failed to parse the CA certificate`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			received = nil
+			status = http.StatusOK
+			if c.status != 0 {
+				status = c.status
+			}
+
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+			secureSettings := make(map[string][]byte)
+			encryptionService := ossencryption.ProvideService()
+			for k, v := range c.secure {
+				secureSettings[k], err = encryptionService.Encrypt(context.Background(), []byte(v), "")
+				require.NoError(t, err)
+			}
+
+			m := &NotificationChannelConfig{
+				Name:           "http_testing",
+				Type:           "http",
+				Settings:       settingsJSON,
+				SecureSettings: secureSettings,
+			}
+
+			hn, err := NewHTTPNotifier(m, tmpl, encryptionService.GetDecryptedValue)
+			if c.expInitError != "" {
+				require.EqualError(t, err, c.expInitError)
+				return
+			}
+			require.NoError(t, err)
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ctx = notify.WithReceiverName(ctx, "my_receiver")
+			ctx, statusCode := notifications.WithResponseStatusCode(ctx)
+			retry, err := hn.Notify(ctx, alerts...)
+			require.Equal(t, c.expRetry, retry)
+			require.Equal(t, c.expStatusCode, statusCode())
+			if c.expMsgError != "" {
+				require.EqualError(t, err, c.expMsgError)
+				if c.expStatusCode == 0 {
+					require.Nil(t, received)
+				}
+				return
+			}
+			require.NoError(t, err)
+
+			require.NotNil(t, received)
+			require.Equal(t, c.expRequest.method, received.method)
+			require.Equal(t, c.expRequest.path, received.path)
+			for name := range c.expRequest.headers {
+				require.Equal(t, c.expRequest.headers.Get(name), received.headers.Get(name), name)
+			}
+			if c.expJSONBody != "" {
+				require.JSONEq(t, c.expJSONBody, received.body)
+			} else {
+				require.Equal(t, c.expRequest.body, received.body)
+			}
+		})
+	}
+}
+
+func TestHTTPNotifierClientCertificate(t *testing.T) {
+	tmpl := templateForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+	clientCert, clientKey := newTestCertificate(t)
+
+	clientCAs := x509.NewCertPool()
+	require.True(t, clientCAs.AppendCertsFromPEM([]byte(clientCert)))
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+	serverCA := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	newNotifier := func(t *testing.T, settings map[string]interface{}, secure map[string]string) *HTTPNotifier {
+		settings["url"] = server.URL
+		encryptionService := ossencryption.ProvideService()
+		secureSettings := make(map[string][]byte)
+		for k, v := range secure {
+			var err error
+			secureSettings[k], err = encryptionService.Encrypt(context.Background(), []byte(v), "")
+			require.NoError(t, err)
+		}
+		hn, err := NewHTTPNotifier(&NotificationChannelConfig{
+			Name:           "http_testing",
+			Type:           "http",
+			Settings:       simplejson.NewFromAny(settings),
+			SecureSettings: secureSettings,
+		}, tmpl, encryptionService.GetDecryptedValue)
+		require.NoError(t, err)
+		return hn
+	}
+
+	t.Run("requests are sent with the client certificate", func(t *testing.T) {
+		hn := newNotifier(t, map[string]interface{}{"tlsCACert": serverCA, "tlsClientCert": clientCert}, map[string]string{"tlsClientKey": clientKey})
+		_, err := hn.Notify(notify.WithGroupKey(context.Background(), "alertname"), &types.Alert{})
+		require.NoError(t, err)
+	})
+
+	t.Run("requests without the client certificate are rejected", func(t *testing.T) {
+		hn := newNotifier(t, map[string]interface{}{"tlsCACert": serverCA}, nil)
+		_, err := hn.Notify(notify.WithGroupKey(context.Background(), "alertname"), &types.Alert{})
+		require.Error(t, err)
+	})
+
+	t.Run("the certificate of the server is verified", func(t *testing.T) {
+		hn := newNotifier(t, map[string]interface{}{"tlsClientCert": clientCert}, map[string]string{"tlsClientKey": clientKey})
+		_, err := hn.Notify(notify.WithGroupKey(context.Background(), "alertname"), &types.Alert{})
+		require.Error(t, err)
+
+		hn = newNotifier(t, map[string]interface{}{"tlsClientCert": clientCert, "tlsSkipVerify": true}, map[string]string{"tlsClientKey": clientKey})
+		_, err = hn.Notify(notify.WithGroupKey(context.Background(), "alertname"), &types.Alert{})
+		require.NoError(t, err)
+	})
+}
+
+// newTestCertificate returns a PEM encoded self-signed client certificate and its key.
+func newTestCertificate(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "grafana"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+func TestParseHTTPFormBody(t *testing.T) {
+	values, err := parseHTTPFormBody("a=1\n\n b = 2 \nc=x=y")
+	require.NoError(t, err)
+	require.Equal(t, url.Values{"a": {"1"}, "b ": {" 2"}, "c": {"x=y"}}, values)
+
+	_, err = parseHTTPFormBody("a")
+	require.EqualError(t, err, `invalid templated form field "a": must be key=value`)
+}
+
+func TestSignHTTPBody(t *testing.T) {
+	// echo -n '{"status":"firing"}' | openssl dgst -sha256 -hmac secret
+	require.Equal(t, "99da718745e068940da5a11a17187cb45656f78cd34132618eebc3ab7f7860e1", signHTTPBody("secret", `{"status":"firing"}`))
+	require.NotEqual(t, signHTTPBody("secret", "a"), signHTTPBody("other", "a"))
+}
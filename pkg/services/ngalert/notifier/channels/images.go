@@ -0,0 +1,69 @@
+package channels
+
+import (
+	"context"
+	"errors"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ImageStore is the interface to get the images of alerts, for the notifiers that attach
+// the image files to their notifications.
+type ImageStore interface {
+	GetImage(ctx context.Context, token string) (*ngmodels.Image, error)
+}
+
+// UnavailableImageStore is an ImageStore without images.
+type UnavailableImageStore struct{}
+
+// GetImage returns ngmodels.ErrImageNotFound.
+func (UnavailableImageStore) GetImage(_ context.Context, _ string) (*ngmodels.Image, error) {
+	return nil, ngmodels.ErrImageNotFound
+}
+
+// forEachImage calls fn with the index and the stored image of each alert that has an
+// image. Images that cannot be found, for example because they expired, are skipped.
+func forEachImage(ctx context.Context, l log.Logger, imageStore ImageStore, fn func(index int, image *ngmodels.Image) error, as ...*types.Alert) error {
+	for i, a := range as {
+		token := string(a.Annotations[ngmodels.ImageTokenAnnotation])
+		if token == "" {
+			continue
+		}
+		image, err := imageStore.GetImage(ctx, token)
+		if err != nil {
+			if !errors.Is(err, ngmodels.ErrImageNotFound) {
+				l.Warn("failed to get image of alert", "token", token, "err", err)
+			} else {
+				l.Debug("image of alert not found", "token", token)
+			}
+			continue
+		}
+		if err := fn(i, image); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// firstImageURL returns the public URL of the image of the first firing alert that has one,
+// or of the first resolved alert if no firing alert has one.
+func firstImageURL(as ...*types.Alert) string {
+	var resolved string
+	for _, a := range as {
+		u := string(a.Annotations[ngmodels.ImageURLAnnotation])
+		if u == "" {
+			continue
+		}
+		if a.Status() == model.AlertFiring {
+			return u
+		}
+		if resolved == "" {
+			resolved = u
+		}
+	}
+	return resolved
+}
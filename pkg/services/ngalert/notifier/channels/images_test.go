@@ -0,0 +1,79 @@
+package channels
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestForEachImage(t *testing.T) {
+	images := &fakeImageStore{images: map[string]*ngmodels.Image{
+		"a": {Token: "a", Path: "/tmp/a.png"},
+		"b": {Token: "b", URL: "http://images/b.png"},
+	}}
+	alerts := []*types.Alert{
+		{Alert: model.Alert{Annotations: model.LabelSet{ngmodels.ImageTokenAnnotation: "a"}}},
+		{Alert: model.Alert{Annotations: model.LabelSet{"ann": "no image"}}},
+		{Alert: model.Alert{Annotations: model.LabelSet{ngmodels.ImageTokenAnnotation: "expired"}}},
+		{Alert: model.Alert{Annotations: model.LabelSet{ngmodels.ImageTokenAnnotation: "b"}}},
+	}
+
+	found := map[int]string{}
+	err := forEachImage(context.Background(), log.New("test"), images, func(index int, image *ngmodels.Image) error {
+		found[index] = image.Token
+		return nil
+	}, alerts...)
+	require.NoError(t, err)
+	require.Equal(t, map[int]string{0: "a", 3: "b"}, found)
+}
+
+func TestFirstImageURL(t *testing.T) {
+	now := time.Now()
+	firing := func(url string) *types.Alert {
+		return &types.Alert{Alert: model.Alert{
+			Annotations: model.LabelSet{ngmodels.ImageURLAnnotation: model.LabelValue(url)},
+			EndsAt:      now.Add(time.Hour),
+		}}
+	}
+	resolved := func(url string) *types.Alert {
+		return &types.Alert{Alert: model.Alert{
+			Annotations: model.LabelSet{ngmodels.ImageURLAnnotation: model.LabelValue(url)},
+			EndsAt:      now.Add(-time.Hour),
+		}}
+	}
+
+	cases := []struct {
+		desc     string
+		alerts   []*types.Alert
+		expected string
+	}{
+		{
+			desc:     "no alert has an image",
+			alerts:   []*types.Alert{firing(""), resolved("")},
+			expected: "",
+		},
+		{
+			desc:     "firing alerts are preferred",
+			alerts:   []*types.Alert{resolved("http://images/resolved.png"), firing(""), firing("http://images/firing.png")},
+			expected: "http://images/firing.png",
+		},
+		{
+			desc:     "resolved alerts are used when no firing alert has an image",
+			alerts:   []*types.Alert{firing(""), resolved("http://images/resolved.png")},
+			expected: "http://images/resolved.png",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.Equal(t, tc.expected, firstImageURL(tc.alerts...))
+		})
+	}
+}
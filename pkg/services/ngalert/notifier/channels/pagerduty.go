@@ -154,6 +154,10 @@ func (pn *PagerdutyNotifier) buildPagerdutyMessage(ctx context.Context, alerts m
 		},
 	}
 
+	if imageURL := firstImageURL(as...); imageURL != "" {
+		msg.Images = []pagerDutyImage{{Src: imageURL}}
+	}
+
 	if len(msg.Payload.Summary) > 1024 {
 		// This is the Pagerduty limit.
 		msg.Payload.Summary = msg.Payload.Summary[:1021] + "..."
@@ -185,6 +189,11 @@ type pagerDutyMessage struct {
 	Client      string           `json:"client,omitempty"`
 	ClientURL   string           `json:"client_url,omitempty"`
 	Links       []pagerDutyLink  `json:"links,omitempty"`
+	Images      []pagerDutyImage `json:"images,omitempty"`
+}
+
+type pagerDutyImage struct {
+	Src string `json:"src"`
 }
 
 type pagerDutyLink struct {
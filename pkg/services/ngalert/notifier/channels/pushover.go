@@ -5,11 +5,13 @@ import (
 	"context"
 	"fmt"
 	"mime/multipart"
+	"os"
 	"strconv"
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
@@ -35,12 +37,13 @@ type PushoverNotifier struct {
 	OKSound          string
 	Upload           bool
 	Message          string
+	images           ImageStore
 	tmpl             *template.Template
 	log              log.Logger
 }
 
 // NewSlackNotifier is the constructor for the Slack notifier
-func NewPushoverNotifier(model *NotificationChannelConfig, t *template.Template, fn GetDecryptedValueFn) (*PushoverNotifier, error) {
+func NewPushoverNotifier(model *NotificationChannelConfig, images ImageStore, t *template.Template, fn GetDecryptedValueFn) (*PushoverNotifier, error) {
 	if model.Settings == nil {
 		return nil, receiverInitError{Cfg: *model, Reason: "no settings supplied"}
 	}
@@ -88,6 +91,7 @@ func NewPushoverNotifier(model *NotificationChannelConfig, t *template.Template,
 		OKSound:          okSound,
 		Upload:           uploadImage,
 		Message:          model.Settings.Get("message").MustString(`{{ template "default.message" .}}`),
+		images:           images,
 		tmpl:             t,
 		log:              log.New("alerting.notifier.pushover"),
 	}, nil
@@ -224,6 +228,25 @@ func (pn *PushoverNotifier) genPushoverBody(ctx context.Context, as ...*types.Al
 	if err != nil {
 		return nil, b, err
 	}
+
+	// Pushover supports a single attachment, the image of the first alert that has one
+	if pn.Upload {
+		attached := false
+		err = forEachImage(ctx, pn.log, pn.images, func(_ int, image *ngmodels.Image) error {
+			if attached || image.Path == "" {
+				return nil
+			}
+			if _, err := os.Stat(image.Path); err != nil {
+				pn.log.Debug("image file of alert not found", "path", image.Path, "err", err)
+				return nil
+			}
+			attached = true
+			return writeFile(w, "attachment", image.Path)
+		}, as...)
+		if err != nil {
+			return nil, b, err
+		}
+	}
 	if err := w.Close(); err != nil {
 		return nil, b, err
 	}
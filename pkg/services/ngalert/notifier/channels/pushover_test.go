@@ -144,7 +144,7 @@ func TestPushoverNotifier(t *testing.T) {
 			}
 
 			decryptFn := ossencryption.ProvideService().GetDecryptedValue
-			pn, err := NewPushoverNotifier(m, tmpl, decryptFn)
+			pn, err := NewPushoverNotifier(m, &UnavailableImageStore{}, tmpl, decryptFn)
 			if c.expInitError != "" {
 				require.Error(t, err)
 				require.Equal(t, c.expInitError, err.Error())
@@ -3,21 +3,27 @@ package channels
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/url"
+	"path"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
 )
@@ -40,14 +46,40 @@ type SlackNotifier struct {
 	MentionGroups  []string
 	MentionChannel string
 	Token          string
+
+	// threads is used to update the message of an alert group and to post the
+	// following notifications of the group in its thread, when a bot token is used.
+	threads   SlackThreadStore
+	updateURL string
+}
+
+// SlackThread is the message the Slack notifier posted for an alert group.
+type SlackThread struct {
+	Channel   string    `json:"channel"`
+	Ts        string    `json:"ts"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SlackThreadStore persists the messages the Slack notifier posted for alert groups, so
+// that they are updated after restarts and by the other instances of Grafana in HA.
+type SlackThreadStore interface {
+	// GetSlackThread returns nil if there is no message for the key.
+	GetSlackThread(ctx context.Context, key string) (*SlackThread, error)
+	SaveSlackThread(ctx context.Context, key string, thread SlackThread) error
 }
 
+// SlackThreadRetention is the time after the last notification of an alert group when
+// its message is no longer updated, and a new message is posted instead. It is the same
+// as the retention of the notification log.
+const SlackThreadRetention = 5 * 24 * time.Hour
+
 var reRecipient *regexp.Regexp = regexp.MustCompile("^((@[a-z0-9][a-zA-Z0-9._-]*)|(#[^ .A-Z]{1,79})|([a-zA-Z0-9]+))$")
 
 var SlackAPIEndpoint = "https://slack.com/api/chat.postMessage"
 
-// NewSlackNotifier is the constructor for the Slack notifier
-func NewSlackNotifier(model *NotificationChannelConfig, t *template.Template, fn GetDecryptedValueFn) (*SlackNotifier, error) {
+// NewSlackNotifier is the constructor for the Slack notifier. The messages of alert groups
+// are not updated if threads is nil.
+func NewSlackNotifier(model *NotificationChannelConfig, t *template.Template, fn GetDecryptedValueFn, threads SlackThreadStore) (*SlackNotifier, error) {
 	if model.Settings == nil {
 		return nil, receiverInitError{Cfg: *model, Reason: "no settings supplied"}
 	}
@@ -125,9 +157,22 @@ func NewSlackNotifier(model *NotificationChannelConfig, t *template.Template, fn
 		Title:          model.Settings.Get("title").MustString(`{{ template "default.title" . }}`),
 		log:            log.New("alerting.notifier.slack"),
 		tmpl:           t,
+		threads:        threads,
+		updateURL:      slackUpdateURL(apiURL),
 	}, nil
 }
 
+// slackUpdateURL returns the URL of the chat.update method of the Slack API, or an empty
+// string if the URL is not the chat.postMessage method, such as incoming webhooks.
+func slackUpdateURL(u *url.URL) string {
+	if path.Base(u.Path) != "chat.postMessage" {
+		return ""
+	}
+	updateURL := *u
+	updateURL.Path = path.Join(path.Dir(u.Path), "chat.update")
+	return updateURL.String()
+}
+
 // slackMessage is the slackMessage for sending a slack notification.
 type slackMessage struct {
 	Channel     string                   `json:"channel,omitempty"`
@@ -136,6 +181,26 @@ type slackMessage struct {
 	IconURL     string                   `json:"icon_url,omitempty"`
 	Attachments []attachment             `json:"attachments"`
 	Blocks      []map[string]interface{} `json:"blocks"`
+	// Ts is the message to update with chat.update, and ThreadTs the message to reply to.
+	Ts       string `json:"ts,omitempty"`
+	ThreadTs string `json:"thread_ts,omitempty"`
+}
+
+// slackResponse is the response of the Slack API to the chat methods.
+type slackResponse struct {
+	Ok      bool   `json:"ok"`
+	Err     string `json:"error"`
+	Channel string `json:"channel"`
+	Ts      string `json:"ts"`
+}
+
+// slackAPIError is returned when the Slack API responds with an error.
+type slackAPIError struct {
+	Err string
+}
+
+func (e slackAPIError) Error() string {
+	return fmt.Sprintf("failed to make Slack API request: %s", e.Err)
 }
 
 // attachment is used to display a richly-formatted message block.
@@ -149,6 +214,7 @@ type attachment struct {
 	FooterIcon string              `json:"footer_icon"`
 	Color      string              `json:"color,omitempty"`
 	Ts         int64               `json:"ts,omitempty"`
+	ImageURL   string              `json:"image_url,omitempty"`
 }
 
 // Notify sends an alert notification to Slack.
@@ -158,15 +224,103 @@ func (sn *SlackNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 		return false, fmt.Errorf("build slack message: %w", err)
 	}
 
+	if key, ok := sn.threadKey(ctx); ok {
+		return sn.notifyThread(ctx, key, msg)
+	}
+
+	if _, err := sn.sendMessage(ctx, sn.URL.String(), msg); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// threadKey returns the key of the message of the alert group, if the messages of alert
+// groups are updated.
+func (sn *SlackNotifier) threadKey(ctx context.Context) (string, bool) {
+	if sn.threads == nil || sn.Token == "" || sn.updateURL == "" {
+		return "", false
+	}
+	groupKey, err := notify.ExtractGroupKey(ctx)
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256([]byte(sn.UID + "/" + groupKey.Hash()))
+	return hex.EncodeToString(sum[:]), true
+}
+
+// notifyThread updates the message of the alert group and replies in its thread, or
+// posts a new message for the alert group if there is none.
+func (sn *SlackNotifier) notifyThread(ctx context.Context, key string, msg *slackMessage) (bool, error) {
+	thread, err := sn.threads.GetSlackThread(ctx, key)
+	if err != nil {
+		sn.log.Warn("Failed to get the Slack message of the alert group", "err", err)
+	}
+
+	if thread != nil && time.Since(thread.UpdatedAt) < SlackThreadRetention {
+		updated, err := sn.updateThread(ctx, thread, msg)
+		if err != nil {
+			return false, err
+		}
+		if updated {
+			thread.UpdatedAt = time.Now()
+			sn.saveThread(ctx, key, *thread)
+			return true, nil
+		}
+		sn.log.Debug("The Slack message of the alert group was deleted, posting a new message", "channel", thread.Channel, "ts", thread.Ts)
+	}
+
+	resp, err := sn.sendMessage(ctx, sn.URL.String(), msg)
+	if err != nil {
+		return false, err
+	}
+	if resp.Channel != "" && resp.Ts != "" {
+		sn.saveThread(ctx, key, SlackThread{Channel: resp.Channel, Ts: resp.Ts, UpdatedAt: time.Now()})
+	}
+	return true, nil
+}
+
+// updateThread updates the message of the alert group and posts the notification as a
+// reply in its thread. It returns false if the message no longer exists.
+func (sn *SlackNotifier) updateThread(ctx context.Context, thread *SlackThread, msg *slackMessage) (bool, error) {
+	update := *msg
+	update.Channel = thread.Channel
+	update.Ts = thread.Ts
+	if _, err := sn.sendMessage(ctx, sn.updateURL, &update); err != nil {
+		var apiErr slackAPIError
+		if errors.As(err, &apiErr) && (apiErr.Err == "message_not_found" || apiErr.Err == "channel_not_found") {
+			return false, nil
+		}
+		return false, err
+	}
+
+	reply := *msg
+	reply.Channel = thread.Channel
+	reply.ThreadTs = thread.Ts
+	if _, err := sn.sendMessage(ctx, sn.URL.String(), &reply); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// saveThread saves the message of the alert group. The notification was sent even if
+// it fails, so the error is only logged.
+func (sn *SlackNotifier) saveThread(ctx context.Context, key string, thread SlackThread) {
+	if err := sn.threads.SaveSlackThread(ctx, key, thread); err != nil {
+		sn.log.Warn("Failed to save the Slack message of the alert group", "channel", thread.Channel, "ts", thread.Ts, "err", err)
+	}
+}
+
+// sendMessage sends the message to the method of the Slack API at the URL.
+func (sn *SlackNotifier) sendMessage(ctx context.Context, u string, msg *slackMessage) (*slackResponse, error) {
 	b, err := json.Marshal(msg)
 	if err != nil {
-		return false, fmt.Errorf("marshal json: %w", err)
+		return nil, fmt.Errorf("marshal json: %w", err)
 	}
 
-	sn.log.Debug("Sending Slack API request", "url", sn.URL.String(), "data", string(b))
-	request, err := http.NewRequestWithContext(ctx, http.MethodPost, sn.URL.String(), bytes.NewReader(b))
+	sn.log.Debug("Sending Slack API request", "url", u, "data", string(b))
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(b))
 	if err != nil {
-		return false, fmt.Errorf("failed to create HTTP request: %w", err)
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
 	request.Header.Set("Content-Type", "application/json")
@@ -180,15 +334,12 @@ func (sn *SlackNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 		request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", sn.Token))
 	}
 
-	if err := sendSlackRequest(request, sn.log); err != nil {
-		return false, err
-	}
-	return true, nil
+	return sendSlackRequest(request, sn.log)
 }
 
 // sendSlackRequest sends a request to the Slack API.
 // Stubbable by tests.
-var sendSlackRequest = func(request *http.Request, logger log.Logger) error {
+var sendSlackRequest = func(request *http.Request, logger log.Logger) (*slackResponse, error) {
 	netTransport := &http.Transport{
 		TLSClientConfig: &tls.Config{
 			Renegotiation: tls.RenegotiateFreelyAsClient,
@@ -205,7 +356,7 @@ var sendSlackRequest = func(request *http.Request, logger log.Logger) error {
 	}
 	resp, err := netClient.Do(request)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
@@ -213,35 +364,34 @@ var sendSlackRequest = func(request *http.Request, logger log.Logger) error {
 		}
 	}()
 
+	notifications.SetResponseStatusCode(request.Context(), resp.StatusCode)
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		logger.Warn("Slack API request failed", "url", request.URL.String(), "statusCode", resp.Status, "body", string(body))
-		return fmt.Errorf("request to Slack API failed with status code %d", resp.StatusCode)
+		return nil, fmt.Errorf("request to Slack API failed with status code %d", resp.StatusCode)
 	}
 
 	// Slack responds to some requests with a JSON document, that might contain an error
-	rslt := struct {
-		Ok  bool   `json:"ok"`
-		Err string `json:"error"`
-	}{}
-	if err := json.Unmarshal(body, &rslt); err != nil {
+	rslt := &slackResponse{}
+	if err := json.Unmarshal(body, rslt); err != nil {
 		logger.Warn("Failed to unmarshal Slack API response", "url", request.URL.String(), "statusCode", resp.Status,
 			"body", string(body))
-		return fmt.Errorf("failed to unmarshal Slack API response: %s", err)
+		return nil, fmt.Errorf("failed to unmarshal Slack API response: %s", err)
 	}
 
 	if !rslt.Ok && rslt.Err != "" {
 		logger.Warn("Sending Slack API request failed", "url", request.URL.String(), "statusCode", resp.Status,
 			"err", rslt.Err)
-		return fmt.Errorf("failed to make Slack API request: %s", rslt.Err)
+		return nil, slackAPIError{Err: rslt.Err}
 	}
 
 	logger.Debug("Sending Slack API request succeeded", "url", request.URL.String(), "statusCode", resp.Status)
-	return nil
+	return rslt, nil
 }
 
 func (sn *SlackNotifier) buildSlackMessage(ctx context.Context, as []*types.Alert) (*slackMessage, error) {
@@ -267,6 +417,7 @@ func (sn *SlackNotifier) buildSlackMessage(ctx context.Context, as []*types.Aler
 				TitleLink:  ruleURL,
 				Text:       tmpl(sn.Text),
 				Fields:     nil, // TODO. Should be a config.
+				ImageURL:   firstImageURL(as...),
 			},
 		},
 	}
@@ -3,11 +3,14 @@ package channels
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
 
@@ -70,6 +73,40 @@ func TestSlackNotifier(t *testing.T) {
 			},
 			expMsgError: nil,
 		},
+		{
+			name: "Correct config with an alert with an image",
+			settings: `{
+				"token": "1234",
+				"recipient": "#testchannel"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1", "__alertImageToken__": "abc", "__alertImageUrl__": "http://images/abc.png"},
+					},
+				},
+			},
+			expMsg: &slackMessage{
+				Channel:  "#testchannel",
+				Username: "Grafana",
+				Attachments: []attachment{
+					{
+						Title:      "[FIRING:1]  (val1)",
+						TitleLink:  "http://localhost/alerting/list",
+						Text:       "**Firing**\n\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSilence: http://localhost/alerting/silence/new?alertmanager=grafana&matchers=alertname%3Dalert1%2Clbl1%3Dval1\n",
+						Fallback:   "[FIRING:1]  (val1)",
+						Fields:     nil,
+						Footer:     "Grafana v",
+						FooterIcon: "https://grafana.com/assets/img/fav32.png",
+						Color:      "#D63232",
+						Ts:         0,
+						ImageURL:   "http://images/abc.png",
+					},
+				},
+			},
+			expMsgError: nil,
+		},
 		{
 			name: "Correct config with webhook",
 			settings: `{
@@ -173,7 +210,7 @@ func TestSlackNotifier(t *testing.T) {
 			}
 
 			decryptFn := ossencryption.ProvideService().GetDecryptedValue
-			pn, err := NewSlackNotifier(m, tmpl, decryptFn)
+			pn, err := NewSlackNotifier(m, tmpl, decryptFn, nil)
 			if c.expInitError != "" {
 				require.Error(t, err)
 				require.Equal(t, c.expInitError, err.Error())
@@ -186,7 +223,7 @@ func TestSlackNotifier(t *testing.T) {
 			t.Cleanup(func() {
 				sendSlackRequest = origSendSlackRequest
 			})
-			sendSlackRequest = func(request *http.Request, log log.Logger) error {
+			sendSlackRequest = func(request *http.Request, log log.Logger) (*slackResponse, error) {
 				t.Helper()
 				defer func() {
 					_ = request.Body.Close()
@@ -195,7 +232,7 @@ func TestSlackNotifier(t *testing.T) {
 				b, err := io.ReadAll(request.Body)
 				require.NoError(t, err)
 				body = string(b)
-				return nil
+				return &slackResponse{Ok: true}, nil
 			}
 
 			ctx := notify.WithGroupKey(context.Background(), "alertname")
@@ -304,7 +341,7 @@ func TestSendSlackRequest(t *testing.T) {
 			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
 			require.NoError(tt, err)
 
-			err = sendSlackRequest(req, log.New("test"))
+			_, err = sendSlackRequest(req, log.New("test"))
 			if !test.expectError {
 				require.NoError(tt, err)
 			} else {
@@ -313,3 +350,181 @@ func TestSendSlackRequest(t *testing.T) {
 		})
 	}
 }
+
+type fakeSlackThreadStore struct {
+	mtx     sync.Mutex
+	threads map[string]SlackThread
+}
+
+func (f *fakeSlackThreadStore) GetSlackThread(_ context.Context, key string) (*SlackThread, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	thread, ok := f.threads[key]
+	if !ok {
+		return nil, nil
+	}
+	return &thread, nil
+}
+
+func (f *fakeSlackThreadStore) SaveSlackThread(_ context.Context, key string, thread SlackThread) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.threads[key] = thread
+	return nil
+}
+
+// slackAPIRequest is a request received by the stand-in for the Slack API.
+type slackAPIRequest struct {
+	method string
+	msg    slackMessage
+}
+
+func TestSlackNotifierThreads(t *testing.T) {
+	tmpl := templateForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	var (
+		requests []slackAPIRequest
+		nextTs   int
+		// missing are the messages that were deleted in Slack
+		missing = map[string]bool{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer 1234", r.Header.Get("Authorization"))
+		var msg slackMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+		method := r.URL.Path[len("/api/"):]
+		requests = append(requests, slackAPIRequest{method: method, msg: msg})
+
+		switch {
+		case method == "chat.update" && missing[msg.Ts]:
+			_, _ = w.Write([]byte(`{"ok": false, "error": "message_not_found"}`))
+		case method == "chat.update":
+			_, _ = w.Write([]byte(fmt.Sprintf(`{"ok": true, "channel": "C1", "ts": %q}`, msg.Ts)))
+		default:
+			nextTs++
+			_, _ = w.Write([]byte(fmt.Sprintf(`{"ok": true, "channel": "C1", "ts": "%d.000100"}`, nextTs)))
+		}
+	}))
+	defer server.Close()
+
+	firing := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}, StartsAt: time.Now()}}
+	resolved := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}, StartsAt: time.Now().Add(-time.Hour), EndsAt: time.Now().Add(-time.Minute)}}
+
+	newNotifier := func(t *testing.T, url string, threads SlackThreadStore) *SlackNotifier {
+		settings, err := simplejson.NewJson([]byte(fmt.Sprintf(`{"url": %q, "token": "1234", "recipient": "#alerts"}`, url)))
+		require.NoError(t, err)
+		sn, err := NewSlackNotifier(&NotificationChannelConfig{
+			UID:      "slack-uid",
+			Name:     "slack_testing",
+			Type:     "slack",
+			Settings: settings,
+		}, tmpl, ossencryption.ProvideService().GetDecryptedValue, threads)
+		require.NoError(t, err)
+		return sn
+	}
+	send := func(t *testing.T, sn *SlackNotifier, groupKey string, as ...*types.Alert) {
+		ctx := notify.WithGroupKey(context.Background(), groupKey)
+		ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": "alert1"})
+		ok, err := sn.Notify(ctx, as...)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	t.Run("the message of the alert group is updated and notifications are posted in its thread", func(t *testing.T) {
+		requests = nil
+		threads := &fakeSlackThreadStore{threads: map[string]SlackThread{}}
+		sn := newNotifier(t, server.URL+"/api/chat.postMessage", threads)
+
+		send(t, sn, "group", firing)
+		require.Len(t, requests, 1)
+		require.Equal(t, "chat.postMessage", requests[0].method)
+		require.Equal(t, "#alerts", requests[0].msg.Channel)
+		require.Empty(t, requests[0].msg.ThreadTs)
+		require.Len(t, threads.threads, 1)
+		var ts string
+		for _, thread := range threads.threads {
+			require.Equal(t, "C1", thread.Channel)
+			ts = thread.Ts
+		}
+
+		send(t, sn, "group", resolved)
+		require.Len(t, requests, 3)
+		require.Equal(t, "chat.update", requests[1].method)
+		require.Equal(t, "C1", requests[1].msg.Channel)
+		require.Equal(t, ts, requests[1].msg.Ts)
+		require.Equal(t, "[RESOLVED] alert1 ", requests[1].msg.Attachments[0].Title)
+		require.Equal(t, "chat.postMessage", requests[2].method)
+		require.Equal(t, "C1", requests[2].msg.Channel)
+		require.Equal(t, ts, requests[2].msg.ThreadTs)
+		require.Equal(t, "[RESOLVED] alert1 ", requests[2].msg.Attachments[0].Title)
+
+		// the alert groups have their own messages
+		send(t, sn, "other group", firing)
+		require.Len(t, requests, 4)
+		require.Equal(t, "chat.postMessage", requests[3].method)
+		require.Empty(t, requests[3].msg.ThreadTs)
+		require.Len(t, threads.threads, 2)
+	})
+
+	t.Run("a new message is posted if the message of the alert group was deleted", func(t *testing.T) {
+		requests = nil
+		threads := &fakeSlackThreadStore{threads: map[string]SlackThread{}}
+		sn := newNotifier(t, server.URL+"/api/chat.postMessage", threads)
+
+		send(t, sn, "group", firing)
+		for _, thread := range threads.threads {
+			missing[thread.Ts] = true
+		}
+		send(t, sn, "group", resolved)
+		require.Len(t, requests, 3)
+		require.Equal(t, "chat.update", requests[1].method)
+		require.Equal(t, "chat.postMessage", requests[2].method)
+		require.Empty(t, requests[2].msg.ThreadTs)
+		for _, thread := range threads.threads {
+			require.False(t, missing[thread.Ts])
+		}
+	})
+
+	t.Run("a new message is posted if the message of the alert group expired", func(t *testing.T) {
+		requests = nil
+		threads := &fakeSlackThreadStore{threads: map[string]SlackThread{}}
+		sn := newNotifier(t, server.URL+"/api/chat.postMessage", threads)
+
+		send(t, sn, "group", firing)
+		for key, thread := range threads.threads {
+			thread.UpdatedAt = time.Now().Add(-SlackThreadRetention)
+			threads.threads[key] = thread
+		}
+		send(t, sn, "group", resolved)
+		require.Len(t, requests, 2)
+		require.Equal(t, "chat.postMessage", requests[1].method)
+		require.Empty(t, requests[1].msg.ThreadTs)
+	})
+
+	t.Run("messages are not updated without the chat API", func(t *testing.T) {
+		requests = nil
+		threads := &fakeSlackThreadStore{threads: map[string]SlackThread{}}
+		sn := newNotifier(t, server.URL+"/api/webhook", threads)
+
+		send(t, sn, "group", firing)
+		send(t, sn, "group", resolved)
+		require.Len(t, requests, 2)
+		require.Empty(t, requests[1].msg.ThreadTs)
+		require.Empty(t, threads.threads)
+	})
+}
+
+func TestSlackUpdateURL(t *testing.T) {
+	for u, exp := range map[string]string{
+		SlackAPIEndpoint: "https://slack.com/api/chat.update",
+		"http://localhost:3000/api/chat.postMessage": "http://localhost:3000/api/chat.update",
+		"https://hooks.slack.com/services/T0/B0/X":   "",
+	} {
+		parsed, err := url.Parse(u)
+		require.NoError(t, err)
+		require.Equal(t, exp, slackUpdateURL(parsed), u)
+	}
+}
@@ -57,6 +57,17 @@ func (tn *TeamsNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 	ruleURL := joinUrlPath(tn.tmpl.ExternalURL.String(), "/alerting/list", tn.log)
 
 	title := tmpl(`{{ template "default.title" . }}`)
+	section := map[string]interface{}{
+		"title": "Details",
+		"text":  tmpl(tn.Message),
+	}
+	if imageURL := firstImageURL(as...); imageURL != "" {
+		section["images"] = []map[string]interface{}{
+			{
+				"image": imageURL,
+			},
+		}
+	}
 	body := map[string]interface{}{
 		"@type":    "MessageCard",
 		"@context": "http://schema.org/extensions",
@@ -65,12 +76,7 @@ func (tn *TeamsNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 		"summary":    title,
 		"title":      title,
 		"themeColor": getAlertStatusColor(types.Alerts(as...).Status()),
-		"sections": []map[string]interface{}{
-			{
-				"title": "Details",
-				"text":  tmpl(tn.Message),
-			},
-		},
+		"sections":   []map[string]interface{}{section},
 		"potentialAction": []map[string]interface{}{
 			{
 				"@context": "http://schema.org",
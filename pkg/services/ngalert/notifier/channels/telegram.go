@@ -4,18 +4,23 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"mime/multipart"
+	"os"
+	"path/filepath"
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
 )
 
 var (
-	TelegramAPIURL = "https://api.telegram.org/bot%s/sendMessage"
+	TelegramAPIURL      = "https://api.telegram.org/bot%s/sendMessage"
+	TelegramPhotoAPIURL = "https://api.telegram.org/bot%s/sendPhoto"
 )
 
 // TelegramNotifier is responsible for sending
@@ -26,11 +31,12 @@ type TelegramNotifier struct {
 	ChatID   string
 	Message  string
 	log      log.Logger
+	images   ImageStore
 	tmpl     *template.Template
 }
 
 // NewTelegramNotifier is the constructor for the Telegram notifier
-func NewTelegramNotifier(model *NotificationChannelConfig, t *template.Template, fn GetDecryptedValueFn) (*TelegramNotifier, error) {
+func NewTelegramNotifier(model *NotificationChannelConfig, images ImageStore, t *template.Template, fn GetDecryptedValueFn) (*TelegramNotifier, error) {
 	if model.Settings == nil {
 		return nil, receiverInitError{Cfg: *model, Reason: "no settings supplied"}
 	}
@@ -58,6 +64,7 @@ func NewTelegramNotifier(model *NotificationChannelConfig, t *template.Template,
 		BotToken: botToken,
 		ChatID:   chatID,
 		Message:  message,
+		images:   images,
 		tmpl:     t,
 		log:      log.New("alerting.notifier.telegram"),
 	}, nil
@@ -70,6 +77,36 @@ func (tn *TelegramNotifier) Notify(ctx context.Context, as ...*types.Alert) (boo
 		return false, err
 	}
 
+	tn.log.Info("sending telegram notification", "chat_id", msg["chat_id"])
+	if err := tn.send(ctx, fmt.Sprintf(TelegramAPIURL, tn.BotToken), msg, ""); err != nil {
+		tn.log.Error("Failed to send webhook", "error", err, "webhook", tn.Name)
+		return false, err
+	}
+
+	// the images of the alerts are sent as photos after the message, and the notification
+	// is not retried if they fail as the message was sent
+	_ = forEachImage(ctx, tn.log, tn.images, func(_ int, image *ngmodels.Image) error {
+		photo := map[string]string{"chat_id": msg["chat_id"]}
+		var path string
+		if image.HasURL() {
+			photo["photo"] = image.URL
+		} else if image.Path != "" {
+			path = image.Path
+		} else {
+			return nil
+		}
+		if err := tn.send(ctx, fmt.Sprintf(TelegramPhotoAPIURL, tn.BotToken), photo, path); err != nil {
+			tn.log.Warn("Failed to send image of alert", "error", err, "webhook", tn.Name)
+		}
+		return nil
+	}, as...)
+
+	return true, nil
+}
+
+// send sends a multipart request with the fields, and the file at path as the photo if
+// path is not empty.
+func (tn *TelegramNotifier) send(ctx context.Context, url string, fields map[string]string, path string) error {
 	var body bytes.Buffer
 	w := multipart.NewWriter(&body)
 	defer func() {
@@ -79,40 +116,37 @@ func (tn *TelegramNotifier) Notify(ctx context.Context, as ...*types.Alert) (boo
 	}()
 	boundary := GetBoundary()
 	if boundary != "" {
-		err = w.SetBoundary(boundary)
-		if err != nil {
-			return false, err
+		if err := w.SetBoundary(boundary); err != nil {
+			return err
 		}
 	}
 
-	for k, v := range msg {
+	for k, v := range fields {
 		if err := writeField(w, k, v); err != nil {
-			return false, err
+			return err
+		}
+	}
+	if path != "" {
+		if err := writeFile(w, "photo", path); err != nil {
+			return err
 		}
 	}
 
 	// We need to close it before using so that the last part
 	// is added to the writer along with the boundary.
 	if err := w.Close(); err != nil {
-		return false, err
+		return err
 	}
 
-	tn.log.Info("sending telegram notification", "chat_id", msg["chat_id"])
 	cmd := &models.SendWebhookSync{
-		Url:        fmt.Sprintf(TelegramAPIURL, tn.BotToken),
+		Url:        url,
 		Body:       body.String(),
 		HttpMethod: "POST",
 		HttpHeader: map[string]string{
 			"Content-Type": w.FormDataContentType(),
 		},
 	}
-
-	if err := bus.DispatchCtx(ctx, cmd); err != nil {
-		tn.log.Error("Failed to send webhook", "error", err, "webhook", tn.Name)
-		return false, err
-	}
-
-	return true, nil
+	return bus.DispatchCtx(ctx, cmd)
 }
 
 func (tn *TelegramNotifier) buildTelegramMessage(ctx context.Context, as []*types.Alert) (map[string]string, error) {
@@ -144,6 +178,22 @@ func writeField(w *multipart.Writer, name, value string) error {
 	return nil
 }
 
+func writeFile(w *multipart.Writer, name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	fw, err := w.CreateFormFile(name, filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fw, f)
+	return err
+}
+
 func (tn *TelegramNotifier) SendResolved() bool {
 	return !tn.GetDisableResolveMessage()
 }
@@ -97,7 +97,7 @@ func TestTelegramNotifier(t *testing.T) {
 			}
 
 			decryptFn := ossencryption.ProvideService().GetDecryptedValue
-			pn, err := NewTelegramNotifier(m, tmpl, decryptFn)
+			pn, err := NewTelegramNotifier(m, &UnavailableImageStore{}, tmpl, decryptFn)
 			if c.expInitError != "" {
 				require.Error(t, err)
 				require.Equal(t, c.expInitError, err.Error())
@@ -31,6 +31,10 @@ type ExtendedAlert struct {
 	DashboardURL string      `json:"dashboardURL"`
 	PanelURL     string      `json:"panelURL"`
 	ValueString  string      `json:"valueString"`
+	// ImageURL is the public URL of the screenshot of the panel of the alert rule, and
+	// EmbeddedImage the name of the screenshot embedded in the notification, if any.
+	ImageURL      string `json:"imageURL,omitempty"`
+	EmbeddedImage string `json:"embeddedImage,omitempty"`
 }
 
 type ExtendedAlerts []ExtendedAlert
@@ -67,6 +71,9 @@ func extendAlert(alert template.Alert, externalURL string, logger log.Logger) *E
 		GeneratorURL: alert.GeneratorURL,
 		Fingerprint:  alert.Fingerprint,
 	}
+	if alert.Annotations != nil {
+		extended.ImageURL = alert.Annotations[ngmodels.ImageURLAnnotation]
+	}
 
 	// fill in some grafana-specific urls
 	if len(externalURL) == 0 {
@@ -1,6 +1,11 @@
 package channels
 
-import "time"
+import (
+	"context"
+	"time"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
 
 // mockTimeNow replaces function timeNow to return constant time.
 // It returns a function that resets the variable back to its original value.
@@ -21,3 +26,15 @@ func mockTimeNow(constTime time.Time) func() {
 func resetTimeNow() {
 	timeNow = time.Now
 }
+
+// fakeImageStore returns the images by token.
+type fakeImageStore struct {
+	images map[string]*ngmodels.Image
+}
+
+func (f *fakeImageStore) GetImage(_ context.Context, token string) (*ngmodels.Image, error) {
+	if image, ok := f.images[token]; ok {
+		return image, nil
+	}
+	return nil, ngmodels.ErrImageNotFound
+}
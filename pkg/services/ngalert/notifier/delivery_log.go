@@ -0,0 +1,103 @@
+package notifier
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	gokit_log "github.com/go-kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/notifications"
+)
+
+// deliverySaveTimeout is the timeout for saving an attempt to send a notification. The
+// attempts are saved even when the context of the notification is canceled, to record
+// the notifications that timed out.
+const deliverySaveTimeout = 5 * time.Second
+
+type deliveryAttemptKey struct{}
+
+// deliveryAttemptStage starts counting the attempts to send a notification, which are
+// made by the retry stage that follows it.
+type deliveryAttemptStage struct{}
+
+func (deliveryAttemptStage) Exec(ctx context.Context, _ gokit_log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	return context.WithValue(ctx, deliveryAttemptKey{}, new(int64)), alerts, nil
+}
+
+// nextDeliveryAttempt returns the number of the next attempt to send the notification of
+// the context, starting from 1.
+func nextDeliveryAttempt(ctx context.Context) int {
+	if attempts, ok := ctx.Value(deliveryAttemptKey{}).(*int64); ok {
+		return int(atomic.AddInt64(attempts, 1))
+	}
+	return 1
+}
+
+// deliveryLogNotifier records each attempt of an integration to send a notification in
+// the delivery log.
+type deliveryLogNotifier struct {
+	NotificationChannel
+	store           store.DeliveryStore
+	logger          log.Logger
+	orgID           int64
+	receiver        string
+	integrationType string
+	integrationUID  string
+}
+
+func newDeliveryLogNotifier(n NotificationChannel, s store.DeliveryStore, l log.Logger, orgID int64, receiver string, r *apimodels.PostableGrafanaReceiver) *deliveryLogNotifier {
+	return &deliveryLogNotifier{
+		NotificationChannel: n,
+		store:               s,
+		logger:              l,
+		orgID:               orgID,
+		receiver:            receiver,
+		integrationType:     r.Type,
+		integrationUID:      r.UID,
+	}
+}
+
+func (n *deliveryLogNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	attempt := nextDeliveryAttempt(ctx)
+	ctx, statusCode := notifications.WithResponseStatusCode(ctx)
+
+	start := time.Now()
+	retry, err := n.NotificationChannel.Notify(ctx, as...)
+
+	groupKey, _ := notify.GroupKey(ctx)
+	delivery := &ngmodels.NotificationDelivery{
+		OrgID:           n.orgID,
+		Receiver:        n.receiver,
+		IntegrationType: n.integrationType,
+		IntegrationUID:  n.integrationUID,
+		GroupKey:        groupKey,
+		Alerts:          len(as),
+		Attempt:         attempt,
+		Status:          ngmodels.NotificationDelivered,
+		StatusCode:      statusCode(),
+		DurationMs:      time.Since(start).Milliseconds(),
+		SentAt:          start,
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+		delivery.Status = ngmodels.NotificationDropped
+		if retry {
+			delivery.Status = ngmodels.NotificationFailed
+		}
+	}
+
+	saveCtx, cancel := context.WithTimeout(context.Background(), deliverySaveTimeout)
+	defer cancel()
+	if err := n.store.SaveNotificationDelivery(saveCtx, delivery); err != nil {
+		n.logger.Warn("failed to save notification delivery", "receiver", n.receiver, "integration", n.integrationType, "err", err)
+	}
+
+	return retry, err
+}
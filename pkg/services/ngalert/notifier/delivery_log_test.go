@@ -0,0 +1,107 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/notifications"
+)
+
+type fakeDeliveryStore struct {
+	FakeConfigStore
+	deliveries []*ngmodels.NotificationDelivery
+}
+
+func (f *fakeDeliveryStore) SaveNotificationDelivery(_ context.Context, d *ngmodels.NotificationDelivery) error {
+	f.deliveries = append(f.deliveries, d)
+	return nil
+}
+
+type fakeNotificationChannel struct {
+	results []fakeNotifyResult
+}
+
+type fakeNotifyResult struct {
+	statusCode int
+	retry      bool
+	err        error
+}
+
+func (f *fakeNotificationChannel) Notify(ctx context.Context, _ ...*types.Alert) (bool, error) {
+	r := f.results[0]
+	f.results = f.results[1:]
+	if r.statusCode != 0 {
+		notifications.SetResponseStatusCode(ctx, r.statusCode)
+	}
+	return r.retry, r.err
+}
+
+func (f *fakeNotificationChannel) SendResolved() bool {
+	return true
+}
+
+func TestDeliveryLogNotifier(t *testing.T) {
+	receiver := &apimodels.PostableGrafanaReceiver{UID: "uid", Name: "slack", Type: "slack"}
+	alerts := []*types.Alert{{}, {}}
+
+	t.Run("each attempt to send a notification is recorded", func(t *testing.T) {
+		s := &fakeDeliveryStore{}
+		channel := &fakeNotificationChannel{results: []fakeNotifyResult{
+			{statusCode: http.StatusServiceUnavailable, retry: true, err: errors.New("unavailable")},
+			{err: errors.New("timeout"), retry: true},
+			{statusCode: http.StatusOK},
+		}}
+		n := newDeliveryLogNotifier(channel, s, log.New("test"), 1, "team", receiver)
+
+		ctx, _, err := deliveryAttemptStage{}.Exec(notify.WithGroupKey(context.Background(), "group"), nil, alerts...)
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			_, _ = n.Notify(ctx, alerts...)
+		}
+
+		require.Len(t, s.deliveries, 3)
+		for i, d := range s.deliveries {
+			require.Equal(t, i+1, d.Attempt)
+			require.Equal(t, int64(1), d.OrgID)
+			require.Equal(t, "team", d.Receiver)
+			require.Equal(t, "slack", d.IntegrationType)
+			require.Equal(t, "uid", d.IntegrationUID)
+			require.Equal(t, "group", d.GroupKey)
+			require.Equal(t, 2, d.Alerts)
+		}
+		require.Equal(t, ngmodels.NotificationFailed, s.deliveries[0].Status)
+		require.Equal(t, http.StatusServiceUnavailable, s.deliveries[0].StatusCode)
+		require.Equal(t, "unavailable", s.deliveries[0].Error)
+		require.Equal(t, ngmodels.NotificationFailed, s.deliveries[1].Status)
+		require.Equal(t, 0, s.deliveries[1].StatusCode)
+		require.Equal(t, ngmodels.NotificationDelivered, s.deliveries[2].Status)
+		require.Equal(t, http.StatusOK, s.deliveries[2].StatusCode)
+		require.Empty(t, s.deliveries[2].Error)
+	})
+
+	t.Run("attempts that are not retried are dropped", func(t *testing.T) {
+		s := &fakeDeliveryStore{}
+		channel := &fakeNotificationChannel{results: []fakeNotifyResult{
+			{statusCode: http.StatusBadRequest, err: errors.New("bad request")},
+		}}
+		n := newDeliveryLogNotifier(channel, s, log.New("test"), 1, "team", receiver)
+
+		retry, err := n.Notify(context.Background(), alerts...)
+		require.False(t, retry)
+		require.EqualError(t, err, "bad request")
+
+		require.Len(t, s.deliveries, 1)
+		require.Equal(t, 1, s.deliveries[0].Attempt)
+		require.Equal(t, ngmodels.NotificationDropped, s.deliveries[0].Status)
+		require.Equal(t, http.StatusBadRequest, s.deliveries[0].StatusCode)
+	})
+}
@@ -39,7 +39,7 @@ type MultiOrgAlertmanager struct {
 	peer         ClusterPeer
 	settleCancel context.CancelFunc
 
-	configStore store.AlertingStore
+	configStore AlertingStore
 	orgStore    store.OrgStore
 	kvStore     kvstore.KVStore
 
@@ -48,7 +48,7 @@ type MultiOrgAlertmanager struct {
 	metrics *metrics.MultiOrgAlertmanager
 }
 
-func NewMultiOrgAlertmanager(cfg *setting.Cfg, configStore store.AlertingStore, orgStore store.OrgStore,
+func NewMultiOrgAlertmanager(cfg *setting.Cfg, configStore AlertingStore, orgStore store.OrgStore,
 	kvStore kvstore.KVStore, decryptFn channels.GetDecryptedValueFn, m *metrics.MultiOrgAlertmanager, l log.Logger,
 ) (*MultiOrgAlertmanager, error) {
 	moa := &MultiOrgAlertmanager{
@@ -260,12 +260,12 @@ func (moa *MultiOrgAlertmanager) cleanupOrphanLocalOrgState(ctx context.Context,
 	// Remove all orphaned items from kvstore by listing all existing items
 	// in our used namespace and comparing them to the currently active
 	// organizations.
-	storedFiles := []string{notificationLogFilename, silencesFilename}
-	for _, fileName := range storedFiles {
-		keys, err := moa.kvStore.Keys(ctx, kvstore.AllOrganizations, KVNamespace, fileName)
+	storedKeys := []string{notificationLogFilename, silencesFilename, slackThreadKeyPrefix}
+	for _, keyPrefix := range storedKeys {
+		keys, err := moa.kvStore.Keys(ctx, kvstore.AllOrganizations, KVNamespace, keyPrefix)
 		if err != nil {
 			moa.logger.Error("failed to fetch items from kvstore", "err", err,
-				"namespace", KVNamespace, "key", fileName)
+				"namespace", KVNamespace, "key", keyPrefix)
 		}
 		for _, key := range keys {
 			if _, exists := activeOrganizations[key.OrgId]; exists {
@@ -316,6 +316,22 @@ func (moa *MultiOrgAlertmanager) AlertmanagerFor(orgID int64) (*Alertmanager, er
 	return orgAM, nil
 }
 
+// ClusterMembers returns the name of this instance and the names of all alive
+// instances in the high availability cluster. If high availability is not enabled,
+// there are no members.
+func (moa *MultiOrgAlertmanager) ClusterMembers() (string, []string) {
+	p, ok := moa.peer.(*cluster.Peer)
+	if !ok {
+		return "", nil
+	}
+	peers := p.Peers()
+	members := make([]string, 0, len(peers))
+	for _, member := range peers {
+		members = append(members, member.Name())
+	}
+	return p.Name(), members
+}
+
 // NilPeer and NilChannel implements the Alertmanager clustering interface.
 type NilPeer struct{}
 
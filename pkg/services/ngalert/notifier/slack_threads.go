@@ -0,0 +1,99 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+)
+
+// slackThreadKeyPrefix is the prefix of the keys of the messages of alert groups posted
+// by the Slack notifiers in the KVstore.
+const slackThreadKeyPrefix = "slack_thread."
+
+// slackThreadStore persists the messages of alert groups posted by the Slack notifiers
+// of an organization in the KVstore, encoded as JSON.
+type slackThreadStore struct {
+	kv     *kvstore.NamespacedKVStore
+	logger log.Logger
+}
+
+func newSlackThreadStore(orgID int64, store kvstore.KVStore) *slackThreadStore {
+	return &slackThreadStore{
+		kv:     kvstore.WithNamespace(store, orgID, KVNamespace),
+		logger: log.New("alertmanager.slackthreads", "org", orgID),
+	}
+}
+
+// GetSlackThread implements the channels.SlackThreadStore interface.
+func (s *slackThreadStore) GetSlackThread(ctx context.Context, key string) (*channels.SlackThread, error) {
+	value, exists, err := s.kv.Get(ctx, slackThreadKeyPrefix+key)
+	if err != nil || !exists {
+		return nil, err
+	}
+	var thread channels.SlackThread
+	if err := json.Unmarshal([]byte(value), &thread); err != nil {
+		return nil, fmt.Errorf("failed to decode the Slack message %q: %w", key, err)
+	}
+	return &thread, nil
+}
+
+// SaveSlackThread implements the channels.SlackThreadStore interface.
+func (s *slackThreadStore) SaveSlackThread(ctx context.Context, key string, thread channels.SlackThread) error {
+	b, err := json.Marshal(thread)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, slackThreadKeyPrefix+key, string(b))
+}
+
+// deleteExpired deletes the messages of the alert groups that were last notified before
+// olderThan, and returns the number of deleted messages.
+func (s *slackThreadStore) deleteExpired(ctx context.Context, olderThan time.Time) (int, error) {
+	keys, err := s.kv.Keys(ctx, slackThreadKeyPrefix)
+	if err != nil {
+		return 0, err
+	}
+	var deleted int
+	for _, key := range keys {
+		value, exists, err := s.kv.Get(ctx, key.Key)
+		if err != nil {
+			return deleted, err
+		}
+		if !exists {
+			continue
+		}
+		var thread channels.SlackThread
+		if err := json.Unmarshal([]byte(value), &thread); err == nil && !thread.UpdatedAt.Before(olderThan) {
+			continue
+		}
+		if err := s.kv.Del(ctx, key.Key); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// maintenance periodically deletes the expired messages until stopc is closed.
+func (s *slackThreadStore) maintenance(interval time.Duration, stopc <-chan struct{}) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-stopc:
+			return
+		case <-t.C:
+			deleted, err := s.deleteExpired(context.Background(), time.Now().Add(-channels.SlackThreadRetention))
+			if err != nil {
+				s.logger.Error("failed to delete the expired Slack messages", "err", err)
+				continue
+			}
+			s.logger.Debug("deleted the expired Slack messages", "count", deleted)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+)
+
+func TestSlackThreadStore(t *testing.T) {
+	ctx := context.Background()
+	kv := newFakeKVStore(t)
+	s := newSlackThreadStore(1, kv)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	thread, err := s.GetSlackThread(ctx, "group")
+	require.NoError(t, err)
+	require.Nil(t, thread)
+
+	require.NoError(t, s.SaveSlackThread(ctx, "group", channels.SlackThread{Channel: "C1", Ts: "1.0001", UpdatedAt: now}))
+	require.NoError(t, s.SaveSlackThread(ctx, "expired", channels.SlackThread{Channel: "C1", Ts: "0.0001", UpdatedAt: now.Add(-time.Hour)}))
+	// the messages are stored in the namespace of the Alertmanager, so that they are
+	// deleted with the organization
+	require.NoError(t, kv.Set(ctx, 1, KVNamespace, slackThreadKeyPrefix+"invalid", "{"))
+
+	thread, err = s.GetSlackThread(ctx, "group")
+	require.NoError(t, err)
+	require.Equal(t, &channels.SlackThread{Channel: "C1", Ts: "1.0001", UpdatedAt: now}, thread)
+
+	_, err = s.GetSlackThread(ctx, "invalid")
+	require.Error(t, err)
+
+	deleted, err := s.deleteExpired(ctx, now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 2, deleted)
+
+	keys, err := kv.Keys(ctx, 1, KVNamespace, slackThreadKeyPrefix)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.Equal(t, slackThreadKeyPrefix+"group", keys[0].Key)
+}
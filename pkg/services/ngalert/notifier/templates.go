@@ -0,0 +1,247 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	tmpltext "text/template"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+)
+
+const (
+	// maxTestTemplateAlerts is the maximum number of current alerts of the Alertmanager
+	// the templates are tested with.
+	maxTestTemplateAlerts = 100
+)
+
+var (
+	ErrInvalidTemplateName = errors.New("invalid template name")
+
+	// templateErrorRe matches the location of the errors of text/template, such as
+	// "template: name:3: ..." or "template: name:3:10: ...".
+	templateErrorRe = regexp.MustCompile(`^template: (.*?):(\d+)(?::\d+)?: `)
+)
+
+type TestTemplatesResults struct {
+	Results []TestTemplatesResult
+	Errors  []TestTemplatesErrorResult
+}
+
+type TestTemplatesResult struct {
+	Name string
+	Text string
+}
+
+type TestTemplatesErrorResult struct {
+	Name string
+	Kind apimodels.TemplateErrorKind
+	Err  error
+	// Line is the line of the error in the tested template file, or 0 if the error is
+	// in another file or its line is unknown.
+	Line int
+}
+
+// isValidTemplateName returns true if the name of a template file can be saved in the
+// working directory of the Alertmanager, as a file name without path.
+func isValidTemplateName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+// TestTemplate executes each template declared in the template file with the same
+// template data as the notifications. The template file is parsed with the saved
+// template files of the Alertmanager, replacing the one with the same name.
+func (am *Alertmanager) TestTemplate(ctx context.Context, c apimodels.TestTemplatesConfigBodyParams) (*TestTemplatesResults, error) {
+	if !isValidTemplateName(c.Name) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTemplateName, c.Name)
+	}
+
+	// The template file is parsed on its own first, so that the line of syntax errors
+	// is reported even when the file is not valid with the other template files.
+	parsed, err := tmpltext.New(c.Name).Funcs(tmpltext.FuncMap(template.DefaultFuncs)).Parse(c.Template)
+	if err != nil {
+		return &TestTemplatesResults{Errors: []TestTemplatesErrorResult{newTemplateError("", apimodels.InvalidTemplate, c.Name, err)}}, nil
+	}
+	names := make([]string, 0, len(parsed.Templates()))
+	for _, t := range parsed.Templates() {
+		if t.Name() != c.Name {
+			names = append(names, t.Name())
+		}
+	}
+	if len(names) == 0 {
+		err := errors.New(`the template file does not declare any templates, use {{ define "name" }} to declare one`)
+		return &TestTemplatesResults{Errors: []TestTemplatesErrorResult{{Kind: apimodels.InvalidTemplate, Err: err}}}, nil
+	}
+	sort.Strings(names)
+
+	tmpl, err := am.templateWith(c.Name, c.Template)
+	if err != nil {
+		var parseErr templateParseError
+		if !errors.As(err, &parseErr) {
+			return nil, err
+		}
+		return &TestTemplatesResults{Errors: []TestTemplatesErrorResult{newTemplateError("", apimodels.InvalidTemplate, c.Name, parseErr.err)}}, nil
+	}
+
+	alerts := am.testTemplateAlerts(c)
+	ctx = notify.WithGroupKey(ctx, fmt.Sprintf("%s-%d", c.Name, time.Now().UnixNano()))
+	ctx = notify.WithGroupLabels(ctx, testTemplateGroupLabels(alerts))
+	ctx = notify.WithReceiverName(ctx, "TestReceiver")
+
+	res := &TestTemplatesResults{Results: []TestTemplatesResult{}, Errors: []TestTemplatesErrorResult{}}
+	for _, name := range names {
+		var tmplErr error
+		expand, _ := channels.TmplText(ctx, tmpl, alerts, am.logger, &tmplErr)
+		text := expand(fmt.Sprintf(`{{ template %q . }}`, name))
+		if tmplErr != nil {
+			res.Errors = append(res.Errors, newTemplateError(name, apimodels.TemplateExecutionFailed, c.Name, tmplErr))
+			continue
+		}
+		res.Results = append(res.Results, TestTemplatesResult{Name: name, Text: text})
+	}
+	return res, nil
+}
+
+// templateWith returns the template of the Alertmanager with the template file added,
+// or replacing the template file with the same name.
+func (am *Alertmanager) templateWith(name, content string) (*template.Template, error) {
+	am.reloadConfigMtx.RLock()
+	if !am.ready() {
+		am.reloadConfigMtx.RUnlock()
+		return nil, errors.New("alertmanager is not initialized")
+	}
+	files := make(map[string]string, len(am.config.TemplateFiles)+1)
+	for k, v := range am.config.TemplateFiles {
+		files[k] = v
+	}
+	am.reloadConfigMtx.RUnlock()
+	files[name] = content
+
+	dir, err := os.MkdirTemp("", "alertmanager-templates")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the template directory: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			am.logger.Warn("failed to remove the template directory", "dir", dir, "err", err)
+		}
+	}()
+
+	paths, _, err := PersistTemplates(&apimodels.PostableUserConfig{TemplateFiles: files}, dir)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := am.templateFromPaths(paths...)
+	if err != nil {
+		return nil, templateParseError{err: err}
+	}
+	return tmpl, nil
+}
+
+// templateParseError is returned when the template files cannot be parsed together,
+// for example when a template is declared twice.
+type templateParseError struct {
+	err error
+}
+
+func (e templateParseError) Error() string {
+	return e.err.Error()
+}
+
+// testTemplateAlerts returns the alerts of the request, the active alerts of the
+// Alertmanager if requested, or a sample alert.
+func (am *Alertmanager) testTemplateAlerts(c apimodels.TestTemplatesConfigBodyParams) []*types.Alert {
+	now := time.Now()
+	if len(c.Alerts) > 0 {
+		alerts := make([]*types.Alert, 0, len(c.Alerts))
+		for _, a := range c.Alerts {
+			if a == nil {
+				continue
+			}
+			alert := &types.Alert{
+				Alert: model.Alert{
+					Labels:       a.Labels.Clone(),
+					Annotations:  a.Annotations.Clone(),
+					StartsAt:     a.StartsAt,
+					EndsAt:       a.EndsAt,
+					GeneratorURL: a.GeneratorURL,
+				},
+				UpdatedAt: now,
+			}
+			if alert.StartsAt.IsZero() {
+				alert.StartsAt = now
+			}
+			alerts = append(alerts, alert)
+		}
+		return alerts
+	}
+
+	if c.CurrentAlerts {
+		if alerts := am.activeAlerts(maxTestTemplateAlerts); len(alerts) > 0 {
+			return alerts
+		}
+	}
+
+	alert := newTestAlert(apimodels.TestReceiversConfigParams{}, now, now)
+	return []*types.Alert{&alert}
+}
+
+// activeAlerts returns up to limit alerts of the Alertmanager that are not resolved,
+// sorted by fingerprint.
+func (am *Alertmanager) activeAlerts(limit int) []*types.Alert {
+	it := am.alerts.GetPending()
+	defer it.Close()
+
+	var alerts []*types.Alert
+	for a := range it.Next() {
+		if !a.Resolved() {
+			alerts = append(alerts, a)
+		}
+	}
+	if err := it.Err(); err != nil {
+		am.logger.Warn("failed to iterate through the alerts", "err", err)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].Fingerprint() < alerts[j].Fingerprint()
+	})
+	if len(alerts) > limit {
+		alerts = alerts[:limit]
+	}
+	return alerts
+}
+
+// testTemplateGroupLabels returns the group labels of the test notification. Alerts are
+// grouped by alert name by default, so the group has the alert name the alerts share.
+func testTemplateGroupLabels(alerts []*types.Alert) model.LabelSet {
+	groupLabels := model.LabelSet{}
+	for i, a := range alerts {
+		name, ok := a.Labels[model.AlertNameLabel]
+		if !ok || (i > 0 && groupLabels[model.AlertNameLabel] != name) {
+			return model.LabelSet{}
+		}
+		groupLabels[model.AlertNameLabel] = name
+	}
+	return groupLabels
+}
+
+// newTemplateError returns the error with the line of the error in the tested template
+// file, if it is in that file.
+func newTemplateError(name string, kind apimodels.TemplateErrorKind, file string, err error) TestTemplatesErrorResult {
+	res := TestTemplatesErrorResult{Name: name, Kind: kind, Err: err}
+	if m := templateErrorRe.FindStringSubmatch(err.Error()); m != nil && m[1] == file {
+		res.Line, _ = strconv.Atoi(m[2])
+	}
+	return res
+}
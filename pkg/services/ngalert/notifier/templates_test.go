@@ -0,0 +1,145 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestTestTemplate(t *testing.T) {
+	am := setupAMTest(t)
+	cfg, err := Load([]byte(`{
+		"template_files": {
+			"saved.tmpl": "{{ define \"saved.title\" }}Saved {{ .Status }}{{ end }}"
+		},
+		"alertmanager_config": {
+			"route": {"receiver": "default"},
+			"receivers": [{"name": "default"}]
+		}
+	}`))
+	require.NoError(t, err)
+	require.NoError(t, am.applyConfig(cfg, nil))
+
+	now := time.Now()
+	cases := []struct {
+		name       string
+		params     apimodels.TestTemplatesConfigBodyParams
+		expResults []TestTemplatesResult
+		expErrors  []TestTemplatesErrorResult
+	}{
+		{
+			name: "templates are executed with a sample alert",
+			params: apimodels.TestTemplatesConfigBodyParams{
+				Name: "test.tmpl",
+				Template: `{{ define "test.title" }}[{{ .Status | toUpper }}] {{ .GroupLabels.alertname }} {{ .CommonAnnotations.summary }}{{ end }}
+{{ define "test.saved" }}{{ template "saved.title" . }}{{ end }}`,
+			},
+			expResults: []TestTemplatesResult{
+				{Name: "test.saved", Text: "Saved firing"},
+				{Name: "test.title", Text: "[FIRING] TestAlert Notification test"},
+			},
+			expErrors: []TestTemplatesErrorResult{},
+		},
+		{
+			name: "templates are executed with the alerts of the request",
+			params: apimodels.TestTemplatesConfigBodyParams{
+				Name:     "test.tmpl",
+				Template: `{{ define "test.alerts" }}{{ len .Alerts.Firing }} firing, {{ len .Alerts.Resolved }} resolved{{ range .Alerts }} {{ .Labels.instance }}{{ end }}{{ end }}`,
+				Alerts: []*apimodels.TestTemplatesAlert{
+					{Labels: model.LabelSet{"alertname": "HighCPU", "instance": "a"}},
+					{Labels: model.LabelSet{"alertname": "HighCPU", "instance": "b"}, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(-time.Minute)},
+				},
+			},
+			expResults: []TestTemplatesResult{
+				{Name: "test.alerts", Text: "1 firing, 1 resolved a b"},
+			},
+			expErrors: []TestTemplatesErrorResult{},
+		},
+		{
+			name: "the template replaces the saved template with the same name",
+			params: apimodels.TestTemplatesConfigBodyParams{
+				Name:     "saved.tmpl",
+				Template: `{{ define "saved.title" }}Updated {{ .Status }}{{ end }}`,
+			},
+			expResults: []TestTemplatesResult{
+				{Name: "saved.title", Text: "Updated firing"},
+			},
+			expErrors: []TestTemplatesErrorResult{},
+		},
+		{
+			name: "syntax errors are returned with their line",
+			params: apimodels.TestTemplatesConfigBodyParams{
+				Name:     "test.tmpl",
+				Template: "{{ define \"test.title\" }}\n{{ if }}{{ end }}\n{{ end }}",
+			},
+			expErrors: []TestTemplatesErrorResult{
+				{Kind: apimodels.InvalidTemplate, Line: 2},
+			},
+		},
+		{
+			name: "execution errors are returned with their line",
+			params: apimodels.TestTemplatesConfigBodyParams{
+				Name:     "test.tmpl",
+				Template: "{{ define \"test.ok\" }}ok{{ end }}\n{{ define \"test.fail\" }}\n{{ index .Alerts 5 }}{{ end }}",
+			},
+			expResults: []TestTemplatesResult{
+				{Name: "test.ok", Text: "ok"},
+			},
+			expErrors: []TestTemplatesErrorResult{
+				{Name: "test.fail", Kind: apimodels.TemplateExecutionFailed, Line: 3},
+			},
+		},
+		{
+			name: "templates must be declared",
+			params: apimodels.TestTemplatesConfigBodyParams{
+				Name:     "test.tmpl",
+				Template: "{{ .Status }}",
+			},
+			expErrors: []TestTemplatesErrorResult{
+				{Kind: apimodels.InvalidTemplate},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			res, err := am.TestTemplate(context.Background(), c.params)
+			require.NoError(t, err)
+			require.Equal(t, c.expResults, res.Results)
+			require.Len(t, res.Errors, len(c.expErrors))
+			for i, expErr := range c.expErrors {
+				require.Equal(t, expErr.Name, res.Errors[i].Name)
+				require.Equal(t, expErr.Kind, res.Errors[i].Kind)
+				require.Equal(t, expErr.Line, res.Errors[i].Line)
+				require.Error(t, res.Errors[i].Err)
+			}
+		})
+	}
+
+	t.Run("templates are executed with the active alerts", func(t *testing.T) {
+		require.NoError(t, am.alerts.Put(
+			&types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "Active"}, StartsAt: now}, UpdatedAt: now},
+			&types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "Resolved"}, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(-time.Minute)}, UpdatedAt: now},
+		))
+		res, err := am.TestTemplate(context.Background(), apimodels.TestTemplatesConfigBodyParams{
+			Name:          "test.tmpl",
+			Template:      `{{ define "test.names" }}{{ range .Alerts }}{{ .Labels.alertname }}{{ end }}{{ end }}`,
+			CurrentAlerts: true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []TestTemplatesResult{{Name: "test.names", Text: "Active"}}, res.Results)
+	})
+
+	t.Run("the name must be a file name", func(t *testing.T) {
+		for _, name := range []string{"", ".", "..", "../test.tmpl", "dir/test.tmpl", `dir\test.tmpl`} {
+			_, err := am.TestTemplate(context.Background(), apimodels.TestTemplatesConfigBodyParams{Name: name, Template: `{{ define "a" }}{{ end }}`})
+			require.ErrorIs(t, err, ErrInvalidTemplateName, name)
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -23,6 +24,30 @@ func (f *FakeConfigStore) GetAllLatestAlertmanagerConfiguration(context.Context)
 	return result, nil
 }
 
+func (f *FakeConfigStore) GetImage(context.Context, string) (*models.Image, error) {
+	return nil, models.ErrImageNotFound
+}
+
+func (f *FakeConfigStore) SaveImage(context.Context, *models.Image) error {
+	return nil
+}
+
+func (f *FakeConfigStore) DeleteExpiredImages(context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func (f *FakeConfigStore) SaveNotificationDelivery(context.Context, *models.NotificationDelivery) error {
+	return nil
+}
+
+func (f *FakeConfigStore) ListNotificationDeliveries(context.Context, *models.ListNotificationDeliveriesQuery) error {
+	return nil
+}
+
+func (f *FakeConfigStore) DeleteNotificationDeliveries(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
 func (f *FakeConfigStore) GetLatestAlertmanagerConfiguration(query *models.GetLatestAlertmanagerConfigurationQuery) error {
 	var ok bool
 	query.Result, ok = f.configs[query.OrgID]
@@ -146,7 +171,7 @@ func (fkv *FakeKVStore) Keys(ctx context.Context, orgID int64, namespace string,
 					keys = append(keys, kvstore.Key{
 						OrgId:     orgIDFromStore,
 						Namespace: namespace,
-						Key:       keyPrefix,
+						Key:       k,
 					})
 				}
 			}
@@ -0,0 +1,325 @@
+package provisioning
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// FileVersion is the version of the format of the files.
+const FileVersion = 1
+
+// File is the format of the files that alerting resources are provisioned from and
+// exported to. The contact points, notification policies and mute timings have the
+// format of the Alertmanager configuration API.
+type File struct {
+	APIVersion                int64                          `json:"apiVersion"`
+	Groups                    []*RuleGroup                   `json:"groups,omitempty"`
+	DeleteGroups              []*RuleGroupReference          `json:"deleteGroups,omitempty"`
+	AlertmanagerConfigs       []*AlertmanagerConfig          `json:"alertmanagerConfigs,omitempty"`
+	DeleteAlertmanagerConfigs []*AlertmanagerConfigReference `json:"deleteAlertmanagerConfigs,omitempty"`
+}
+
+// RuleGroup is a group of Grafana managed alert rules in a folder, the folder is created
+// if it does not exist.
+type RuleGroup struct {
+	OrgID    int64          `json:"orgId"`
+	Folder   string         `json:"folder"`
+	Name     string         `json:"name"`
+	Interval model.Duration `json:"interval,omitempty"`
+	Rules    []*AlertRule   `json:"rules"`
+	IsPaused bool           `json:"isPaused,omitempty"`
+}
+
+// RuleGroupReference references a rule group to delete.
+type RuleGroupReference struct {
+	OrgID  int64  `json:"orgId"`
+	Folder string `json:"folder"`
+	Name   string `json:"name"`
+}
+
+// AlertRule is a Grafana managed alert rule. The UID is required so that the alert rule
+// keeps its state and history when the files are applied again.
+type AlertRule struct {
+	UID          string                       `json:"uid"`
+	Title        string                       `json:"title"`
+	Condition    string                       `json:"condition,omitempty"`
+	Data         []ngmodels.AlertQuery        `json:"data"`
+	For          model.Duration               `json:"for,omitempty"`
+	NoDataState  ngmodels.NoDataState         `json:"noDataState,omitempty"`
+	ExecErrState ngmodels.ExecutionErrorState `json:"execErrState,omitempty"`
+	Annotations  map[string]string            `json:"annotations,omitempty"`
+	Labels       map[string]string            `json:"labels,omitempty"`
+	IsPaused     bool                         `json:"isPaused,omitempty"`
+	Record       *Record                      `json:"record,omitempty"`
+	SuppressedBy *SuppressedBy                `json:"suppressedBy,omitempty"`
+}
+
+// Record is the time series written by a recording rule.
+type Record struct {
+	Metric string `json:"metric"`
+	Target string `json:"target"`
+}
+
+// SuppressedBy is what suppresses the alerts of an alert rule.
+type SuppressedBy struct {
+	RuleUIDs []string          `json:"ruleUids,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+// AlertmanagerConfig is the configuration of the Grafana Alertmanager of an organization.
+// It replaces the whole configuration, which cannot be changed from the UI anymore.
+type AlertmanagerConfig struct {
+	OrgID         int64                            `json:"orgId"`
+	ContactPoints []*apimodels.PostableApiReceiver `json:"contactPoints"`
+	Policies      *apimodels.Route                 `json:"policies"`
+	MuteTimes     []apimodels.MuteTimeInterval     `json:"muteTimes,omitempty"`
+	Templates     map[string]string                `json:"templates,omitempty"`
+}
+
+// AlertmanagerConfigReference references the Alertmanager configuration of an
+// organization to reset to the default.
+type AlertmanagerConfigReference struct {
+	OrgID int64 `json:"orgId"`
+}
+
+// ParseFile parses a file of alerting resources. The file is in YAML, or in JSON which
+// is a subset of YAML.
+func ParseFile(data []byte) (*File, error) {
+	// the resources use the JSON format of the API, so the YAML is converted to JSON
+	// before it is decoded
+	var v interface{}
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var f File
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, err
+	}
+	if f.APIVersion != FileVersion {
+		return nil, fmt.Errorf("unsupported apiVersion %d, expected %d", f.APIVersion, FileVersion)
+	}
+	return &f, nil
+}
+
+// EncodeFile encodes a file of alerting resources in YAML.
+func EncodeFile(f *File) ([]byte, error) {
+	b, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is valid YAML, decoding it into a node keeps the order of the keys
+	var node yaml.Node
+	if err := yaml.Unmarshal(b, &node); err != nil {
+		return nil, err
+	}
+	resetStyle(&node)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resetStyle replaces the flow style of the nodes decoded from JSON with the block style
+// of YAML, and writes multiline strings as literals.
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" && strings.Contains(node.Value, "\n") {
+		node.Style = yaml.LiteralStyle
+	}
+	for _, n := range node.Content {
+		resetStyle(n)
+	}
+}
+
+// Validate checks that the resources of the file are complete and not duplicated.
+func (f *File) Validate() error {
+	groups := make(map[RuleGroupReference]struct{}, len(f.Groups))
+	ruleUIDs := make(map[int64]map[string]struct{})
+	for _, g := range f.Groups {
+		if g.Folder == "" || g.Name == "" {
+			return errors.New("rule groups require a folder and a name")
+		}
+		ref := RuleGroupReference{OrgID: g.OrgID, Folder: g.Folder, Name: g.Name}
+		if _, ok := groups[ref]; ok {
+			return fmt.Errorf("rule group %q of folder %q is defined more than once", g.Name, g.Folder)
+		}
+		groups[ref] = struct{}{}
+
+		if ruleUIDs[g.OrgID] == nil {
+			ruleUIDs[g.OrgID] = make(map[string]struct{})
+		}
+		for _, r := range g.Rules {
+			if r.UID == "" {
+				return fmt.Errorf("alert rule %q of rule group %q requires a uid", r.Title, g.Name)
+			}
+			if _, ok := ruleUIDs[g.OrgID][r.UID]; ok {
+				return fmt.Errorf("alert rule uid %q is used more than once", r.UID)
+			}
+			ruleUIDs[g.OrgID][r.UID] = struct{}{}
+		}
+	}
+
+	for _, g := range f.DeleteGroups {
+		if g.Folder == "" || g.Name == "" {
+			return errors.New("deleted rule groups require a folder and a name")
+		}
+	}
+
+	orgs := make(map[int64]struct{}, len(f.AlertmanagerConfigs))
+	for _, c := range f.AlertmanagerConfigs {
+		if _, ok := orgs[c.OrgID]; ok {
+			return fmt.Errorf("the Alertmanager configuration of organization %d is defined more than once", c.OrgID)
+		}
+		orgs[c.OrgID] = struct{}{}
+		if _, err := c.UserConfig(); err != nil {
+			return fmt.Errorf("invalid Alertmanager configuration of organization %d: %w", c.OrgID, err)
+		}
+	}
+	return nil
+}
+
+// Merge adds the resources of another file.
+func (f *File) Merge(other *File) {
+	f.Groups = append(f.Groups, other.Groups...)
+	f.DeleteGroups = append(f.DeleteGroups, other.DeleteGroups...)
+	f.AlertmanagerConfigs = append(f.AlertmanagerConfigs, other.AlertmanagerConfigs...)
+	f.DeleteAlertmanagerConfigs = append(f.DeleteAlertmanagerConfigs, other.DeleteAlertmanagerConfigs...)
+}
+
+// RuleGroupConfig returns the rule group in the format of the ruler API.
+func (g *RuleGroup) RuleGroupConfig() apimodels.PostableRuleGroupConfig {
+	cfg := apimodels.PostableRuleGroupConfig{
+		Name:     g.Name,
+		Interval: g.Interval,
+		Rules:    make([]apimodels.PostableExtendedRuleNode, 0, len(g.Rules)),
+		IsPaused: g.IsPaused,
+	}
+	for _, r := range g.Rules {
+		node := apimodels.PostableExtendedRuleNode{
+			ApiRuleNode: &apimodels.ApiRuleNode{
+				For:         r.For,
+				Annotations: r.Annotations,
+				Labels:      r.Labels,
+			},
+			GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+				UID:          r.UID,
+				Title:        r.Title,
+				Condition:    r.Condition,
+				Data:         r.Data,
+				NoDataState:  apimodels.NoDataState(r.NoDataState),
+				ExecErrState: apimodels.ExecutionErrorState(r.ExecErrState),
+				IsPaused:     r.IsPaused,
+			},
+		}
+		if r.Record != nil {
+			node.GrafanaManagedAlert.Record = &apimodels.Record{Metric: r.Record.Metric, Target: r.Record.Target}
+		}
+		if r.SuppressedBy != nil {
+			node.GrafanaManagedAlert.SuppressedBy = &apimodels.SuppressedBy{RuleUIDs: r.SuppressedBy.RuleUIDs, Labels: r.SuppressedBy.Labels}
+		}
+		cfg.Rules = append(cfg.Rules, node)
+	}
+	return cfg
+}
+
+// newRuleGroup returns the rule group of the alert rules of a folder.
+func newRuleGroup(orgID int64, folder, name string, rules []*ngmodels.AlertRule) *RuleGroup {
+	g := &RuleGroup{
+		OrgID:  orgID,
+		Folder: folder,
+		Name:   name,
+		Rules:  make([]*AlertRule, 0, len(rules)),
+	}
+	for _, r := range rules {
+		g.Interval = model.Duration(time.Duration(r.IntervalSeconds) * time.Second)
+		g.IsPaused = r.IsGroupPaused
+		rule := &AlertRule{
+			UID:          r.UID,
+			Title:        r.Title,
+			Condition:    r.Condition,
+			Data:         r.Data,
+			For:          model.Duration(r.For),
+			NoDataState:  r.NoDataState,
+			ExecErrState: r.ExecErrState,
+			Annotations:  r.Annotations,
+			Labels:       r.Labels,
+			IsPaused:     r.IsPaused,
+		}
+		if r.IsRecording() {
+			rule.Record = &Record{Metric: r.Record, Target: string(r.RecordTarget)}
+		}
+		if r.IsSuppressible() {
+			rule.SuppressedBy = &SuppressedBy{RuleUIDs: r.SuppressedByRuleUIDs, Labels: r.SuppressedByLabels}
+		}
+		g.Rules = append(g.Rules, rule)
+	}
+	return g
+}
+
+// UserConfig returns the configuration in the format of the Alertmanager configuration API.
+// It is validated like the configurations posted to the API.
+func (c *AlertmanagerConfig) UserConfig() (*apimodels.PostableUserConfig, error) {
+	templates := make([]string, 0, len(c.Templates))
+	for name := range c.Templates {
+		templates = append(templates, name)
+	}
+	sort.Strings(templates)
+
+	cfg := apimodels.PostableUserConfig{
+		TemplateFiles: c.Templates,
+		AlertmanagerConfig: apimodels.PostableApiAlertingConfig{
+			Config: apimodels.Config{
+				Route:             c.Policies,
+				Templates:         templates,
+				MuteTimeIntervals: c.MuteTimes,
+			},
+			Receivers: c.ContactPoints,
+		},
+	}
+
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var result apimodels.PostableUserConfig
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// newAlertmanagerConfig returns the configuration of an organization from a configuration
+// in the format of the Alertmanager configuration API.
+func newAlertmanagerConfig(orgID int64, cfg *apimodels.PostableUserConfig) *AlertmanagerConfig {
+	return &AlertmanagerConfig{
+		OrgID:         orgID,
+		ContactPoints: cfg.AlertmanagerConfig.Receivers,
+		Policies:      cfg.AlertmanagerConfig.Route,
+		MuteTimes:     cfg.AlertmanagerConfig.MuteTimeIntervals,
+		Templates:     cfg.TemplateFiles,
+	}
+}
@@ -0,0 +1,240 @@
+package provisioning
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestParseFile(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/alerting.yaml")
+	require.NoError(t, err)
+
+	f, err := ParseFile(data)
+	require.NoError(t, err)
+	require.NoError(t, f.Validate())
+
+	require.Len(t, f.Groups, 1)
+	g := f.Groups[0]
+	require.Equal(t, int64(1), g.OrgID)
+	require.Equal(t, "Infrastructure", g.Folder)
+	require.Equal(t, "cpu", g.Name)
+	require.Equal(t, model.Duration(time.Minute), g.Interval)
+	require.Len(t, g.Rules, 2)
+
+	r := g.Rules[0]
+	require.Equal(t, "cpu-high", r.UID)
+	require.Equal(t, "B", r.Condition)
+	require.Len(t, r.Data, 2)
+	require.Equal(t, "prometheus", r.Data[0].DatasourceUID)
+	require.Equal(t, ngmodels.Duration(10*time.Minute), r.Data[0].RelativeTimeRange.From)
+	require.JSONEq(t, `{"expr": "rate(node_cpu_seconds_total{mode!=\"idle\"}[5m])"}`, string(r.Data[0].Model))
+	require.Equal(t, model.Duration(5*time.Minute), r.For)
+	require.Equal(t, ngmodels.NoData, r.NoDataState)
+	require.Equal(t, ngmodels.AlertingErrState, r.ExecErrState)
+	require.Equal(t, map[string]string{"summary": "{{ $labels.instance }} is busy"}, r.Annotations)
+	require.Equal(t, &Record{Metric: "instance:cpu:rate5m", Target: "remote_write"}, g.Rules[1].Record)
+
+	require.Equal(t, []*RuleGroupReference{{OrgID: 1, Folder: "Infrastructure", Name: "memory"}}, f.DeleteGroups)
+
+	require.Len(t, f.AlertmanagerConfigs, 1)
+	cfg, err := f.AlertmanagerConfigs[0].UserConfig()
+	require.NoError(t, err)
+	require.Equal(t, []string{"ops.tmpl"}, cfg.AlertmanagerConfig.Templates)
+	require.Contains(t, cfg.TemplateFiles["ops.tmpl"], "{{ .CommonLabels.alertname }}\n")
+	require.Equal(t, "ops", cfg.AlertmanagerConfig.Route.Receiver)
+	require.Equal(t, []string{"weekends"}, cfg.AlertmanagerConfig.Route.Routes[0].MuteTimeIntervals)
+	require.Len(t, cfg.AlertmanagerConfig.MuteTimeIntervals, 1)
+	receivers := cfg.GetGrafanaReceiverMap()
+	require.Len(t, receivers, 1)
+	require.Equal(t, "slack", receivers["ops-slack"].Type)
+	require.Equal(t, map[string]string{"url": "https://hooks.slack.com/services/secret"}, receivers["ops-slack"].SecureSettings)
+}
+
+func TestParseFileErrors(t *testing.T) {
+	for _, tc := range []struct {
+		desc string
+		data string
+		err  string
+	}{
+		{
+			desc: "unsupported version",
+			data: "apiVersion: 2",
+			err:  "unsupported apiVersion 2, expected 1",
+		},
+		{
+			desc: "invalid YAML",
+			data: "apiVersion: [1",
+		},
+		{
+			desc: "invalid rule",
+			data: "apiVersion: 1\ngroups:\n  - name: a\n    rules:\n      - data: 1",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := ParseFile([]byte(tc.data))
+			require.Error(t, err)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestFileValidate(t *testing.T) {
+	rule := func(uid string) *AlertRule {
+		return &AlertRule{UID: uid, Title: uid}
+	}
+	policies := &apimodels.Route{Receiver: "a"}
+	contactPoints := []*apimodels.PostableApiReceiver{{}}
+	contactPoints[0].Name = "a"
+
+	for _, tc := range []struct {
+		desc string
+		file File
+		err  string
+	}{
+		{
+			desc: "valid",
+			file: File{
+				Groups: []*RuleGroup{
+					{OrgID: 1, Folder: "a", Name: "a", Rules: []*AlertRule{rule("a"), rule("b")}},
+					{OrgID: 2, Folder: "a", Name: "a", Rules: []*AlertRule{rule("a")}},
+				},
+				AlertmanagerConfigs: []*AlertmanagerConfig{{OrgID: 1, ContactPoints: contactPoints, Policies: policies}},
+			},
+		},
+		{
+			desc: "rule group without folder",
+			file: File{Groups: []*RuleGroup{{OrgID: 1, Name: "a"}}},
+			err:  "rule groups require a folder and a name",
+		},
+		{
+			desc: "duplicated rule group",
+			file: File{Groups: []*RuleGroup{{OrgID: 1, Folder: "a", Name: "a"}, {OrgID: 1, Folder: "a", Name: "a"}}},
+			err:  `rule group "a" of folder "a" is defined more than once`,
+		},
+		{
+			desc: "alert rule without uid",
+			file: File{Groups: []*RuleGroup{{OrgID: 1, Folder: "a", Name: "a", Rules: []*AlertRule{rule("")}}}},
+			err:  `alert rule "" of rule group "a" requires a uid`,
+		},
+		{
+			desc: "duplicated alert rule uid",
+			file: File{Groups: []*RuleGroup{
+				{OrgID: 1, Folder: "a", Name: "a", Rules: []*AlertRule{rule("a")}},
+				{OrgID: 1, Folder: "a", Name: "b", Rules: []*AlertRule{rule("a")}},
+			}},
+			err: `alert rule uid "a" is used more than once`,
+		},
+		{
+			desc: "deleted rule group without name",
+			file: File{DeleteGroups: []*RuleGroupReference{{OrgID: 1, Folder: "a"}}},
+			err:  "deleted rule groups require a folder and a name",
+		},
+		{
+			desc: "duplicated Alertmanager configuration",
+			file: File{AlertmanagerConfigs: []*AlertmanagerConfig{
+				{OrgID: 1, ContactPoints: contactPoints, Policies: policies},
+				{OrgID: 1, ContactPoints: contactPoints, Policies: policies},
+			}},
+			err: "the Alertmanager configuration of organization 1 is defined more than once",
+		},
+		{
+			desc: "Alertmanager configuration with an undefined contact point",
+			file: File{AlertmanagerConfigs: []*AlertmanagerConfig{
+				{OrgID: 1, ContactPoints: contactPoints, Policies: &apimodels.Route{Receiver: "b"}},
+			}},
+			err: "invalid Alertmanager configuration of organization 1: unexpected receiver (b) is undefined",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := tc.file.Validate()
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestEncodeFile(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/alerting.yaml")
+	require.NoError(t, err)
+	f, err := ParseFile(data)
+	require.NoError(t, err)
+
+	encoded, err := EncodeFile(f)
+	require.NoError(t, err)
+	require.Contains(t, string(encoded), "apiVersion: 1\ngroups:\n")
+	require.Contains(t, string(encoded), "ops.tmpl: |\n")
+
+	decoded, err := ParseFile(encoded)
+	require.NoError(t, err)
+	reencoded, err := EncodeFile(decoded)
+	require.NoError(t, err)
+	require.Equal(t, string(encoded), string(reencoded))
+}
+
+func TestRuleGroupConversion(t *testing.T) {
+	dashboardUID := "dashboard"
+	rules := []*ngmodels.AlertRule{
+		{
+			UID:             "a",
+			Title:           "A",
+			Condition:       "B",
+			Data:            []ngmodels.AlertQuery{{RefID: "A", DatasourceUID: "prometheus", Model: []byte(`{}`)}},
+			IntervalSeconds: 60,
+			For:             5 * time.Minute,
+			NoDataState:     ngmodels.NoData,
+			ExecErrState:    ngmodels.AlertingErrState,
+			Annotations:     map[string]string{ngmodels.DashboardUIDAnnotation: dashboardUID},
+			Labels:          map[string]string{"team": "a"},
+			DashboardUID:    &dashboardUID,
+		},
+		{
+			UID:                  "b",
+			Title:                "B",
+			Data:                 []ngmodels.AlertQuery{{RefID: "A", DatasourceUID: "prometheus", Model: []byte(`{}`)}},
+			IntervalSeconds:      60,
+			IsPaused:             true,
+			Record:               "b:rate5m",
+			RecordTarget:         ngmodels.RecordTargetLive,
+			SuppressedByRuleUIDs: []string{"a"},
+		},
+	}
+
+	g := newRuleGroup(1, "folder", "group", rules)
+	require.Equal(t, model.Duration(time.Minute), g.Interval)
+	require.Equal(t, &SuppressedBy{RuleUIDs: []string{"a"}}, g.Rules[1].SuppressedBy)
+
+	cfg := g.RuleGroupConfig()
+	require.Equal(t, "group", cfg.Name)
+	require.Equal(t, model.Duration(time.Minute), cfg.Interval)
+	require.Len(t, cfg.Rules, 2)
+
+	a := cfg.Rules[0]
+	require.Equal(t, model.Duration(5*time.Minute), a.ApiRuleNode.For)
+	require.Equal(t, rules[0].Labels, a.ApiRuleNode.Labels)
+	require.Equal(t, rules[0].Annotations, a.ApiRuleNode.Annotations)
+	require.Equal(t, &apimodels.PostableGrafanaRule{
+		UID:          "a",
+		Title:        "A",
+		Condition:    "B",
+		Data:         rules[0].Data,
+		NoDataState:  apimodels.NoData,
+		ExecErrState: apimodels.ExecutionErrorState(ngmodels.AlertingErrState),
+	}, a.GrafanaManagedAlert)
+
+	b := cfg.Rules[1].GrafanaManagedAlert
+	require.True(t, b.IsPaused)
+	require.Equal(t, &apimodels.Record{Metric: "b:rate5m", Target: "live"}, b.Record)
+	require.Equal(t, &apimodels.SuppressedBy{RuleUIDs: []string{"a"}}, b.SuppressedBy)
+}
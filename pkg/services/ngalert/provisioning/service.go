@@ -0,0 +1,217 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/encryption"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Service provisions alerting resources from files and exports them to the same format.
+type Service struct {
+	ruleStore       store.RuleStore
+	alertingStore   store.AlertingStore
+	provenanceStore store.ProvisioningStore
+	sqlStore        *sqlstore.SQLStore
+	mam             *notifier.MultiOrgAlertmanager
+	enc             encryption.Service
+	log             log.Logger
+}
+
+func NewService(ruleStore store.RuleStore, alertingStore store.AlertingStore, provenanceStore store.ProvisioningStore,
+	sqlStore *sqlstore.SQLStore, mam *notifier.MultiOrgAlertmanager, enc encryption.Service, log log.Logger) *Service {
+	return &Service{
+		ruleStore:       ruleStore,
+		alertingStore:   alertingStore,
+		provenanceStore: provenanceStore,
+		sqlStore:        sqlStore,
+		mam:             mam,
+		enc:             enc,
+		log:             log,
+	}
+}
+
+// Apply deletes and then creates or updates the resources of the file, and marks them as
+// provisioned so that they cannot be changed through the API.
+func (s *Service) Apply(ctx context.Context, f *File) error {
+	if err := f.Validate(); err != nil {
+		return err
+	}
+
+	for _, ref := range f.DeleteGroups {
+		if err := s.deleteRuleGroup(ctx, ref); err != nil {
+			return fmt.Errorf("failed to delete rule group %q of folder %q: %w", ref.Name, ref.Folder, err)
+		}
+	}
+	for _, ref := range f.DeleteAlertmanagerConfigs {
+		if err := s.deleteAlertmanagerConfig(ref); err != nil {
+			return fmt.Errorf("failed to reset the Alertmanager configuration of organization %d: %w", ref.OrgID, err)
+		}
+	}
+	for _, g := range f.Groups {
+		if err := s.applyRuleGroup(ctx, g); err != nil {
+			return fmt.Errorf("failed to provision rule group %q of folder %q: %w", g.Name, g.Folder, err)
+		}
+	}
+	for _, c := range f.AlertmanagerConfigs {
+		if err := s.applyAlertmanagerConfig(c); err != nil {
+			return fmt.Errorf("failed to provision the Alertmanager configuration of organization %d: %w", c.OrgID, err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) folderService(orgID int64) dashboards.FolderService {
+	return dashboards.NewFolderService(orgID, &models.SignedInUser{OrgId: orgID, OrgRole: models.ROLE_ADMIN}, s.sqlStore)
+}
+
+func (s *Service) applyRuleGroup(ctx context.Context, g *RuleGroup) error {
+	folders := s.folderService(g.OrgID)
+	folder, err := folders.GetFolderByTitle(ctx, g.Folder)
+	if errors.Is(err, models.ErrFolderNotFound) {
+		s.log.Info("Creating folder of provisioned alert rules", "org", g.OrgID, "folder", g.Folder)
+		folder, err = folders.CreateFolder(ctx, g.Folder, "")
+	}
+	if err != nil {
+		return err
+	}
+
+	s.log.Debug("Provisioning rule group", "org", g.OrgID, "folder", g.Folder, "group", g.Name)
+	if err := s.ruleStore.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+		OrgID:           g.OrgID,
+		NamespaceUID:    folder.Uid,
+		RuleGroupConfig: g.RuleGroupConfig(),
+		KeepUIDs:        true,
+	}); err != nil {
+		return err
+	}
+
+	for _, r := range g.Rules {
+		if err := s.provenanceStore.SetProvenance(g.OrgID, ngmodels.ProvisionedAlertRule, r.UID, ngmodels.ProvenanceFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) deleteRuleGroup(ctx context.Context, ref *RuleGroupReference) error {
+	folder, err := s.folderService(ref.OrgID).GetFolderByTitle(ctx, ref.Folder)
+	if errors.Is(err, models.ErrFolderNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s.log.Debug("Deleting provisioned rule group", "org", ref.OrgID, "folder", ref.Folder, "group", ref.Name)
+	if _, err := s.ruleStore.DeleteRuleGroupAlertRules(ref.OrgID, folder.Uid, ref.Name); err != nil && !errors.Is(err, ngmodels.ErrRuleGroupNamespaceNotFound) {
+		return err
+	}
+	return nil
+}
+
+func (s *Service) applyAlertmanagerConfig(c *AlertmanagerConfig) error {
+	cfg, err := c.UserConfig()
+	if err != nil {
+		return err
+	}
+	if err := cfg.ProcessConfig(s.enc.Encrypt); err != nil {
+		return err
+	}
+
+	am, err := s.mam.AlertmanagerFor(c.OrgID)
+	if err != nil {
+		return err
+	}
+
+	s.log.Debug("Provisioning Alertmanager configuration", "org", c.OrgID)
+	if err := am.SaveAndApplyConfig(cfg); err != nil {
+		return err
+	}
+	return s.provenanceStore.SetProvenance(c.OrgID, ngmodels.ProvisionedAlertmanagerConfiguration, "", ngmodels.ProvenanceFile)
+}
+
+func (s *Service) deleteAlertmanagerConfig(ref *AlertmanagerConfigReference) error {
+	am, err := s.mam.AlertmanagerFor(ref.OrgID)
+	if err != nil {
+		return err
+	}
+
+	s.log.Debug("Resetting provisioned Alertmanager configuration", "org", ref.OrgID)
+	if err := am.SaveAndApplyDefaultConfig(); err != nil {
+		return err
+	}
+	return s.provenanceStore.SetProvenance(ref.OrgID, ngmodels.ProvisionedAlertmanagerConfiguration, "", ngmodels.ProvenanceNone)
+}
+
+// Export returns the rule groups and the Alertmanager configuration of an organization.
+// The secure settings of the contact points are only included, decrypted, if withSecrets
+// is true.
+func (s *Service) Export(ctx context.Context, orgID int64, withSecrets bool) (*File, error) {
+	f := &File{APIVersion: FileVersion}
+
+	groupsQuery := ngmodels.ListOrgRuleGroupsQuery{OrgID: orgID}
+	if err := s.ruleStore.GetOrgRuleGroups(&groupsQuery); err != nil {
+		return nil, err
+	}
+	for _, group := range groupsQuery.Result {
+		// each group is a list of the rule group name, the namespace UID and the namespace title
+		rulesQuery := ngmodels.ListRuleGroupAlertRulesQuery{OrgID: orgID, NamespaceUID: group[1], RuleGroup: group[0]}
+		if err := s.ruleStore.GetRuleGroupAlertRules(&rulesQuery); err != nil {
+			return nil, err
+		}
+		if len(rulesQuery.Result) == 0 {
+			continue
+		}
+		f.Groups = append(f.Groups, newRuleGroup(orgID, group[2], group[0], rulesQuery.Result))
+	}
+	sort.SliceStable(f.Groups, func(i, j int) bool {
+		if f.Groups[i].Folder != f.Groups[j].Folder {
+			return f.Groups[i].Folder < f.Groups[j].Folder
+		}
+		return f.Groups[i].Name < f.Groups[j].Name
+	})
+
+	amQuery := ngmodels.GetLatestAlertmanagerConfigurationQuery{OrgID: orgID}
+	err := s.alertingStore.GetLatestAlertmanagerConfiguration(&amQuery)
+	if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := notifier.Load([]byte(amQuery.Result.AlertmanagerConfiguration))
+	if err != nil {
+		return nil, err
+	}
+	for _, gr := range cfg.GetGrafanaReceiverMap() {
+		if !withSecrets {
+			gr.SecureSettings = nil
+			continue
+		}
+		for k, v := range gr.SecureSettings {
+			decoded, err := base64.StdEncoding.DecodeString(v)
+			if err != nil {
+				return nil, err
+			}
+			decrypted, err := s.enc.Decrypt(ctx, decoded, setting.SecretKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt secure setting %s of contact point %q: %w", k, gr.Name, err)
+			}
+			gr.SecureSettings[k] = string(decrypted)
+		}
+	}
+	f.AlertmanagerConfigs = append(f.AlertmanagerConfigs, newAlertmanagerConfig(orgID, cfg))
+	return f, nil
+}
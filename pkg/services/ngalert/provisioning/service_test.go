@@ -0,0 +1,79 @@
+//go:build integration
+// +build integration
+
+package provisioning_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+const baseIntervalSeconds = 10
+
+func TestServiceApply(t *testing.T) {
+	ng, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	s := provisioning.NewService(dbstore, dbstore, dbstore, ng.SQLStore, ng.MultiOrgAlertmanager, ossencryption.ProvideService(), log.New("ngalert.provisioning.test"))
+	ctx := context.Background()
+
+	const orgID int64 = 1
+	newFile := func(titles ...string) *provisioning.File {
+		g := &provisioning.RuleGroup{OrgID: orgID, Folder: "Provisioned", Name: "group", Interval: model.Duration(time.Minute)}
+		for i, title := range titles {
+			g.Rules = append(g.Rules, &provisioning.AlertRule{
+				UID:       []string{"rule-a", "rule-b"}[i],
+				Title:     title,
+				Condition: "A",
+				Data: []ngmodels.AlertQuery{{
+					RefID:         "A",
+					DatasourceUID: "-100",
+					Model:         []byte(`{"type": "math", "expression": "2 + 2 > 1"}`),
+				}},
+			})
+		}
+		return &provisioning.File{APIVersion: provisioning.FileVersion, Groups: []*provisioning.RuleGroup{g}}
+	}
+
+	getRules := func(t *testing.T) map[string]*ngmodels.AlertRule {
+		t.Helper()
+		q := ngmodels.ListAlertRulesQuery{OrgID: orgID}
+		require.NoError(t, dbstore.GetOrgAlertRules(&q))
+		rules := make(map[string]*ngmodels.AlertRule, len(q.Result))
+		for _, r := range q.Result {
+			rules[r.UID] = r
+		}
+		return rules
+	}
+
+	t.Run("rules that do not exist are created with the UID of the file", func(t *testing.T) {
+		require.NoError(t, s.Apply(ctx, newFile("rule A", "rule B")))
+
+		rules := getRules(t)
+		require.Len(t, rules, 2)
+		require.Equal(t, "rule A", rules["rule-a"].Title)
+		require.Equal(t, int64(1), rules["rule-a"].Version)
+		require.Equal(t, "rule B", rules["rule-b"].Title)
+
+		provenance, err := dbstore.GetProvenance(orgID, ngmodels.ProvisionedAlertRule, "rule-a")
+		require.NoError(t, err)
+		require.Equal(t, ngmodels.ProvenanceFile, provenance)
+	})
+
+	t.Run("applying the file again updates the rules and deletes the rules removed from the group", func(t *testing.T) {
+		require.NoError(t, s.Apply(ctx, newFile("rule A updated")))
+
+		rules := getRules(t)
+		require.Len(t, rules, 1)
+		require.Equal(t, "rule A updated", rules["rule-a"].Title)
+		require.Equal(t, int64(2), rules["rule-a"].Version)
+	})
+}
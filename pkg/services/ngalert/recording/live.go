@@ -0,0 +1,53 @@
+package recording
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/live"
+
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// LiveNamespace is the namespace of the Grafana Live stream channels that recording
+// rules publish their time series to.
+const LiveNamespace = "recording_rules"
+
+// StreamGetter gets the managed streams of Grafana Live.
+type StreamGetter interface {
+	GetOrCreateStream(orgID int64, scope string, namespace string) (*managedstream.NamespaceStream, error)
+}
+
+// LiveWriter publishes the time series of recording rules to the Grafana Live channel
+// stream/recording_rules/<metric>, where colons in the name of the metric are replaced
+// with underscores.
+type LiveWriter struct {
+	streams StreamGetter
+}
+
+func NewLiveWriter(streams StreamGetter) *LiveWriter {
+	return &LiveWriter{streams: streams}
+}
+
+func (w *LiveWriter) Write(_ context.Context, rule *models.AlertRule, now time.Time, frames data.Frames) error {
+	if w.streams == nil {
+		return errors.New("the live service is not available")
+	}
+
+	stream, err := w.streams.GetOrCreateStream(rule.OrgID, live.ScopeStream, LiveNamespace)
+	if err != nil {
+		return err
+	}
+
+	fields := append([]*data.Field{data.NewField("time", nil, []time.Time{now})}, toFields(rule, frames)...)
+	return stream.Push(LivePath(rule.Record), data.NewFrame(rule.Record, fields...))
+}
+
+// LivePath returns the path of the Grafana Live channel of the time series of a recording rule.
+func LivePath(record string) string {
+	return strings.ReplaceAll(record, ":", "_")
+}
@@ -0,0 +1,73 @@
+package recording
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// ErrRemoteWriteNotConfigured is returned when a recording rule writes to the remote
+// write endpoint, but it is not configured.
+var ErrRemoteWriteNotConfigured = errors.New("the remote write endpoint of recording rules is not configured")
+
+// RemoteWriteWriter writes the time series of recording rules to a Prometheus remote write endpoint.
+type RemoteWriteWriter struct {
+	url        string
+	user       string
+	password   string
+	httpClient *http.Client
+}
+
+func NewRemoteWriteWriter(cfg setting.UnifiedAlertingSettings) *RemoteWriteWriter {
+	return &RemoteWriteWriter{
+		url:        cfg.RecordingRulesRemoteWriteURL,
+		user:       cfg.RecordingRulesRemoteWriteUser,
+		password:   cfg.RecordingRulesRemoteWritePassword,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (w *RemoteWriteWriter) Write(ctx context.Context, rule *models.AlertRule, now time.Time, frames data.Frames) error {
+	if w.url == "" {
+		return ErrRemoteWriteNotConfigured
+	}
+
+	timeSeries := remotewrite.TimeSeriesFromFields(now, toFields(rule, frames)...)
+	if len(timeSeries) == 0 {
+		return nil
+	}
+	remoteWriteData, err := remotewrite.TimeSeriesToBytes(timeSeries)
+	if err != nil {
+		return fmt.Errorf("error converting time series to bytes: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(remoteWriteData))
+	if err != nil {
+		return fmt.Errorf("error constructing remote write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if w.user != "" || w.password != "" {
+		req.SetBasicAuth(w.user, w.password)
+	}
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending remote write request: %w", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected response code %d from remote write endpoint", resp.StatusCode)
+	}
+	return nil
+}
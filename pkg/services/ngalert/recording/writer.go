@@ -0,0 +1,72 @@
+package recording
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// Writer writes the time series of recording rules.
+type Writer interface {
+	// Write writes the series of the frames of the condition of the recording rule
+	// evaluated at now.
+	Write(ctx context.Context, rule *models.AlertRule, now time.Time, frames data.Frames) error
+}
+
+// TargetWriter writes the time series of each recording rule with the writer of its target.
+type TargetWriter struct {
+	remoteWrite Writer
+	live        Writer
+}
+
+func NewTargetWriter(remoteWrite, live Writer) *TargetWriter {
+	return &TargetWriter{
+		remoteWrite: remoteWrite,
+		live:        live,
+	}
+}
+
+func (w *TargetWriter) Write(ctx context.Context, rule *models.AlertRule, now time.Time, frames data.Frames) error {
+	switch rule.RecordTarget {
+	case models.RecordTargetRemoteWrite:
+		return w.remoteWrite.Write(ctx, rule, now, frames)
+	case models.RecordTargetLive:
+		return w.live.Write(ctx, rule, now, frames)
+	default:
+		return fmt.Errorf("unknown record target %q", rule.RecordTarget)
+	}
+}
+
+// toFields returns a field for each series of the frames, named after the time series of
+// the recording rule. The fields have the labels of the series and of the recording rule,
+// which take precedence. Frames that do not have a single number are skipped.
+func toFields(rule *models.AlertRule, frames data.Frames) []*data.Field {
+	fields := make([]*data.Field, 0, len(frames))
+	for _, frame := range frames {
+		if len(frame.Fields) != 1 || frame.Fields[0].Len() != 1 {
+			continue
+		}
+		f, err := frame.FloatAt(0, 0)
+		if err != nil {
+			continue
+		}
+		var v *float64
+		if _, ok := frame.Fields[0].ConcreteAt(0); ok {
+			v = &f
+		}
+
+		labels := frame.Fields[0].Labels.Copy()
+		if labels == nil {
+			labels = data.Labels{}
+		}
+		for name, value := range rule.Labels {
+			labels[name] = value
+		}
+		fields = append(fields, data.NewField(rule.Record, labels, []*float64{v}))
+	}
+	return fields
+}
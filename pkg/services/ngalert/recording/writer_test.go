@@ -0,0 +1,125 @@
+package recording
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeWriter struct {
+	rules []*models.AlertRule
+}
+
+func (w *fakeWriter) Write(_ context.Context, rule *models.AlertRule, _ time.Time, _ data.Frames) error {
+	w.rules = append(w.rules, rule)
+	return nil
+}
+
+func numberFrame(labels data.Labels, v *float64) *data.Frame {
+	return data.NewFrame("", data.NewField("", labels, []*float64{v}))
+}
+
+func TestToFields(t *testing.T) {
+	v1, v2 := 1.0, 2.0
+	rule := &models.AlertRule{
+		Record: "job:requests:rate5m",
+		Labels: map[string]string{"team": "a"},
+	}
+	frames := data.Frames{
+		numberFrame(data.Labels{"job": "api", "team": "b"}, &v1),
+		numberFrame(nil, &v2),
+		numberFrame(data.Labels{"job": "db"}, nil),
+		// frames that are not a single number are skipped
+		data.NewFrame("", data.NewField("time", nil, []time.Time{{}}), data.NewField("value", nil, []float64{3})),
+		data.NewFrame("", data.NewField("", nil, []*float64{&v1, &v2})),
+	}
+
+	fields := toFields(rule, frames)
+
+	require.Len(t, fields, 3)
+	require.Equal(t, data.NewField("job:requests:rate5m", data.Labels{"job": "api", "team": "a"}, []*float64{&v1}), fields[0])
+	require.Equal(t, data.NewField("job:requests:rate5m", data.Labels{"team": "a"}, []*float64{&v2}), fields[1])
+	require.Equal(t, data.NewField("job:requests:rate5m", data.Labels{"job": "db", "team": "a"}, []*float64{nil}), fields[2])
+}
+
+func TestTargetWriter(t *testing.T) {
+	remoteWrite, live := &fakeWriter{}, &fakeWriter{}
+	w := NewTargetWriter(remoteWrite, live)
+
+	remoteWriteRule := &models.AlertRule{Record: "a", RecordTarget: models.RecordTargetRemoteWrite}
+	liveRule := &models.AlertRule{Record: "b", RecordTarget: models.RecordTargetLive}
+	require.NoError(t, w.Write(context.Background(), remoteWriteRule, time.Now(), nil))
+	require.NoError(t, w.Write(context.Background(), liveRule, time.Now(), nil))
+	require.EqualError(t, w.Write(context.Background(), &models.AlertRule{Record: "c", RecordTarget: "kafka"}, time.Now(), nil), `unknown record target "kafka"`)
+
+	require.Equal(t, []*models.AlertRule{remoteWriteRule}, remoteWrite.rules)
+	require.Equal(t, []*models.AlertRule{liveRule}, live.rules)
+}
+
+func TestRemoteWriteWriter(t *testing.T) {
+	now := time.Unix(1634000000, 0)
+	v := 42.0
+	rule := &models.AlertRule{
+		Record:       "job:requests:rate5m",
+		RecordTarget: models.RecordTargetRemoteWrite,
+	}
+	frames := data.Frames{numberFrame(data.Labels{"job": "api"}, &v)}
+
+	t.Run("not configured", func(t *testing.T) {
+		w := NewRemoteWriteWriter(setting.UnifiedAlertingSettings{})
+		require.ErrorIs(t, w.Write(context.Background(), rule, now, frames), ErrRemoteWriteNotConfigured)
+	})
+
+	t.Run("writes the time series", func(t *testing.T) {
+		var received prompb.WriteRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, password, ok := r.BasicAuth()
+			require.True(t, ok)
+			require.Equal(t, "user", user)
+			require.Equal(t, "password", password)
+			require.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+
+			compressed, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			b, err := snappy.Decode(nil, compressed)
+			require.NoError(t, err)
+			require.NoError(t, proto.Unmarshal(b, &received))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		w := NewRemoteWriteWriter(setting.UnifiedAlertingSettings{
+			RecordingRulesRemoteWriteURL:      server.URL,
+			RecordingRulesRemoteWriteUser:     "user",
+			RecordingRulesRemoteWritePassword: "password",
+		})
+		require.NoError(t, w.Write(context.Background(), rule, now, frames))
+
+		require.Equal(t, []prompb.TimeSeries{{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "job:requests:rate5m"}, {Name: "job", Value: "api"}},
+			Samples: []prompb.Sample{{Timestamp: now.UnixNano() / int64(time.Millisecond), Value: 42}},
+		}}, received.Timeseries)
+	})
+
+	t.Run("fails on unexpected response code", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		w := NewRemoteWriteWriter(setting.UnifiedAlertingSettings{RecordingRulesRemoteWriteURL: server.URL})
+		require.EqualError(t, w.Write(context.Background(), rule, now, frames), "unexpected response code 400 from remote write endpoint")
+	})
+}
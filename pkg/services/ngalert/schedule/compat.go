@@ -10,7 +10,9 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/common/model"
 
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 )
@@ -21,39 +23,94 @@ func FromAlertStateToPostableAlerts(firingStates []*state.State, stateManager *s
 	ts := time.Now()
 
 	for _, alertState := range firingStates {
-		if !alertState.NeedsSending(stateManager.ResendDelay) {
-			continue
+		sent := false
+		if alertState.NeedsResolvingError() {
+			// the alert for the failed evaluation is resolved with the labels it was sent with,
+			// as the alert instance is not sent with these labels anymore
+			alerts.PostableAlerts = append(alerts.PostableAlerts, resolvedErrorAlert(alertState, appURL))
+			alertState.LastSentAsError = false
+			sent = true
 		}
-		nL := alertState.Labels.Copy()
-		nA := data.Labels(alertState.Annotations).Copy()
+		if alertState.NeedsSending(stateManager.ResendDelay) {
+			alerts.PostableAlerts = append(alerts.PostableAlerts, stateToPostableAlert(alertState, appURL))
+			alertState.LastSentAt = ts
+			alertState.LastSentAsError = alertState.State == eval.Error
+			alertState.LastSentAsFiring = alertState.State == eval.Alerting && alertState.SuppressedBy == ""
+			sent = true
+		}
+		if sent {
+			sentAlerts = append(sentAlerts, alertState)
+		}
+	}
+	stateManager.Put(sentAlerts)
+	return alerts
+}
 
-		if len(alertState.Results) > 0 {
-			nA["__value_string__"] = alertState.Results[0].EvaluationString
+func stateToPostableAlert(alertState *state.State, appURL *url.URL) models.PostableAlert {
+	nL := alertState.Labels.Copy()
+	nA := data.Labels(alertState.Annotations).Copy()
+
+	if len(alertState.Results) > 0 {
+		nA["__value_string__"] = alertState.Results[0].EvaluationString
+	}
+
+	if alertState.Image != nil {
+		nA[ngModels.ImageTokenAnnotation] = alertState.Image.Token
+		if alertState.Image.HasURL() {
+			nA[ngModels.ImageURLAnnotation] = alertState.Image.URL
 		}
+	}
 
-		var urlStr string
-		if uid := nL[ngModels.RuleUIDLabel]; len(uid) > 0 && appURL != nil {
-			u := *appURL
-			u.Path = path.Join(u.Path, fmt.Sprintf("/alerting/%s/edit", uid))
-			urlStr = u.String()
-		} else if appURL != nil {
-			urlStr = appURL.String()
-		} else {
-			urlStr = ""
+	if alertState.State == eval.Error {
+		nL = errorAlertLabels(nL)
+		if alertState.Error != nil {
+			nA["Error"] = alertState.Error.Error()
 		}
+	}
 
-		alerts.PostableAlerts = append(alerts.PostableAlerts, models.PostableAlert{
-			Annotations: models.LabelSet(nA),
-			StartsAt:    strfmt.DateTime(alertState.StartsAt),
-			EndsAt:      strfmt.DateTime(alertState.EndsAt),
-			Alert: models.Alert{
-				Labels:       models.LabelSet(nL),
-				GeneratorURL: strfmt.URI(urlStr),
-			},
-		})
-		alertState.LastSentAt = ts
-		sentAlerts = append(sentAlerts, alertState)
+	return models.PostableAlert{
+		Annotations: models.LabelSet(nA),
+		StartsAt:    strfmt.DateTime(alertState.StartsAt),
+		EndsAt:      strfmt.DateTime(alertState.EndsAt),
+		Alert: models.Alert{
+			Labels:       models.LabelSet(nL),
+			GeneratorURL: strfmt.URI(generatorURL(nL, appURL)),
+		},
 	}
-	stateManager.Put(sentAlerts)
-	return alerts
+}
+
+// resolvedErrorAlert returns the alert that resolves the alert sent for a failed evaluation
+// of the alert instance. The Alertmanager keeps the start time of the alert it already has.
+func resolvedErrorAlert(alertState *state.State, appURL *url.URL) models.PostableAlert {
+	nL := errorAlertLabels(alertState.Labels.Copy())
+	return models.PostableAlert{
+		Annotations: models.LabelSet(data.Labels(alertState.Annotations).Copy()),
+		StartsAt:    strfmt.DateTime(alertState.LastEvaluationTime),
+		EndsAt:      strfmt.DateTime(alertState.LastEvaluationTime),
+		Alert: models.Alert{
+			Labels:       models.LabelSet(nL),
+			GeneratorURL: strfmt.URI(generatorURL(nL, appURL)),
+		},
+	}
+}
+
+// errorAlertLabels changes the labels of an alert instance into the labels of the alert
+// sent for its failed evaluations. These alerts have their own name, so they can be routed
+// separately from the alerts of the alert rule.
+func errorAlertLabels(labels data.Labels) data.Labels {
+	labels[ngModels.RuleNameLabel] = labels[model.AlertNameLabel]
+	labels[model.AlertNameLabel] = ngModels.ErrorAlertName
+	return labels
+}
+
+func generatorURL(labels data.Labels, appURL *url.URL) string {
+	if appURL == nil {
+		return ""
+	}
+	if uid := labels[ngModels.RuleUIDLabel]; len(uid) > 0 {
+		u := *appURL
+		u.Path = path.Join(u.Path, fmt.Sprintf("/alerting/%s/edit", uid))
+		return u.String()
+	}
+	return appURL.String()
 }
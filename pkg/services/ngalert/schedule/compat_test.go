@@ -0,0 +1,91 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestFromAlertStateToPostableAlerts_ErrorAlerts(t *testing.T) {
+	m := metrics.NewNGAlert(prometheus.NewPedanticRegistry())
+	st := state.NewManager(log.New("test"), m.GetStateMetrics(), nil, nil, nil, nil, nil)
+	t.Cleanup(st.Close)
+
+	evaluationTime := time.Unix(1000, 0)
+	newState := func(s eval.State, at time.Time) *state.State {
+		return &state.State{
+			AlertRuleUID:       "rule",
+			OrgID:              1,
+			CacheId:            "test",
+			State:              s,
+			Labels:             data.Labels{"alertname": "test", ngModels.RuleUIDLabel: "rule"},
+			StartsAt:           at,
+			EndsAt:             at.Add(time.Minute),
+			LastEvaluationTime: at,
+		}
+	}
+
+	testCases := []struct {
+		desc     string
+		next     eval.State
+		expNames []string
+	}{
+		{
+			desc:     "error alerts are resolved with the labels they were sent with",
+			next:     eval.Normal,
+			expNames: []string{ngModels.ErrorAlertName},
+		},
+		{
+			desc:     "error alerts are resolved when the alert instance fires",
+			next:     eval.Alerting,
+			expNames: []string{ngModels.ErrorAlertName, "test"},
+		},
+		{
+			desc:     "error alerts are resolved when the alert instance is pending",
+			next:     eval.Pending,
+			expNames: []string{ngModels.ErrorAlertName},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := newState(eval.Error, evaluationTime)
+			s.Error = errors.New("failed")
+			alerts := FromAlertStateToPostableAlerts([]*state.State{s}, st, nil)
+			require.Len(t, alerts.PostableAlerts, 1)
+			require.Equal(t, ngModels.ErrorAlertName, alerts.PostableAlerts[0].Labels["alertname"])
+			require.Equal(t, "test", alerts.PostableAlerts[0].Labels[ngModels.RuleNameLabel])
+			require.True(t, s.LastSentAsError)
+
+			next := newState(tc.next, evaluationTime.Add(time.Minute))
+			next.LastSentAsError = s.LastSentAsError
+			alerts = FromAlertStateToPostableAlerts([]*state.State{next}, st, nil)
+			var names []string
+			for _, a := range alerts.PostableAlerts {
+				names = append(names, a.Labels["alertname"])
+			}
+			require.Equal(t, tc.expNames, names)
+			resolved := alerts.PostableAlerts[0]
+			require.Equal(t, "test", resolved.Labels[ngModels.RuleNameLabel])
+			require.Equal(t, strfmt.DateTime(next.LastEvaluationTime), resolved.EndsAt)
+			require.False(t, next.LastSentAsError)
+
+			// the error alert is resolved only once
+			alerts = FromAlertStateToPostableAlerts([]*state.State{next}, st, nil)
+			for _, a := range alerts.PostableAlerts {
+				require.NotEqual(t, ngModels.ErrorAlertName, a.Labels["alertname"])
+			}
+		})
+	}
+}
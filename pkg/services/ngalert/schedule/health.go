@@ -0,0 +1,149 @@
+package schedule
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// evaluationStatus is the outcome of an evaluation of an alert rule.
+type evaluationStatus struct {
+	health   models.RuleHealth
+	err      error
+	duration time.Duration
+}
+
+// resultsStatus returns the status of an evaluation from its results. The evaluation is
+// unhealthy if any of the results is an error, and has no data if all the results have
+// no data.
+func resultsStatus(results eval.Results, duration time.Duration) evaluationStatus {
+	status := evaluationStatus{health: models.RuleHealthOK, duration: duration}
+	noData := len(results) > 0
+	for _, r := range results {
+		if r.State == eval.Error {
+			status.health = models.RuleHealthError
+			status.err = r.Error
+			return status
+		}
+		if r.State != eval.NoData {
+			noData = false
+		}
+	}
+	if noData {
+		status.health = models.RuleHealthNoData
+	}
+	return status
+}
+
+// ruleHealthRegistry keeps the health of the last evaluation of the alert rules evaluated
+// by this instance, and exposes it as metrics by rule group.
+type ruleHealthRegistry struct {
+	mu      sync.RWMutex
+	health  map[models.AlertRuleKey]models.AlertRuleHealth
+	metrics *metrics.Scheduler
+}
+
+func newRuleHealthRegistry(m *metrics.Scheduler) *ruleHealthRegistry {
+	return &ruleHealthRegistry{
+		health:  make(map[models.AlertRuleKey]models.AlertRuleHealth),
+		metrics: m,
+	}
+}
+
+// update records the status of the last evaluation of the alert rule after the given
+// number of attempts.
+func (r *ruleHealthRegistry) update(alertRule *models.AlertRule, now time.Time, attempts int64, status evaluationStatus) {
+	key := alertRule.GetKey()
+	org := fmt.Sprint(alertRule.OrgID)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, exists := r.health[key]
+	h := models.AlertRuleHealth{
+		OrgID:              alertRule.OrgID,
+		RuleUID:            alertRule.UID,
+		NamespaceUID:       alertRule.NamespaceUID,
+		RuleGroup:          alertRule.RuleGroup,
+		Health:             status.health,
+		LastEvaluation:     now,
+		EvaluationDuration: status.duration,
+		Attempts:           attempts,
+	}
+	if status.health == models.RuleHealthError {
+		if status.err != nil {
+			h.LastError = status.err.Error()
+			h.LastErrorRefID = eval.ErrorRefID(status.err)
+		}
+		h.ConsecutiveFailures = 1
+		h.FailingSince = now
+		if exists && previous.Health == models.RuleHealthError {
+			h.ConsecutiveFailures = previous.ConsecutiveFailures + 1
+			h.FailingSince = previous.FailingSince
+		}
+	}
+	r.health[key] = h
+
+	r.metrics.GroupEvalDuration.WithLabelValues(org, h.NamespaceUID, h.RuleGroup).Observe(status.duration.Seconds())
+	r.metrics.GroupEvalAttempts.WithLabelValues(org, h.NamespaceUID, h.RuleGroup).Add(float64(attempts))
+	if h.Health == models.RuleHealthError {
+		r.metrics.GroupEvalFailures.WithLabelValues(org, h.NamespaceUID, h.RuleGroup).Inc()
+	}
+	if exists {
+		r.metrics.GroupRulesHealth.WithLabelValues(org, previous.NamespaceUID, previous.RuleGroup, string(previous.Health)).Dec()
+		if previous.NamespaceUID != h.NamespaceUID || previous.RuleGroup != h.RuleGroup {
+			r.deleteGroupMetrics(previous)
+		}
+	}
+	r.metrics.GroupRulesHealth.WithLabelValues(org, h.NamespaceUID, h.RuleGroup, string(h.Health)).Inc()
+}
+
+// delete removes the health of an alert rule that is not evaluated anymore.
+func (r *ruleHealthRegistry) delete(key models.AlertRuleKey) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, exists := r.health[key]
+	if !exists {
+		return
+	}
+	delete(r.health, key)
+	r.metrics.GroupRulesHealth.WithLabelValues(fmt.Sprint(key.OrgID), previous.NamespaceUID, previous.RuleGroup, string(previous.Health)).Dec()
+	r.deleteGroupMetrics(previous)
+}
+
+// deleteGroupMetrics deletes the metrics of the rule group of h if this instance does not
+// evaluate any alert rule of the group anymore, so deleted or renamed groups do not leave
+// stale series. The lock must be held.
+func (r *ruleHealthRegistry) deleteGroupMetrics(h models.AlertRuleHealth) {
+	for _, other := range r.health {
+		if other.OrgID == h.OrgID && other.NamespaceUID == h.NamespaceUID && other.RuleGroup == h.RuleGroup {
+			return
+		}
+	}
+	org := fmt.Sprint(h.OrgID)
+	r.metrics.GroupEvalDuration.DeleteLabelValues(org, h.NamespaceUID, h.RuleGroup)
+	r.metrics.GroupEvalAttempts.DeleteLabelValues(org, h.NamespaceUID, h.RuleGroup)
+	r.metrics.GroupEvalFailures.DeleteLabelValues(org, h.NamespaceUID, h.RuleGroup)
+	for _, health := range []models.RuleHealth{models.RuleHealthOK, models.RuleHealthError, models.RuleHealthNoData} {
+		r.metrics.GroupRulesHealth.DeleteLabelValues(org, h.NamespaceUID, h.RuleGroup, string(health))
+	}
+}
+
+// list returns the health of the alert rules of an organization by UID.
+func (r *ruleHealthRegistry) list(orgID int64) map[string]models.AlertRuleHealth {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[string]models.AlertRuleHealth)
+	for key, h := range r.health {
+		if key.OrgID == orgID {
+			result[key.UID] = h
+		}
+	}
+	return result
+}
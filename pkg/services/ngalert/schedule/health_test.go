@@ -0,0 +1,119 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestResultsStatus(t *testing.T) {
+	evalErr := errors.New("invalid format")
+	cases := []struct {
+		desc     string
+		results  eval.Results
+		expected evaluationStatus
+	}{
+		{
+			desc:     "normal and alerting results are healthy",
+			results:  eval.Results{{State: eval.Normal}, {State: eval.Alerting}},
+			expected: evaluationStatus{health: models.RuleHealthOK, duration: time.Second},
+		},
+		{
+			desc:     "any error result is unhealthy",
+			results:  eval.Results{{State: eval.Normal}, {State: eval.Error, Error: evalErr}},
+			expected: evaluationStatus{health: models.RuleHealthError, err: evalErr, duration: time.Second},
+		},
+		{
+			desc:     "only no data results have no data",
+			results:  eval.Results{{State: eval.NoData}, {State: eval.NoData}},
+			expected: evaluationStatus{health: models.RuleHealthNoData, duration: time.Second},
+		},
+		{
+			desc:     "some no data results are healthy",
+			results:  eval.Results{{State: eval.NoData}, {State: eval.Normal}},
+			expected: evaluationStatus{health: models.RuleHealthOK, duration: time.Second},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.Equal(t, tc.expected, resultsStatus(tc.results, time.Second))
+		})
+	}
+}
+
+func TestRuleHealthRegistry(t *testing.T) {
+	m := metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetSchedulerMetrics()
+	r := newRuleHealthRegistry(m)
+
+	rule := &models.AlertRule{OrgID: 1, UID: "a", NamespaceUID: "folder", RuleGroup: "group"}
+	other := &models.AlertRule{OrgID: 2, UID: "b", NamespaceUID: "folder", RuleGroup: "group"}
+	healthGauge := func(health models.RuleHealth) float64 {
+		return testutil.ToFloat64(m.GroupRulesHealth.WithLabelValues("1", "folder", "group", string(health)))
+	}
+
+	start := time.Unix(0, 0)
+	r.update(rule, start, 1, evaluationStatus{health: models.RuleHealthOK, duration: time.Second})
+	r.update(other, start, 1, evaluationStatus{health: models.RuleHealthOK, duration: time.Second})
+	require.Equal(t, float64(1), healthGauge(models.RuleHealthOK))
+
+	queryErr := fmt.Errorf("queries and expressions execution failed: %w", &expr.NodeError{RefID: "A", Err: errors.New("connection refused")})
+	for i := 1; i <= 3; i++ {
+		r.update(rule, start.Add(time.Duration(i)*time.Minute), 3, evaluationStatus{health: models.RuleHealthError, err: queryErr, duration: 2 * time.Second})
+	}
+
+	health := r.list(1)
+	require.Len(t, health, 1)
+	require.Equal(t, models.AlertRuleHealth{
+		OrgID:               1,
+		RuleUID:             "a",
+		NamespaceUID:        "folder",
+		RuleGroup:           "group",
+		Health:              models.RuleHealthError,
+		LastEvaluation:      start.Add(3 * time.Minute),
+		EvaluationDuration:  2 * time.Second,
+		LastError:           "queries and expressions execution failed: connection refused",
+		LastErrorRefID:      "A",
+		Attempts:            3,
+		ConsecutiveFailures: 3,
+		FailingSince:        start.Add(time.Minute),
+	}, health["a"])
+	require.Equal(t, float64(0), healthGauge(models.RuleHealthOK))
+	require.Equal(t, float64(1), healthGauge(models.RuleHealthError))
+	require.Equal(t, float64(3), testutil.ToFloat64(m.GroupEvalFailures.WithLabelValues("1", "folder", "group")))
+	require.Equal(t, float64(10), testutil.ToFloat64(m.GroupEvalAttempts.WithLabelValues("1", "folder", "group")))
+
+	// a successful evaluation resets the failures
+	r.update(rule, start.Add(4*time.Minute), 1, evaluationStatus{health: models.RuleHealthOK, duration: time.Second})
+	h := r.list(1)["a"]
+	require.Equal(t, models.RuleHealthOK, h.Health)
+	require.Empty(t, h.LastError)
+	require.Zero(t, h.ConsecutiveFailures)
+	require.True(t, h.FailingSince.IsZero())
+
+	r.delete(rule.GetKey())
+	require.Empty(t, r.list(1))
+	require.Len(t, r.list(2), 1)
+	// the series of the group of the deleted rule are deleted
+	require.Equal(t, 1, testutil.CollectAndCount(m.GroupEvalAttempts))
+	require.Zero(t, testutil.CollectAndCount(m.GroupEvalFailures))
+	require.Equal(t, 1, testutil.CollectAndCount(m.GroupRulesHealth))
+
+	// the series of the previous group of a moved rule are deleted
+	moved := &models.AlertRule{OrgID: 2, UID: "b", NamespaceUID: "folder", RuleGroup: "renamed"}
+	r.update(moved, start, 1, evaluationStatus{health: models.RuleHealthOK, duration: time.Second})
+	require.Equal(t, 1, testutil.CollectAndCount(m.GroupEvalAttempts))
+	require.Equal(t, 1, testutil.CollectAndCount(m.GroupEvalDuration))
+	require.Equal(t, 1, testutil.CollectAndCount(m.GroupRulesHealth))
+	require.Equal(t, float64(1), testutil.ToFloat64(m.GroupRulesHealth.WithLabelValues("2", "folder", "renamed", string(models.RuleHealthOK))))
+}
@@ -0,0 +1,133 @@
+package schedule
+
+import (
+	"context"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// skippedEvaluationRunning is the reason of evaluations skipped because the previous
+	// evaluation of the alert rule was still running.
+	skippedEvaluationRunning = "evaluation_running"
+	// skippedConcurrencyLimit is the reason of evaluations skipped because they waited
+	// for the concurrency limits longer than the interval of the alert rule.
+	skippedConcurrencyLimit = "concurrency_limit"
+)
+
+// evaluationLimiter limits the number of alert rules evaluated at the same time, in
+// total, per organization and per data source. A limit of zero means no limit.
+type evaluationLimiter struct {
+	maxPerOrg        int
+	maxPerDatasource int
+
+	global chan struct{}
+
+	mtx   sync.Mutex
+	slots map[slotKey]chan struct{}
+}
+
+// slotKey identifies the slots of an organization, or of a data source of the
+// organization if datasourceUID is not empty.
+type slotKey struct {
+	orgID         int64
+	datasourceUID string
+}
+
+func newEvaluationLimiter(maxTotal, maxPerOrg, maxPerDatasource int) *evaluationLimiter {
+	l := &evaluationLimiter{
+		maxPerOrg:        maxPerOrg,
+		maxPerDatasource: maxPerDatasource,
+		slots:            make(map[slotKey]chan struct{}),
+	}
+	if maxTotal > 0 {
+		l.global = make(chan struct{}, maxTotal)
+	}
+	return l
+}
+
+// acquire waits until the alert rule can be evaluated within the limits, and returns a
+// function that must be called once the evaluation is done. If the context is done
+// first, it returns the error of the context. Slots are always acquired in the same
+// order, global, organization and then data sources by UID, so that evaluations
+// waiting for each other cannot deadlock.
+func (l *evaluationLimiter) acquire(ctx context.Context, alertRule *models.AlertRule) (func(), error) {
+	slots := make([]chan struct{}, 0, len(alertRule.Data)+2)
+	if l.global != nil {
+		slots = append(slots, l.global)
+	}
+
+	l.mtx.Lock()
+	if l.maxPerOrg > 0 {
+		slots = append(slots, l.slot(slotKey{orgID: alertRule.OrgID}, l.maxPerOrg))
+	}
+	if l.maxPerDatasource > 0 {
+		for _, uid := range datasourceUIDs(alertRule) {
+			slots = append(slots, l.slot(slotKey{orgID: alertRule.OrgID, datasourceUID: uid}, l.maxPerDatasource))
+		}
+	}
+	l.mtx.Unlock()
+
+	release := func(acquired []chan struct{}) {
+		for i := len(acquired) - 1; i >= 0; i-- {
+			<-acquired[i]
+		}
+	}
+	for i, slot := range slots {
+		select {
+		case slot <- struct{}{}:
+		case <-ctx.Done():
+			release(slots[:i])
+			return nil, ctx.Err()
+		}
+	}
+	return func() { release(slots) }, nil
+}
+
+// slot returns the slots of the key, creating them if needed. It must be called with
+// the mutex held.
+func (l *evaluationLimiter) slot(key slotKey, max int) chan struct{} {
+	slot, ok := l.slots[key]
+	if !ok {
+		slot = make(chan struct{}, max)
+		l.slots[key] = slot
+	}
+	return slot
+}
+
+// datasourceUIDs returns the sorted UIDs of the data sources queried by the alert rule,
+// without the expressions.
+func datasourceUIDs(alertRule *models.AlertRule) []string {
+	uids := make([]string, 0, len(alertRule.Data))
+	seen := make(map[string]struct{}, len(alertRule.Data))
+	for i := range alertRule.Data {
+		q := &alertRule.Data[i]
+		if isExpression, err := q.IsExpression(); err == nil && isExpression {
+			continue
+		}
+		if _, ok := seen[q.DatasourceUID]; ok {
+			continue
+		}
+		seen[q.DatasourceUID] = struct{}{}
+		uids = append(uids, q.DatasourceUID)
+	}
+	sort.Strings(uids)
+	return uids
+}
+
+// jitterOffset returns the offset of the evaluations of the alert rule within its
+// interval. It is derived from the UID of the alert rule, so that alert rules with the
+// same interval are evaluated at different times, and each alert rule is always
+// evaluated at the same point of its interval.
+func jitterOffset(key models.AlertRuleKey, interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key.UID))
+	return time.Duration(h.Sum64() % uint64(interval))
+}
@@ -0,0 +1,121 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func testRuleWithDatasources(orgID int64, uids ...string) *models.AlertRule {
+	rule := &models.AlertRule{OrgID: orgID}
+	for _, uid := range uids {
+		rule.Data = append(rule.Data, models.AlertQuery{DatasourceUID: uid})
+	}
+	return rule
+}
+
+func TestEvaluationLimiter(t *testing.T) {
+	// tryAcquire acquires the slots of the alert rule if they are available right away
+	tryAcquire := func(t *testing.T, l *evaluationLimiter, rule *models.AlertRule) (func(), bool) {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		release, err := l.acquire(ctx, rule)
+		if err != nil {
+			require.ErrorIs(t, err, context.DeadlineExceeded)
+			return nil, false
+		}
+		return release, true
+	}
+
+	t.Run("no limits", func(t *testing.T) {
+		l := newEvaluationLimiter(0, 0, 0)
+		for i := 0; i < 100; i++ {
+			_, ok := tryAcquire(t, l, testRuleWithDatasources(1, "a"))
+			require.True(t, ok)
+		}
+	})
+
+	t.Run("global limit", func(t *testing.T) {
+		l := newEvaluationLimiter(2, 0, 0)
+		release, ok := tryAcquire(t, l, testRuleWithDatasources(1, "a"))
+		require.True(t, ok)
+		_, ok = tryAcquire(t, l, testRuleWithDatasources(2, "b"))
+		require.True(t, ok)
+		_, ok = tryAcquire(t, l, testRuleWithDatasources(3, "c"))
+		require.False(t, ok)
+
+		release()
+		_, ok = tryAcquire(t, l, testRuleWithDatasources(3, "c"))
+		require.True(t, ok)
+	})
+
+	t.Run("limit per organization", func(t *testing.T) {
+		l := newEvaluationLimiter(0, 1, 0)
+		release, ok := tryAcquire(t, l, testRuleWithDatasources(1, "a"))
+		require.True(t, ok)
+		_, ok = tryAcquire(t, l, testRuleWithDatasources(1, "b"))
+		require.False(t, ok)
+		_, ok = tryAcquire(t, l, testRuleWithDatasources(2, "a"))
+		require.True(t, ok)
+
+		release()
+		_, ok = tryAcquire(t, l, testRuleWithDatasources(1, "b"))
+		require.True(t, ok)
+	})
+
+	t.Run("limit per data source", func(t *testing.T) {
+		l := newEvaluationLimiter(0, 0, 1)
+		release, ok := tryAcquire(t, l, testRuleWithDatasources(1, "a", "b"))
+		require.True(t, ok)
+		_, ok = tryAcquire(t, l, testRuleWithDatasources(1, "b"))
+		require.False(t, ok)
+		// the same data source UID in another organization is another data source
+		_, ok = tryAcquire(t, l, testRuleWithDatasources(2, "b"))
+		require.True(t, ok)
+		// expressions are not limited
+		_, ok = tryAcquire(t, l, testRuleWithDatasources(1, "c", expr.DatasourceUID))
+		require.True(t, ok)
+		_, ok = tryAcquire(t, l, testRuleWithDatasources(1, "d", expr.DatasourceUID))
+		require.True(t, ok)
+
+		release()
+		_, ok = tryAcquire(t, l, testRuleWithDatasources(1, "b"))
+		require.True(t, ok)
+	})
+
+	t.Run("slots acquired before the context is done are released", func(t *testing.T) {
+		l := newEvaluationLimiter(2, 1, 0)
+		_, ok := tryAcquire(t, l, testRuleWithDatasources(1, "a"))
+		require.True(t, ok)
+		// acquires a global slot, but waits for the slot of the organization
+		_, ok = tryAcquire(t, l, testRuleWithDatasources(1, "a"))
+		require.False(t, ok)
+		_, ok = tryAcquire(t, l, testRuleWithDatasources(2, "a"))
+		require.True(t, ok)
+	})
+}
+
+func TestDatasourceUIDs(t *testing.T) {
+	rule := testRuleWithDatasources(1, "b", expr.DatasourceUID, "a", "b")
+	require.Equal(t, []string{"a", "b"}, datasourceUIDs(rule))
+}
+
+func TestJitterOffset(t *testing.T) {
+	interval := time.Minute
+	offsets := make(map[time.Duration]struct{})
+	for _, key := range testRuleKeys(100) {
+		offset := jitterOffset(key, interval)
+		require.GreaterOrEqual(t, offset, time.Duration(0))
+		require.Less(t, offset, interval)
+		require.Equal(t, offset, jitterOffset(key, interval), "the offset of an alert rule is deterministic")
+		offsets[offset] = struct{}{}
+	}
+	require.Greater(t, len(offsets), 90, "the alert rules are spread over the interval")
+	require.Equal(t, time.Duration(0), jitterOffset(models.AlertRuleKey{UID: "a"}, 0))
+}
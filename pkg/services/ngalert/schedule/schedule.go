@@ -13,6 +13,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/recording"
 	"github.com/grafana/grafana/pkg/services/ngalert/sender"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
@@ -32,6 +33,9 @@ type ScheduleService interface {
 	Unpause() error
 	AlertmanagersFor(orgID int64) []*url.URL
 	DroppedAlertmanagersFor(orgID int64) []*url.URL
+	// RuleHealth returns the health of the last evaluation of the alert rules of the
+	// organization evaluated by this instance, by UID.
+	RuleHealth(orgID int64) map[string]models.AlertRuleHealth
 
 	// the following are used by tests only used for tests
 	evalApplied(models.AlertRuleKey, time.Time)
@@ -86,6 +90,24 @@ type schedule struct {
 	adminConfigPollInterval time.Duration
 	disabledOrgs            map[int64]struct{}
 	minRuleInterval         time.Duration
+
+	// sharder partitions the alert rules between the instances of a high
+	// availability cluster. If nil, this instance evaluates all alert rules.
+	sharder *ruleSharder
+	// notEvaluated are the alert rules this instance did not evaluate on the last tick,
+	// so that their states are cleared only when they stop being evaluated here.
+	notEvaluated map[models.AlertRuleKey]struct{}
+
+	// recordingWriter writes the time series of recording rules.
+	recordingWriter recording.Writer
+
+	// limiter limits the number of alert rules evaluated at the same time.
+	limiter *evaluationLimiter
+	// evaluationJitter spreads the evaluations of alert rules over their interval.
+	evaluationJitter bool
+
+	// health keeps the health of the last evaluation of each alert rule.
+	health *ruleHealthRegistry
 }
 
 // SchedulerCfg is the scheduler configuration.
@@ -106,6 +128,22 @@ type SchedulerCfg struct {
 	AdminConfigPollInterval time.Duration
 	DisabledOrgs            map[int64]struct{}
 	MinRuleInterval         time.Duration
+	// ClusterMembership, if set, enables sharding the evaluation of alert rules
+	// across the members of the cluster.
+	ClusterMembership ClusterMembership
+	// RecordingWriter writes the time series of recording rules. If nil, the
+	// time series of recording rules are not written.
+	RecordingWriter recording.Writer
+	// EvaluationJitter evaluates each alert rule with an offset within its interval
+	// derived from its UID, instead of evaluating all the alert rules with the same
+	// interval at the same tick.
+	EvaluationJitter bool
+	// MaxConcurrentEvaluations, MaxConcurrentEvaluationsPerOrg and
+	// MaxConcurrentEvaluationsPerDatasource limit the number of alert rules evaluated
+	// at the same time. Zero means no limit.
+	MaxConcurrentEvaluations              int
+	MaxConcurrentEvaluationsPerOrg        int
+	MaxConcurrentEvaluationsPerDatasource int
 }
 
 // NewScheduler returns a new schedule.
@@ -136,10 +174,30 @@ func NewScheduler(cfg SchedulerCfg, dataService *tsdb.Service, appURL *url.URL,
 		adminConfigPollInterval: cfg.AdminConfigPollInterval,
 		disabledOrgs:            cfg.DisabledOrgs,
 		minRuleInterval:         cfg.MinRuleInterval,
+		recordingWriter:         cfg.RecordingWriter,
+		limiter:                 newEvaluationLimiter(cfg.MaxConcurrentEvaluations, cfg.MaxConcurrentEvaluationsPerOrg, cfg.MaxConcurrentEvaluationsPerDatasource),
+		evaluationJitter:        cfg.EvaluationJitter,
+		health:                  newRuleHealthRegistry(cfg.Metrics),
+		notEvaluated:            map[models.AlertRuleKey]struct{}{},
+	}
+	if cfg.ClusterMembership != nil {
+		sch.sharder = newRuleSharder(cfg.ClusterMembership)
 	}
 	return &sch
 }
 
+// notEvaluatedReason returns why the alert rule is not evaluated by this instance, or
+// an empty string if it is.
+func (sch *schedule) notEvaluatedReason(rule *models.AlertRule) string {
+	if rule.IsEvaluationPaused() {
+		return "paused"
+	}
+	if sch.sharder != nil && !sch.sharder.owns(rule.GetKey()) {
+		return "evaluated by another instance"
+	}
+	return ""
+}
+
 func (sch *schedule) Pause() error {
 	if sch == nil {
 		return fmt.Errorf("scheduler is not initialised")
@@ -300,6 +358,12 @@ func (sch *schedule) DroppedAlertmanagersFor(orgID int64) []*url.URL {
 	return s.DroppedAlertmanagers()
 }
 
+// RuleHealth returns the health of the last evaluation of the alert rules of the
+// organization evaluated by this instance, by UID.
+func (sch *schedule) RuleHealth(orgID int64) map[string]models.AlertRuleHealth {
+	return sch.health.list(orgID)
+}
+
 func (sch *schedule) adminConfigSync(ctx context.Context) error {
 	for {
 		select {
@@ -334,6 +398,14 @@ func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 			alertRules := sch.fetchAllDetails(disabledOrgs)
 			sch.log.Debug("alert rules fetched", "count", len(alertRules), "disabled_orgs", disabledOrgs)
 
+			if sch.sharder != nil {
+				if sch.sharder.refresh() {
+					sch.log.Info("cluster members changed, rebalancing alert rules", "self", sch.sharder.self, "members", sch.sharder.members)
+				}
+				sch.sharder.setAlertRules(alertRules)
+			}
+			notEvaluated := make(map[models.AlertRuleKey]struct{})
+
 			// registeredDefinitions is a map used for finding deleted alert rules
 			// initially it is assigned to all known alert rules from the previous cycle
 			// each alert rule found also in this cycle is removed
@@ -343,11 +415,22 @@ func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 			type readyToRunItem struct {
 				key      models.AlertRuleKey
 				ruleInfo alertRuleInfo
+				interval time.Duration
+				// delay is the offset of the evaluation within the tick when the
+				// evaluations are jittered
+				delay time.Duration
 			}
 
 			readyToRun := make([]readyToRunItem, 0)
 			for _, item := range alertRules {
 				key := item.GetKey()
+				if reason := sch.notEvaluatedReason(item); reason != "" {
+					notEvaluated[key] = struct{}{}
+					if _, ok := sch.notEvaluated[key]; !ok {
+						sch.log.Debug("alert rule is not evaluated by this instance", "key", key, "reason", reason)
+					}
+					continue
+				}
 				itemVersion := item.Version
 				newRoutine := !sch.registry.exists(key)
 				ruleInfo := sch.registry.getOrCreateInfo(key, itemVersion)
@@ -374,8 +457,16 @@ func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 				}
 
 				itemFrequency := item.IntervalSeconds / int64(sch.baseInterval.Seconds())
-				if item.IntervalSeconds != 0 && tickNum%itemFrequency == 0 {
-					readyToRun = append(readyToRun, readyToRunItem{key: key, ruleInfo: ruleInfo})
+				interval := time.Duration(item.IntervalSeconds) * time.Second
+				var offsetTicks int64
+				var delay time.Duration
+				if sch.evaluationJitter {
+					offset := jitterOffset(key, interval)
+					offsetTicks = int64(offset / sch.baseInterval)
+					delay = offset % sch.baseInterval
+				}
+				if item.IntervalSeconds != 0 && (tickNum-offsetTicks)%itemFrequency == 0 {
+					readyToRun = append(readyToRun, readyToRunItem{key: key, ruleInfo: ruleInfo, interval: interval, delay: delay})
 				}
 
 				// remove the alert rule from the registered alert rules
@@ -390,8 +481,12 @@ func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 			for i := range readyToRun {
 				item := readyToRun[i]
 
-				time.AfterFunc(time.Duration(int64(i)*step), func() {
-					item.ruleInfo.evalCh <- &evalContext{now: tick, version: item.ruleInfo.version}
+				delay := item.delay
+				if !sch.evaluationJitter {
+					delay = time.Duration(int64(i) * step)
+				}
+				time.AfterFunc(delay, func() {
+					sch.dispatch(ctx, item.key, item.ruleInfo, item.interval, tick)
 				})
 			}
 
@@ -405,6 +500,17 @@ func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 				ruleInfo.stopCh <- struct{}{}
 				sch.registry.del(key)
 			}
+
+			// the states of paused alert rules are cleared so that their alerts are
+			// resolved and they start afresh when resumed, and the states of the alert
+			// rules evaluated by another instance are kept by that instance. They are
+			// cleared once, after their routine is stopped.
+			for key := range notEvaluated {
+				if _, ok := sch.notEvaluated[key]; !ok {
+					sch.stateManager.RemoveByRuleUID(key.OrgID, key.UID)
+				}
+			}
+			sch.notEvaluated = notEvaluated
 		case <-ctx.Done():
 			waitErr := dispatcherGroup.Wait()
 
@@ -423,6 +529,45 @@ func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 	}
 }
 
+// dispatch sends an evaluation to the routine of the alert rule. The evaluation is
+// skipped if the routine does not take it within the interval of the alert rule, because
+// the previous evaluation is still running.
+func (sch *schedule) dispatch(ctx context.Context, key models.AlertRuleKey, ruleInfo alertRuleInfo, interval time.Duration, tick time.Time) {
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	select {
+	case ruleInfo.evalCh <- &evalContext{now: tick, version: ruleInfo.version}:
+	case <-timer.C:
+		sch.metrics.EvalSkipped.WithLabelValues(fmt.Sprint(key.OrgID), skippedEvaluationRunning).Inc()
+		sch.log.Warn("skipping evaluation of alert rule because the previous evaluation is still running", "key", key, "now", tick)
+	case <-ctx.Done():
+	}
+}
+
+// waitForLimits waits until the alert rule can be evaluated within the concurrency
+// limits, for at most the interval of the alert rule. It returns a function that must
+// be called once the evaluation is done, or false if the evaluation is skipped.
+func (sch *schedule) waitForLimits(ctx context.Context, alertRule *models.AlertRule) (func(), bool) {
+	interval := time.Duration(alertRule.IntervalSeconds) * time.Second
+	if interval < sch.baseInterval {
+		interval = sch.baseInterval
+	}
+	ctx, cancel := context.WithTimeout(ctx, interval)
+	defer cancel()
+
+	start := timeNow()
+	release, err := sch.limiter.acquire(ctx, alertRule)
+	tenant := fmt.Sprint(alertRule.OrgID)
+	wait := timeNow().Sub(start)
+	sch.metrics.EvalWait.WithLabelValues(tenant).Observe(wait.Seconds())
+	if err != nil {
+		sch.metrics.EvalSkipped.WithLabelValues(tenant, skippedConcurrencyLimit).Inc()
+		sch.log.Warn("skipping evaluation of alert rule that waited too long for the concurrency limits", "key", alertRule.GetKey(), "wait", wait)
+		return nil, false
+	}
+	return release, true
+}
+
 func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRuleKey, evalCh <-chan *evalContext, stopCh <-chan struct{}) error {
 	sch.log.Debug("alert rule routine started", "key", key)
 
@@ -436,7 +581,12 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 				continue
 			}
 
+			// status is the outcome of the last attempt, it is nil if the evaluation
+			// is skipped
+			var status *evaluationStatus
+
 			evaluate := func(attempt int64) error {
+				status = nil
 				start := timeNow()
 
 				// fetch latest alert rule version
@@ -447,16 +597,44 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 						sch.log.Error("failed to fetch alert rule", "key", key)
 						return err
 					}
+					if alertRule == nil && sch.sharder != nil {
+						// this instance may take over the alert rule from another
+						// instance, so continue from the states it saved
+						sch.stateManager.WarmRule(q.Result)
+					}
 					alertRule = q.Result
 					sch.log.Debug("new alert rule version fetched", "title", alertRule.Title, "key", key, "version", alertRule.Version)
 				}
 
+				// the alert rule may have been paused after it was scheduled
+				if alertRule.IsEvaluationPaused() {
+					sch.log.Debug("skipping evaluation of paused alert rule", "key", key)
+					return nil
+				}
+
+				release, ok := sch.waitForLimits(grafanaCtx, alertRule)
+				if !ok {
+					return nil
+				}
+				// the duration of the evaluation does not include the wait
+				start = timeNow()
+
 				condition := models.Condition{
 					Condition: alertRule.Condition,
 					OrgID:     alertRule.OrgID,
 					Data:      alertRule.Data,
 				}
+				if alertRule.IsRecording() {
+					defer release()
+					err := sch.recordRule(key, alertRule, &condition, ctx.now, attempt)
+					status = &evaluationStatus{health: models.RuleHealthOK, err: err, duration: timeNow().Sub(start)}
+					if err != nil {
+						status.health = models.RuleHealthError
+					}
+					return err
+				}
 				results, err := sch.evaluator.ConditionEval(&condition, ctx.now, sch.dataService)
+				release()
 				var (
 					end    = timeNow()
 					tenant = fmt.Sprint(alertRule.OrgID)
@@ -470,8 +648,15 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 					// consider saving alert instance on error
 					sch.log.Error("failed to evaluate alert rule", "title", alertRule.Title,
 						"key", key, "attempt", attempt, "now", ctx.now, "duration", end.Sub(start), "error", err)
+					status = &evaluationStatus{health: models.RuleHealthError, err: err, duration: end.Sub(start)}
 					return err
 				}
+				evalStatus := resultsStatus(results, end.Sub(start))
+				status = &evalStatus
+				if evalStatus.health == models.RuleHealthError {
+					sch.log.Warn("evaluation of alert rule returned an error", "title", alertRule.Title,
+						"key", key, "now", ctx.now, "error", evalStatus.err)
+				}
 
 				processedStates := sch.stateManager.ProcessEvalResults(context.Background(), alertRule, results)
 				sch.saveAlertStates(processedStates)
@@ -510,14 +695,20 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 					sch.evalApplied(key, ctx.now)
 				}()
 
+				var attempts int64
 				for attempt = 0; attempt < sch.maxAttempts; attempt++ {
+					attempts++
 					err := evaluate(attempt)
 					if err == nil {
 						break
 					}
 				}
+				if status != nil {
+					sch.health.update(alertRule, ctx.now, attempts, *status)
+				}
 			}()
 		case <-stopCh:
+			sch.health.delete(key)
 			sch.stopApplied(key)
 			sch.log.Debug("stopping alert rule routine", "key", key)
 			// interrupt evaluation if it's running
@@ -528,6 +719,37 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 	}
 }
 
+// recordRule evaluates the condition of a recording rule and writes its time series.
+func (sch *schedule) recordRule(key models.AlertRuleKey, alertRule *models.AlertRule, condition *models.Condition, now time.Time, attempt int64) error {
+	start := timeNow()
+	frames, err := sch.evaluator.ConditionRecord(condition, now, sch.dataService)
+	var (
+		end    = timeNow()
+		tenant = fmt.Sprint(alertRule.OrgID)
+		dur    = end.Sub(start).Seconds()
+	)
+
+	sch.metrics.EvalTotal.WithLabelValues(tenant).Inc()
+	sch.metrics.EvalDuration.WithLabelValues(tenant).Observe(dur)
+	if err != nil {
+		sch.metrics.EvalFailures.WithLabelValues(tenant).Inc()
+		sch.log.Error("failed to evaluate recording rule", "title", alertRule.Title,
+			"key", key, "attempt", attempt, "now", now, "duration", end.Sub(start), "error", err)
+		return err
+	}
+
+	if sch.recordingWriter == nil {
+		sch.log.Debug("skipping writing the time series of recording rule", "key", key)
+		return nil
+	}
+	if err := sch.recordingWriter.Write(context.Background(), alertRule, now, frames); err != nil {
+		sch.log.Error("failed to write the time series of recording rule", "title", alertRule.Title,
+			"key", key, "attempt", attempt, "now", now, "record", alertRule.Record, "target", alertRule.RecordTarget, "error", err)
+		return err
+	}
+	return nil
+}
+
 func (sch *schedule) saveAlertStates(states []*state.State) {
 	sch.log.Debug("saving alert states", "count", len(states))
 	for _, s := range states {
@@ -0,0 +1,144 @@
+package schedule
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ringReplicas is the number of points each member has on the hash ring. More points
+// spread the alert rules more evenly between members.
+const ringReplicas = 128
+
+// ClusterMembership provides the members of a high availability cluster of Grafana instances.
+type ClusterMembership interface {
+	// ClusterMembers returns the name of this instance and the names of all alive
+	// instances in the cluster, including this one.
+	ClusterMembers() (string, []string)
+}
+
+// ruleSharder decides which alert rules are evaluated by this instance. The alert
+// rules are partitioned between the members of the cluster using consistent hashing,
+// so when a member joins or leaves only the alert rules of that member move.
+//
+// Alert rules are suppressed by the states of other alert rules kept by the instance
+// evaluating them, so all alert rules of an organization using suppression are
+// evaluated by the same instance.
+type ruleSharder struct {
+	membership ClusterMembership
+
+	self    string
+	members []string
+	ring    *hashRing
+	// suppressionOrgs are the organizations with alert rules suppressed by other
+	// alert rules.
+	suppressionOrgs map[int64]struct{}
+}
+
+func newRuleSharder(membership ClusterMembership) *ruleSharder {
+	return &ruleSharder{membership: membership}
+}
+
+// refresh updates the ring to the current members of the cluster, and returns true if
+// the members changed.
+func (s *ruleSharder) refresh() bool {
+	self, members := s.membership.ClusterMembers()
+	members = append([]string(nil), members...)
+	sort.Strings(members)
+	if self == s.self && equalStrings(members, s.members) {
+		return false
+	}
+	s.self, s.members = self, members
+	s.ring = newHashRing(members)
+	return true
+}
+
+// setAlertRules updates the organizations whose alert rules are all evaluated by the
+// same instance to the ones with suppressible alert rules.
+func (s *ruleSharder) setAlertRules(alertRules []*models.AlertRule) {
+	orgs := make(map[int64]struct{})
+	for _, rule := range alertRules {
+		if rule.IsSuppressible() {
+			orgs[rule.OrgID] = struct{}{}
+		}
+	}
+	s.suppressionOrgs = orgs
+}
+
+// owns returns true if the alert rule is evaluated by this instance. If the members of
+// the cluster are unknown, every instance owns all alert rules.
+func (s *ruleSharder) owns(key models.AlertRuleKey) bool {
+	if s.self == "" || s.ring == nil || len(s.ring.points) == 0 {
+		return true
+	}
+	_, wholeOrg := s.suppressionOrgs[key.OrgID]
+	return s.ring.owner(ruleShardKey(key, wholeOrg)) == s.self
+}
+
+// ruleShardKey returns the key of the alert rule on the ring, which is the key of its
+// organization if all alert rules of the organization are evaluated by the same instance.
+func ruleShardKey(key models.AlertRuleKey, wholeOrg bool) string {
+	if wholeOrg {
+		return strconv.FormatInt(key.OrgID, 10)
+	}
+	return strconv.FormatInt(key.OrgID, 10) + "/" + key.UID
+}
+
+// hashRing is a consistent hash ring of the members of a cluster.
+type hashRing struct {
+	points  []uint32
+	members map[uint32]string
+}
+
+func newHashRing(members []string) *hashRing {
+	r := &hashRing{
+		points:  make([]uint32, 0, len(members)*ringReplicas),
+		members: make(map[uint32]string, len(members)*ringReplicas),
+	}
+	for _, m := range members {
+		for i := 0; i < ringReplicas; i++ {
+			h := hashString(m + "#" + strconv.Itoa(i))
+			// on a collision, the point belongs to the smallest member name so all
+			// instances build the same ring
+			if existing, ok := r.members[h]; ok && existing < m {
+				continue
+			} else if !ok {
+				r.points = append(r.points, h)
+			}
+			r.members[h] = m
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+	return r
+}
+
+// owner returns the member that owns key, which is the member of the first point on
+// the ring at or after the hash of key.
+func (r *hashRing) owner(key string) string {
+	h := hashString(key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= h })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.members[r.points[i]]
+}
+
+func hashString(s string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum32()
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
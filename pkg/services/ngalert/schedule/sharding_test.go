@@ -0,0 +1,105 @@
+package schedule
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClusterMembership struct {
+	self    string
+	members []string
+}
+
+func (f *fakeClusterMembership) ClusterMembers() (string, []string) {
+	return f.self, f.members
+}
+
+func testRuleKeys(n int) []models.AlertRuleKey {
+	keys := make([]models.AlertRuleKey, 0, n)
+	for i := 0; i < n; i++ {
+		keys = append(keys, models.AlertRuleKey{OrgID: int64(i%3 + 1), UID: fmt.Sprintf("rule-%d", i)})
+	}
+	return keys
+}
+
+func TestRuleSharder(t *testing.T) {
+	keys := testRuleKeys(3000)
+
+	t.Run("every alert rule is owned by exactly one member", func(t *testing.T) {
+		members := []string{"a", "b", "c"}
+		owned := map[string]int{}
+		for _, self := range members {
+			// members are listed in a different order by each instance
+			s := newRuleSharder(&fakeClusterMembership{self: self, members: []string{members[2], members[0], members[1]}})
+			require.True(t, s.refresh())
+			for _, key := range keys {
+				if s.owns(key) {
+					owned[key.String()]++
+					owned[self]++
+				}
+			}
+		}
+		for _, key := range keys {
+			require.Equal(t, 1, owned[key.String()], key.String())
+		}
+		// the alert rules are spread between the members
+		for _, m := range members {
+			require.InDelta(t, len(keys)/len(members), owned[m], float64(len(keys))/10, m)
+		}
+	})
+
+	t.Run("only the alert rules of a leaving member move", func(t *testing.T) {
+		before := newHashRing([]string{"a", "b", "c"})
+		after := newHashRing([]string{"a", "b"})
+		for _, key := range keys {
+			k := ruleShardKey(key, false)
+			if owner := before.owner(k); owner != "c" {
+				require.Equal(t, owner, after.owner(k))
+			}
+		}
+	})
+
+	t.Run("refresh detects membership changes", func(t *testing.T) {
+		m := &fakeClusterMembership{self: "a", members: []string{"a", "b"}}
+		s := newRuleSharder(m)
+		require.True(t, s.refresh())
+		require.False(t, s.refresh())
+		m.members = []string{"b", "a"}
+		require.False(t, s.refresh())
+		m.members = []string{"a"}
+		require.True(t, s.refresh())
+		for _, key := range keys {
+			require.True(t, s.owns(key))
+		}
+	})
+
+	t.Run("the alert rules of organizations using suppression are owned by the same member", func(t *testing.T) {
+		members := []string{"a", "b", "c"}
+		owners := map[int64]map[string]struct{}{}
+		for _, self := range members {
+			s := newRuleSharder(&fakeClusterMembership{self: self, members: members})
+			s.refresh()
+			s.setAlertRules([]*models.AlertRule{{OrgID: 1, UID: "rule-0", SuppressedByRuleUIDs: []string{"rule-3"}}})
+			for _, key := range keys {
+				if key.OrgID == 1 && s.owns(key) {
+					if owners[key.OrgID] == nil {
+						owners[key.OrgID] = map[string]struct{}{}
+					}
+					owners[key.OrgID][self] = struct{}{}
+				}
+			}
+		}
+		require.Len(t, owners[1], 1)
+	})
+
+	t.Run("all alert rules are owned without members", func(t *testing.T) {
+		s := newRuleSharder(&fakeClusterMembership{})
+		s.refresh()
+		for _, key := range keys {
+			require.True(t, s.owns(key))
+		}
+	})
+}
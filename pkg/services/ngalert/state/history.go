@@ -0,0 +1,69 @@
+package state
+
+import (
+	"math"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// staleStateReason is the reason of the transition of stale alert instances.
+const staleStateReason = "MissingSeries"
+
+// newStateHistoryEntry returns the transition of an alert instance from oldState to its
+// current state after the evaluation result.
+func newStateHistoryEntry(s *State, oldState eval.State, result eval.Result) ngModels.AlertStateHistoryEntry {
+	var reason string
+	switch {
+	case result.Error != nil:
+		reason = result.Error.Error()
+	case result.State != s.State && (result.State == eval.NoData || result.State == eval.Error):
+		// the state was set by the no data or error handling of the alert rule
+		reason = result.State.String()
+	}
+
+	values := make(ngModels.StateHistoryValues, len(result.Values))
+	for refID, v := range result.Values {
+		// the values are stored as json, which has no representation for these
+		if v.Value == nil || math.IsNaN(*v.Value) || math.IsInf(*v.Value, 0) {
+			continue
+		}
+		values[refID] = *v.Value
+	}
+
+	return ngModels.AlertStateHistoryEntry{
+		RuleOrgID:        s.OrgID,
+		RuleUID:          s.AlertRuleUID,
+		Labels:           ngModels.InstanceLabels(s.Labels),
+		PreviousState:    ngModels.InstanceStateType(oldState.String()),
+		CurrentState:     ngModels.InstanceStateType(s.State.String()),
+		Reason:           reason,
+		EvaluationValues: values,
+		EvaluatedAt:      result.EvaluatedAt,
+	}
+}
+
+// newStaleStateHistoryEntry returns the transition of a stale alert instance, which is
+// removed as Normal when its series is missing from the results of the alert rule.
+func newStaleStateHistoryEntry(s *State, evaluatedAt time.Time) ngModels.AlertStateHistoryEntry {
+	return ngModels.AlertStateHistoryEntry{
+		RuleOrgID:        s.OrgID,
+		RuleUID:          s.AlertRuleUID,
+		Labels:           ngModels.InstanceLabels(s.Labels),
+		PreviousState:    ngModels.InstanceStateType(s.State.String()),
+		CurrentState:     ngModels.InstanceStateNormal,
+		Reason:           staleStateReason,
+		EvaluationValues: ngModels.StateHistoryValues{},
+		EvaluatedAt:      evaluatedAt,
+	}
+}
+
+func (st *Manager) saveStateHistory(alertRule *ngModels.AlertRule, transitions []ngModels.AlertStateHistoryEntry) {
+	if st.historyStore == nil || len(transitions) == 0 {
+		return
+	}
+	if err := st.historyStore.SaveAlertStateHistory(transitions); err != nil {
+		st.log.Error("failed to save alert state history", "alertRuleUID", alertRule.UID, "transitions", len(transitions), "error", err.Error())
+	}
+}
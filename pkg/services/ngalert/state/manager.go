@@ -2,11 +2,14 @@ package state
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"strconv"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
@@ -14,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/image"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
@@ -29,11 +33,18 @@ type Manager struct {
 	quit        chan struct{}
 	ResendDelay time.Duration
 
-	ruleStore     store.RuleStore
+	ruleStore store.RuleStore
+	// instanceStore persists the alert instances. It is nil when the states are only
+	// kept in memory, such as when backtesting an alert rule.
 	instanceStore store.InstanceStore
+	// historyStore records the state transitions of alert instances. It is optional.
+	historyStore store.StateHistoryStore
+	// imageService takes screenshots of the panels of alert rules when their alert
+	// instances start firing. It is optional.
+	imageService image.ImageService
 }
 
-func NewManager(logger log.Logger, metrics *metrics.State, externalURL *url.URL, ruleStore store.RuleStore, instanceStore store.InstanceStore) *Manager {
+func NewManager(logger log.Logger, metrics *metrics.State, externalURL *url.URL, ruleStore store.RuleStore, instanceStore store.InstanceStore, historyStore store.StateHistoryStore, imageService image.ImageService) *Manager {
 	manager := &Manager{
 		cache:         newCache(logger, metrics, externalURL),
 		quit:          make(chan struct{}),
@@ -42,6 +53,8 @@ func NewManager(logger log.Logger, metrics *metrics.State, externalURL *url.URL,
 		metrics:       metrics,
 		ruleStore:     ruleStore,
 		instanceStore: instanceStore,
+		historyStore:  historyStore,
+		imageService:  imageService,
 	}
 	go manager.recordMetrics()
 	return manager
@@ -89,25 +102,7 @@ func (st *Manager) Warm() {
 				st.log.Error("rule not found for instance, ignoring", "rule", entry.RuleUID)
 				continue
 			}
-
-			lbs := map[string]string(entry.Labels)
-			cacheId, err := entry.Labels.StringKey()
-			if err != nil {
-				st.log.Error("error getting cacheId for entry", "msg", err.Error())
-			}
-			stateForEntry := &State{
-				AlertRuleUID:       entry.RuleUID,
-				OrgID:              entry.RuleOrgID,
-				CacheId:            cacheId,
-				Labels:             lbs,
-				State:              translateInstanceState(entry.CurrentState),
-				Results:            []Evaluation{},
-				StartsAt:           entry.CurrentStateSince,
-				EndsAt:             entry.CurrentStateEnd,
-				LastEvaluationTime: entry.LastEvalTime,
-				Annotations:        ruleForEntry.Annotations,
-			}
-			states = append(states, stateForEntry)
+			states = append(states, st.stateFromInstance(entry, ruleForEntry))
 		}
 	}
 
@@ -116,6 +111,45 @@ func (st *Manager) Warm() {
 	}
 }
 
+// WarmRule replaces the cached states of an alert rule with the alert instances
+// stored in the database. It is used when this instance takes over the evaluation of
+// an alert rule from another instance, which saved the states of its last evaluation.
+func (st *Manager) WarmRule(alertRule *ngModels.AlertRule) {
+	cmd := ngModels.ListAlertInstancesQuery{
+		RuleOrgID: alertRule.OrgID,
+		RuleUID:   alertRule.UID,
+	}
+	if err := st.instanceStore.ListAlertInstances(&cmd); err != nil {
+		st.log.Error("unable to fetch previous state", "orgID", alertRule.OrgID, "alertRuleUID", alertRule.UID, "msg", err.Error())
+		return
+	}
+
+	st.RemoveByRuleUID(alertRule.OrgID, alertRule.UID)
+	for _, entry := range cmd.Result {
+		st.set(st.stateFromInstance(entry, alertRule))
+	}
+}
+
+func (st *Manager) stateFromInstance(entry *ngModels.ListAlertInstancesQueryResult, alertRule *ngModels.AlertRule) *State {
+	lbs := map[string]string(entry.Labels)
+	cacheId, err := entry.Labels.StringKey()
+	if err != nil {
+		st.log.Error("error getting cacheId for entry", "msg", err.Error())
+	}
+	return &State{
+		AlertRuleUID:       entry.RuleUID,
+		OrgID:              entry.RuleOrgID,
+		CacheId:            cacheId,
+		Labels:             lbs,
+		State:              translateInstanceState(entry.CurrentState),
+		Results:            []Evaluation{},
+		StartsAt:           entry.CurrentStateSince,
+		EndsAt:             entry.CurrentStateEnd,
+		LastEvaluationTime: entry.LastEvalTime,
+		Annotations:        alertRule.Annotations,
+	}
+}
+
 func (st *Manager) getOrCreate(alertRule *ngModels.AlertRule, result eval.Result) *State {
 	return st.cache.getOrCreate(alertRule, result)
 }
@@ -136,23 +170,129 @@ func (st *Manager) ResetCache() {
 // RemoveByRuleUID deletes all entries in the state manager that match the given rule UID.
 func (st *Manager) RemoveByRuleUID(orgID int64, ruleUID string) {
 	st.cache.removeByRuleUID(orgID, ruleUID)
+	if st.imageService != nil {
+		st.imageService.ForgetAlertRule(ngModels.AlertRuleKey{OrgID: orgID, UID: ruleUID})
+	}
 }
 
 func (st *Manager) ProcessEvalResults(ctx context.Context, alertRule *ngModels.AlertRule, results eval.Results) []*State {
 	st.log.Debug("state manager processing evaluation results", "uid", alertRule.UID, "resultCount", len(results))
+	suppressedBy := st.suppressedBy(alertRule)
+	if states := st.keepLastStates(alertRule, results, suppressedBy); len(states) > 0 {
+		return states
+	}
+
 	var states []*State
+	var transitions []ngModels.AlertStateHistoryEntry
 	processedResults := make(map[string]*State, len(results))
 	for _, result := range results {
-		s := st.setNextState(ctx, alertRule, result)
+		s, oldState := st.setNextState(ctx, alertRule, result, suppressedBy)
 		states = append(states, s)
 		processedResults[s.CacheId] = s
+		if oldState != s.State {
+			transitions = append(transitions, newStateHistoryEntry(s, oldState, result))
+		}
+	}
+	st.saveStateHistory(alertRule, transitions)
+	evaluatedAt := time.Now()
+	if len(results) > 0 {
+		evaluatedAt = results[0].EvaluatedAt
 	}
-	st.staleResultsHandler(alertRule, processedResults)
+	st.staleResultsHandler(alertRule, processedResults, evaluatedAt)
 	return states
 }
 
-// Set the current state based on evaluation results
-func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRule, result eval.Result) *State {
+// keepLastStates keeps the states of the existing alert instances of an alert rule that
+// keeps its last state when the evaluation fails or has no data. The result of such an
+// evaluation has no labels, so instead of creating a new alert instance for it, the result
+// is applied to all existing alert instances, which then do not become stale.
+func (st *Manager) keepLastStates(alertRule *ngModels.AlertRule, results eval.Results, suppressedBy string) []*State {
+	if len(results) != 1 {
+		return nil
+	}
+	result := results[0]
+	switch {
+	case result.State == eval.NoData && alertRule.NoDataState == ngModels.KeepLastState:
+	case result.State == eval.Error && alertRule.ExecErrState == ngModels.KeepLastStateErrState:
+	default:
+		return nil
+	}
+
+	states := st.GetStatesForRuleUID(alertRule.OrgID, alertRule.UID)
+	var transitions []ngModels.AlertStateHistoryEntry
+	for _, s := range states {
+		oldState := s.State
+		// the state is kept, so the history only records when the alert rule starts to
+		// have no data or to fail, for the reason why the state is kept
+		keeping := len(s.Results) > 0 && s.Results[len(s.Results)-1].EvaluationState == result.State
+		s.LastEvaluationTime = result.EvaluatedAt
+		s.EvaluationDuration = result.EvaluationDuration
+		s.Results = append(s.Results, Evaluation{
+			EvaluationTime:   result.EvaluatedAt,
+			EvaluationState:  result.State,
+			EvaluationString: result.EvaluationString,
+			Values:           NewEvaluationValues(result.Values),
+		})
+		s.TrimResults(alertRule)
+		s.resultKeepLastState(alertRule, result)
+		s.Resolved = false
+		s.setSuppressedBy(suppressedBy, result.EvaluatedAt)
+		st.set(s)
+		if !keeping {
+			transitions = append(transitions, newStateHistoryEntry(s, oldState, result))
+		}
+	}
+	st.saveStateHistory(alertRule, transitions)
+	return states
+}
+
+// suppressedBy returns the UID of an alert rule with a firing alert instance that
+// suppresses the alert instances of the alert rule, or an empty string if they are not
+// suppressed. Firing alert instances that are themselves suppressed do not suppress
+// others, so that alert rules suppressing each other do not silence each other forever.
+//
+// Only the states of this instance are considered, so when the evaluation of alert rules
+// is sharded, all alert rules of an organization using suppression are evaluated by the
+// same instance.
+func (st *Manager) suppressedBy(alertRule *ngModels.AlertRule) string {
+	if !alertRule.IsSuppressible() {
+		return ""
+	}
+
+	for _, uid := range alertRule.SuppressedByRuleUIDs {
+		for _, s := range st.GetStatesForRuleUID(alertRule.OrgID, uid) {
+			if s.State == eval.Alerting && s.SuppressedBy == "" {
+				return uid
+			}
+		}
+	}
+
+	if len(alertRule.SuppressedByLabels) == 0 {
+		return ""
+	}
+	for _, s := range st.GetAll(alertRule.OrgID) {
+		if s.AlertRuleUID == alertRule.UID || s.State != eval.Alerting || s.SuppressedBy != "" {
+			continue
+		}
+		if hasLabels(s.Labels, alertRule.SuppressedByLabels) {
+			return s.AlertRuleUID
+		}
+	}
+	return ""
+}
+
+// hasLabels returns true if labels has all the labels of selector.
+func hasLabels(labels data.Labels, selector map[string]string) bool {
+	for name, value := range selector {
+		if v, ok := labels[name]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Set the current state based on evaluation results, and return it with the previous state.
+func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRule, result eval.Result, suppressedBy string) (*State, eval.State) {
 	currentState := st.getOrCreate(alertRule, result)
 
 	currentState.LastEvaluationTime = result.EvaluatedAt
@@ -182,12 +322,34 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 	// Set Resolved property so the scheduler knows to send a postable alert
 	// to Alertmanager.
 	currentState.Resolved = oldState == eval.Alerting && currentState.State == eval.Normal
+	currentState.setSuppressedBy(suppressedBy, result.EvaluatedAt)
+
+	if oldState != eval.Alerting && currentState.State == eval.Alerting {
+		currentState.Image = st.newImage(ctx, alertRule, result.EvaluatedAt)
+	}
 
 	st.set(currentState)
 	if oldState != currentState.State {
 		go st.createAlertAnnotation(ctx, currentState.State, alertRule, result, oldState)
 	}
-	return currentState
+	return currentState, oldState
+}
+
+// newImage returns the screenshot of the panel of the alert rule for an evaluation, or nil
+// if screenshots are disabled or the alert rule is not linked to a panel.
+func (st *Manager) newImage(ctx context.Context, alertRule *ngModels.AlertRule, evaluatedAt time.Time) *ngModels.Image {
+	if st.imageService == nil {
+		return nil
+	}
+	img, err := st.imageService.NewImage(ctx, alertRule, evaluatedAt)
+	if err != nil {
+		if errors.Is(err, image.ErrNoDashboard) || errors.Is(err, image.ErrNoPanel) {
+			return nil
+		}
+		st.log.Warn("failed to take a screenshot of the panel of the alert rule", "uid", alertRule.UID, "err", err)
+		return nil
+	}
+	return img
 }
 
 func (st *Manager) GetAll(orgID int64) []*State {
@@ -278,26 +440,36 @@ func (st *Manager) createAlertAnnotation(ctx context.Context, new eval.State, al
 	}
 }
 
-func (st *Manager) staleResultsHandler(alertRule *ngModels.AlertRule, states map[string]*State) {
+func (st *Manager) staleResultsHandler(alertRule *ngModels.AlertRule, states map[string]*State, evaluatedAt time.Time) {
 	allStates := st.GetStatesForRuleUID(alertRule.OrgID, alertRule.UID)
+	var transitions []ngModels.AlertStateHistoryEntry
 	for _, s := range allStates {
 		_, ok := states[s.CacheId]
-		if !ok && isItStale(s.LastEvaluationTime, alertRule.IntervalSeconds) {
+		if !ok && isItStale(s.LastEvaluationTime, evaluatedAt, alertRule.IntervalSeconds) {
 			st.log.Debug("removing stale state entry", "orgID", s.OrgID, "alertRuleUID", s.AlertRuleUID, "cacheID", s.CacheId)
 			st.cache.deleteEntry(s.OrgID, s.AlertRuleUID, s.CacheId)
+			if s.State != eval.Normal {
+				transitions = append(transitions, newStaleStateHistoryEntry(s, evaluatedAt))
+			}
 			ilbs := ngModels.InstanceLabels(s.Labels)
 			_, labelsHash, err := ilbs.StringAndHash()
 			if err != nil {
 				st.log.Error("unable to get labelsHash", "error", err.Error(), "orgID", s.OrgID, "alertRuleUID", s.AlertRuleUID)
 			}
 
+			if st.instanceStore == nil {
+				continue
+			}
 			if err = st.instanceStore.DeleteAlertInstance(s.OrgID, s.AlertRuleUID, labelsHash); err != nil {
 				st.log.Error("unable to delete stale instance from database", "error", err.Error(), "orgID", s.OrgID, "alertRuleUID", s.AlertRuleUID, "cacheID", s.CacheId)
 			}
 		}
 	}
+	st.saveStateHistory(alertRule, transitions)
 }
 
-func isItStale(lastEval time.Time, intervalSeconds int64) bool {
-	return lastEval.Add(2 * time.Duration(intervalSeconds) * time.Second).Before(time.Now())
+// isItStale returns true if an alert instance was last evaluated more than two intervals
+// before the evaluation at evaluatedAt.
+func isItStale(lastEval, evaluatedAt time.Time, intervalSeconds int64) bool {
+	return lastEval.Add(2 * time.Duration(intervalSeconds) * time.Second).Before(evaluatedAt)
 }
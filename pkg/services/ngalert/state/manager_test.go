@@ -2,6 +2,7 @@ package state_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/tests"
 
+	"github.com/prometheus/alertmanager/pkg/labels"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -852,7 +854,7 @@ func TestProcessEvalResults(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		st := state.NewManager(log.New("test_state_manager"), testMetrics.GetStateMetrics(), nil, nil, nil)
+		st := state.NewManager(log.New("test_state_manager"), testMetrics.GetStateMetrics(), nil, nil, nil, nil, nil)
 		t.Run(tc.desc, func(t *testing.T) {
 			for _, res := range tc.evalResults {
 				_ = st.ProcessEvalResults(context.Background(), tc.alertRule, res)
@@ -914,7 +916,7 @@ func TestStaleResultsHandler(t *testing.T) {
 					eval.Result{
 						Instance:    data.Labels{"test1": "testValue1"},
 						State:       eval.Normal,
-						EvaluatedAt: evaluationTime.Add(3 * time.Minute),
+						EvaluatedAt: evaluationTime.Add(30 * time.Minute),
 					},
 				},
 			},
@@ -932,12 +934,12 @@ func TestStaleResultsHandler(t *testing.T) {
 					State: eval.Normal,
 					Results: []state.Evaluation{
 						{
-							EvaluationTime:  evaluationTime.Add(3 * time.Minute),
+							EvaluationTime:  evaluationTime.Add(30 * time.Minute),
 							EvaluationState: eval.Normal,
 							Values:          make(map[string]state.EvaluationValue),
 						},
 					},
-					LastEvaluationTime: evaluationTime.Add(3 * time.Minute),
+					LastEvaluationTime: evaluationTime.Add(30 * time.Minute),
 					EvaluationDuration: 0,
 					Annotations:        map[string]string{"testAnnoKey": "testAnnoValue"},
 				},
@@ -948,7 +950,7 @@ func TestStaleResultsHandler(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		st := state.NewManager(log.New("test_stale_results_handler"), testMetrics.GetStateMetrics(), nil, dbstore, dbstore)
+		st := state.NewManager(log.New("test_stale_results_handler"), testMetrics.GetStateMetrics(), nil, dbstore, dbstore, nil, nil)
 		st.Warm()
 		existingStatesForRule := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
 
@@ -968,3 +970,433 @@ func TestStaleResultsHandler(t *testing.T) {
 		assert.Equal(t, tc.finalStateCount, len(existingStatesForRule))
 	}
 }
+
+func TestProcessEvalResultsErrorAndNoDataStates(t *testing.T) {
+	// evaluations are recent so existing states do not become stale
+	evaluationTime := time.Now().Truncate(time.Second)
+	firing := eval.Result{
+		Instance:    data.Labels{"instance_label": "test"},
+		State:       eval.Alerting,
+		EvaluatedAt: evaluationTime,
+	}
+	failed := eval.Result{
+		State:       eval.Error,
+		Error:       errors.New("data source timeout"),
+		EvaluatedAt: evaluationTime.Add(10 * time.Second),
+	}
+	noData := eval.Result{
+		State:       eval.NoData,
+		EvaluatedAt: evaluationTime.Add(10 * time.Second),
+	}
+	const instanceCacheID = `[["__alert_rule_namespace_uid__","test_namespace_uid"],["__alert_rule_uid__","test_alert_rule_uid"],["alertname","test_title"],["instance_label","test"]]`
+	const ruleCacheID = `[["__alert_rule_namespace_uid__","test_namespace_uid"],["__alert_rule_uid__","test_alert_rule_uid"],["alertname","test_title"]]`
+
+	type expectedState struct {
+		state              eval.State
+		startsAt           time.Time
+		lastEvaluationTime time.Time
+	}
+	testCases := []struct {
+		desc         string
+		noDataState  models.NoDataState
+		execErrState models.ExecutionErrorState
+		result       eval.Result
+		expected     map[string]expectedState
+	}{
+		{
+			desc:         "error when result is Error and ExecErrState is Error",
+			execErrState: models.ErrorErrState,
+			result:       failed,
+			expected: map[string]expectedState{
+				instanceCacheID: {eval.Alerting, evaluationTime, evaluationTime},
+				ruleCacheID:     {eval.Error, failed.EvaluatedAt, failed.EvaluatedAt},
+			},
+		},
+		{
+			desc:         "normal when result is Error and ExecErrState is OK",
+			execErrState: models.OkErrState,
+			result:       failed,
+			expected: map[string]expectedState{
+				instanceCacheID: {eval.Alerting, evaluationTime, evaluationTime},
+				ruleCacheID:     {eval.Normal, time.Time{}, failed.EvaluatedAt},
+			},
+		},
+		{
+			desc:         "alerting is kept when result is Error and ExecErrState is KeepLastState",
+			execErrState: models.KeepLastStateErrState,
+			result:       failed,
+			expected: map[string]expectedState{
+				instanceCacheID: {eval.Alerting, evaluationTime, failed.EvaluatedAt},
+			},
+		},
+		{
+			desc:        "alerting is kept when result is NoData and NoDataState is KeepLastState",
+			noDataState: models.KeepLastState,
+			result:      noData,
+			expected: map[string]expectedState{
+				instanceCacheID: {eval.Alerting, evaluationTime, noData.EvaluatedAt},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			st := state.NewManager(log.New("test_state_manager"), testMetrics.GetStateMetrics(), nil, nil, nil, nil, nil)
+			rule := &models.AlertRule{
+				OrgID:           1,
+				Title:           "test_title",
+				UID:             "test_alert_rule_uid",
+				NamespaceUID:    "test_namespace_uid",
+				IntervalSeconds: 10,
+				NoDataState:     tc.noDataState,
+				ExecErrState:    tc.execErrState,
+			}
+			_ = st.ProcessEvalResults(context.Background(), rule, eval.Results{firing})
+			_ = st.ProcessEvalResults(context.Background(), rule, eval.Results{tc.result})
+
+			require.Len(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID), len(tc.expected))
+			for cacheID, expected := range tc.expected {
+				s, err := st.Get(rule.OrgID, rule.UID, cacheID)
+				require.NoError(t, err)
+				assert.Equal(t, expected.state, s.State)
+				assert.Equal(t, expected.startsAt, s.StartsAt)
+				assert.Equal(t, expected.lastEvaluationTime, s.LastEvaluationTime)
+			}
+		})
+	}
+}
+
+func TestProcessEvalResultsSuppression(t *testing.T) {
+	evaluationTime := time.Now().Truncate(time.Second)
+	result := func(state eval.State, instance data.Labels) eval.Results {
+		return eval.Results{{
+			Instance:    instance,
+			State:       state,
+			EvaluatedAt: evaluationTime,
+		}}
+	}
+	newRule := func(uid string, labels map[string]string) *models.AlertRule {
+		return &models.AlertRule{
+			OrgID:           1,
+			Title:           uid,
+			UID:             uid,
+			NamespaceUID:    "test_namespace_uid",
+			IntervalSeconds: 10,
+			Labels:          labels,
+		}
+	}
+
+	testCases := []struct {
+		desc                 string
+		suppressedByRuleUIDs []string
+		suppressedByLabels   map[string]string
+		coreState            eval.State
+		downstreamState      eval.State
+		expectedSuppressedBy string
+	}{
+		{
+			desc:                 "suppressed by rule UID while the rule is firing",
+			suppressedByRuleUIDs: []string{"unknown", "core"},
+			coreState:            eval.Alerting,
+			downstreamState:      eval.Alerting,
+			expectedSuppressedBy: "core",
+		},
+		{
+			desc:                 "suppressed by labels while a matching rule is firing",
+			suppressedByLabels:   map[string]string{"team": "network"},
+			coreState:            eval.Alerting,
+			downstreamState:      eval.Alerting,
+			expectedSuppressedBy: "core",
+		},
+		{
+			desc:               "not suppressed by labels that do not match",
+			suppressedByLabels: map[string]string{"team": "network", "severity": "critical"},
+			coreState:          eval.Alerting,
+			downstreamState:    eval.Alerting,
+		},
+		{
+			desc:                 "not suppressed while the rule is not firing",
+			suppressedByRuleUIDs: []string{"core"},
+			coreState:            eval.Normal,
+			downstreamState:      eval.Alerting,
+		},
+		{
+			desc:                 "not suppressed while not firing",
+			suppressedByRuleUIDs: []string{"core"},
+			coreState:            eval.Alerting,
+			downstreamState:      eval.Normal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			st := state.NewManager(log.New("test_state_manager"), testMetrics.GetStateMetrics(), nil, nil, nil, nil, nil)
+			core := newRule("core", map[string]string{"team": "network"})
+			downstream := newRule("downstream", map[string]string{"team": "network"})
+			downstream.SuppressedByRuleUIDs = tc.suppressedByRuleUIDs
+			downstream.SuppressedByLabels = tc.suppressedByLabels
+
+			_ = st.ProcessEvalResults(context.Background(), core, result(tc.coreState, data.Labels{"instance": "router"}))
+			states := st.ProcessEvalResults(context.Background(), downstream, result(tc.downstreamState, data.Labels{"instance": "api"}))
+
+			require.Len(t, states, 1)
+			assert.Equal(t, tc.downstreamState, states[0].State)
+			assert.Equal(t, tc.expectedSuppressedBy, states[0].SuppressedBy)
+			if tc.expectedSuppressedBy != "" {
+				assert.False(t, states[0].NeedsSending(st.ResendDelay))
+			}
+		})
+	}
+
+	t.Run("firing alerts are resolved once when they become suppressed", func(t *testing.T) {
+		st := state.NewManager(log.New("test_state_manager"), testMetrics.GetStateMetrics(), nil, nil, nil, nil, nil)
+		core := newRule("core", nil)
+		downstream := newRule("downstream", nil)
+		downstream.SuppressedByRuleUIDs = []string{"core"}
+		process := func(rule *models.AlertRule, s eval.State, at time.Time) *state.State {
+			states := st.ProcessEvalResults(context.Background(), rule, eval.Results{{
+				Instance:    data.Labels{"instance": rule.UID},
+				State:       s,
+				EvaluatedAt: at,
+			}})
+			require.Len(t, states, 1)
+			return states[0]
+		}
+		send := func(s *state.State) {
+			s.LastSentAt = s.LastEvaluationTime
+			s.LastSentAsFiring = s.State == eval.Alerting && s.SuppressedBy == ""
+		}
+
+		// firing
+		process(core, eval.Normal, evaluationTime)
+		s := process(downstream, eval.Alerting, evaluationTime)
+		require.Equal(t, "", s.SuppressedBy)
+		require.True(t, s.NeedsSending(st.ResendDelay))
+		send(s)
+
+		// suppressed: sent once as resolved at the evaluation
+		at := evaluationTime.Add(10 * time.Second)
+		process(core, eval.Alerting, at)
+		s = process(downstream, eval.Alerting, at)
+		require.Equal(t, "core", s.SuppressedBy)
+		require.Equal(t, at, s.EndsAt)
+		require.True(t, s.NeedsSending(st.ResendDelay))
+		send(s)
+
+		at = at.Add(time.Minute)
+		process(core, eval.Alerting, at)
+		s = process(downstream, eval.Alerting, at)
+		require.Equal(t, at, s.EndsAt)
+		require.False(t, s.NeedsSending(st.ResendDelay))
+
+		// not suppressed anymore: sent again as firing at once
+		at = at.Add(10 * time.Second)
+		process(core, eval.Normal, at)
+		s = process(downstream, eval.Alerting, at)
+		require.Equal(t, "", s.SuppressedBy)
+		require.True(t, s.EndsAt.After(at))
+		require.True(t, s.NeedsSending(st.ResendDelay))
+	})
+
+	t.Run("suppressed alerts do not suppress other alerts", func(t *testing.T) {
+		st := state.NewManager(log.New("test_state_manager"), testMetrics.GetStateMetrics(), nil, nil, nil, nil, nil)
+		a := newRule("a", map[string]string{"team": "network"})
+		b := newRule("b", map[string]string{"team": "network"})
+		a.SuppressedByLabels = map[string]string{"team": "network"}
+		b.SuppressedByLabels = map[string]string{"team": "network"}
+
+		states := st.ProcessEvalResults(context.Background(), a, result(eval.Alerting, data.Labels{"instance": "a"}))
+		require.Equal(t, "", states[0].SuppressedBy)
+		states = st.ProcessEvalResults(context.Background(), b, result(eval.Alerting, data.Labels{"instance": "b"}))
+		require.Equal(t, "a", states[0].SuppressedBy)
+		// b is suppressed, so it does not suppress a
+		states = st.ProcessEvalResults(context.Background(), a, result(eval.Alerting, data.Labels{"instance": "a"}))
+		require.Equal(t, "", states[0].SuppressedBy)
+	})
+}
+
+func TestStateHistory(t *testing.T) {
+	evaluationTime, err := time.Parse("2006-01-02", "2021-03-25")
+	require.NoError(t, err)
+
+	_, dbstore := tests.SetupTestEnv(t, 1)
+
+	const mainOrgID int64 = 1
+	rule := tests.CreateTestAlertRule(t, dbstore, 600, mainOrgID)
+
+	st := state.NewManager(log.New("test_state_history"), testMetrics.GetStateMetrics(), nil, dbstore, dbstore, dbstore, nil)
+
+	value := float64(3)
+	st.ProcessEvalResults(context.Background(), rule, eval.Results{
+		{
+			Instance:    data.Labels{"host": "a"},
+			State:       eval.Alerting,
+			EvaluatedAt: evaluationTime,
+			Values:      map[string]eval.NumberValueCapture{"B": {Var: "B", Value: &value}},
+		},
+		{
+			Instance:    data.Labels{"host": "b"},
+			State:       eval.Normal,
+			EvaluatedAt: evaluationTime,
+		},
+	})
+	st.ProcessEvalResults(context.Background(), rule, eval.Results{
+		{
+			Instance:    data.Labels{"host": "a"},
+			State:       eval.Normal,
+			EvaluatedAt: evaluationTime.Add(time.Minute),
+		},
+		{
+			Instance:    data.Labels{"host": "b"},
+			State:       eval.Error,
+			Error:       errors.New("failed to execute query"),
+			EvaluatedAt: evaluationTime.Add(time.Minute),
+		},
+	})
+
+	t.Run("all state transitions are recorded", func(t *testing.T) {
+		q := models.ListAlertStateHistoryQuery{OrgID: mainOrgID, RuleUID: rule.UID}
+		require.NoError(t, dbstore.ListAlertStateHistory(&q))
+		require.Len(t, q.Result, 3)
+
+		// the most recent transitions are first
+		require.Equal(t, evaluationTime.Add(time.Minute).Unix(), q.Result[0].EvaluatedAt.Unix())
+		require.Equal(t, evaluationTime.Add(time.Minute).Unix(), q.Result[1].EvaluatedAt.Unix())
+
+		first := q.Result[2]
+		require.Equal(t, evaluationTime.Unix(), first.EvaluatedAt.Unix())
+		require.Equal(t, "a", first.Labels["host"])
+		require.Equal(t, rule.UID, first.Labels[models.RuleUIDLabel])
+		require.Equal(t, models.InstanceStateNormal, first.PreviousState)
+		require.Equal(t, models.InstanceStateFiring, first.CurrentState)
+		require.Equal(t, models.StateHistoryValues{"B": 3}, first.EvaluationValues)
+		require.Empty(t, first.Reason)
+	})
+
+	t.Run("state transitions are filtered by labels", func(t *testing.T) {
+		matcher, err := labels.NewMatcher(labels.MatchEqual, "host", "b")
+		require.NoError(t, err)
+		q := models.ListAlertStateHistoryQuery{OrgID: mainOrgID, Matchers: []*labels.Matcher{matcher}}
+		require.NoError(t, dbstore.ListAlertStateHistory(&q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, models.InstanceStateNormal, q.Result[0].PreviousState)
+		require.Equal(t, models.InstanceStateFiring, q.Result[0].CurrentState)
+		require.Equal(t, "failed to execute query", q.Result[0].Reason)
+	})
+
+	t.Run("state transitions are filtered by time", func(t *testing.T) {
+		q := models.ListAlertStateHistoryQuery{OrgID: mainOrgID, To: evaluationTime.Add(30 * time.Second)}
+		require.NoError(t, dbstore.ListAlertStateHistory(&q))
+		require.Len(t, q.Result, 1)
+
+		q = models.ListAlertStateHistoryQuery{OrgID: mainOrgID, From: evaluationTime.Add(30 * time.Second), Limit: 1}
+		require.NoError(t, dbstore.ListAlertStateHistory(&q))
+		require.Len(t, q.Result, 1)
+	})
+
+	t.Run("old state transitions are deleted", func(t *testing.T) {
+		deleted, err := dbstore.DeleteAlertStateHistory(evaluationTime.Add(30 * time.Second))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), deleted)
+
+		q := models.ListAlertStateHistoryQuery{OrgID: mainOrgID}
+		require.NoError(t, dbstore.ListAlertStateHistory(&q))
+		require.Len(t, q.Result, 2)
+	})
+	t.Run("stale alert instances are recorded as normal", func(t *testing.T) {
+		st.ProcessEvalResults(context.Background(), rule, eval.Results{
+			{
+				Instance:    data.Labels{"host": "a"},
+				State:       eval.Normal,
+				EvaluatedAt: evaluationTime.Add(30 * time.Minute),
+			},
+		})
+
+		matcher, err := labels.NewMatcher(labels.MatchEqual, "host", "b")
+		require.NoError(t, err)
+		q := models.ListAlertStateHistoryQuery{OrgID: mainOrgID, Matchers: []*labels.Matcher{matcher}}
+		require.NoError(t, dbstore.ListAlertStateHistory(&q))
+		require.Len(t, q.Result, 2)
+		require.Equal(t, models.InstanceStateFiring, q.Result[0].PreviousState)
+		require.Equal(t, models.InstanceStateNormal, q.Result[0].CurrentState)
+		require.Equal(t, "MissingSeries", q.Result[0].Reason)
+		require.Equal(t, evaluationTime.Add(30*time.Minute).Unix(), q.Result[0].EvaluatedAt.Unix())
+	})
+
+	t.Run("evaluations keeping the last state are recorded once", func(t *testing.T) {
+		keepRule := tests.CreateTestAlertRule(t, dbstore, 600, mainOrgID)
+		keepRule.NoDataState = models.KeepLastState
+		st.ProcessEvalResults(context.Background(), keepRule, eval.Results{
+			{
+				Instance:    data.Labels{"host": "c"},
+				State:       eval.Alerting,
+				EvaluatedAt: evaluationTime,
+			},
+		})
+		for i := 1; i <= 2; i++ {
+			st.ProcessEvalResults(context.Background(), keepRule, eval.Results{
+				{
+					State:       eval.NoData,
+					EvaluatedAt: evaluationTime.Add(time.Duration(i) * time.Minute),
+				},
+			})
+		}
+
+		q := models.ListAlertStateHistoryQuery{OrgID: mainOrgID, RuleUID: keepRule.UID}
+		require.NoError(t, dbstore.ListAlertStateHistory(&q))
+		require.Len(t, q.Result, 2)
+		require.Equal(t, models.InstanceStateFiring, q.Result[0].PreviousState)
+		require.Equal(t, models.InstanceStateFiring, q.Result[0].CurrentState)
+		require.Equal(t, "NoData", q.Result[0].Reason)
+		require.Equal(t, evaluationTime.Add(time.Minute).Unix(), q.Result[0].EvaluatedAt.Unix())
+	})
+}
+
+type fakeImageService struct {
+	calls     []time.Time
+	forgotten []models.AlertRuleKey
+}
+
+func (f *fakeImageService) NewImage(_ context.Context, _ *models.AlertRule, evaluatedAt time.Time) (*models.Image, error) {
+	f.calls = append(f.calls, evaluatedAt)
+	return &models.Image{Token: evaluatedAt.Format(time.RFC3339)}, nil
+}
+
+func (f *fakeImageService) ForgetAlertRule(key models.AlertRuleKey) {
+	f.forgotten = append(f.forgotten, key)
+}
+
+func TestProcessEvalResultsImages(t *testing.T) {
+	evaluationTime, err := time.Parse("2006-01-02", "2021-03-25")
+	require.NoError(t, err)
+
+	images := &fakeImageService{}
+	st := state.NewManager(log.New("test_state_images"), testMetrics.GetStateMetrics(), nil, nil, nil, nil, images)
+	rule := &models.AlertRule{
+		OrgID:           1,
+		UID:             "rule",
+		Title:           "rule",
+		IntervalSeconds: 10,
+		For:             10 * time.Second,
+		Annotations:     map[string]string{models.DashboardUIDAnnotation: "dashboard", models.PanelIDAnnotation: "1"},
+	}
+	result := func(state eval.State, at time.Time) eval.Results {
+		return eval.Results{{Instance: data.Labels{"host": "a"}, State: state, EvaluatedAt: at}}
+	}
+
+	states := st.ProcessEvalResults(context.Background(), rule, result(eval.Alerting, evaluationTime))
+	require.Equal(t, eval.Pending, states[0].State)
+	require.Nil(t, states[0].Image, "pending alert instances do not have an image")
+
+	states = st.ProcessEvalResults(context.Background(), rule, result(eval.Alerting, evaluationTime.Add(20*time.Second)))
+	require.Equal(t, eval.Alerting, states[0].State)
+	require.NotNil(t, states[0].Image)
+	require.Equal(t, []time.Time{evaluationTime.Add(20 * time.Second)}, images.calls)
+
+	states = st.ProcessEvalResults(context.Background(), rule, result(eval.Alerting, evaluationTime.Add(30*time.Second)))
+	require.Equal(t, evaluationTime.Add(20*time.Second).Format(time.RFC3339), states[0].Image.Token, "the image is kept while the alert instance is firing")
+	require.Len(t, images.calls, 1)
+
+	st.RemoveByRuleUID(rule.OrgID, rule.UID)
+	require.Equal(t, []models.AlertRuleKey{rule.GetKey()}, images.forgotten, "the image of deleted alert rules is forgotten")
+}
@@ -23,6 +23,18 @@ type State struct {
 	Annotations        map[string]string
 	Labels             data.Labels
 	Error              error
+	// SuppressedBy is the UID of the alert rule whose firing alert instance suppresses
+	// this alert instance. Suppressed alert instances are not sent to the Alertmanager.
+	SuppressedBy string
+	// LastSentAsFiring is true if the alert instance was last sent to the Alertmanager as a
+	// firing alert that is not suppressed.
+	LastSentAsFiring bool
+	// LastSentAsError is true if the alert instance was last sent to the Alertmanager as
+	// an alert for a failed evaluation, which has to be resolved with the same labels.
+	LastSentAsError bool
+	// Image is the screenshot of the panel of the alert rule taken when the alert instance
+	// started firing, if any.
+	Image *ngModels.Image
 }
 
 type Evaluation struct {
@@ -85,18 +97,38 @@ func (a *State) resultAlerting(alertRule *ngModels.AlertRule, result eval.Result
 }
 
 func (a *State) resultError(alertRule *ngModels.AlertRule, result eval.Result) {
+	switch alertRule.ExecErrState {
+	case ngModels.OkErrState:
+		a.resultNormal(alertRule, result)
+		return
+	case ngModels.KeepLastStateErrState:
+		a.resultKeepLastState(alertRule, result)
+		return
+	}
+
 	a.Error = result.Error
 	if a.StartsAt.IsZero() {
 		a.StartsAt = result.EvaluatedAt
 	}
 	a.setEndsAt(alertRule, result)
 
-	if alertRule.ExecErrState == ngModels.AlertingErrState {
+	switch alertRule.ExecErrState {
+	case ngModels.AlertingErrState:
 		a.State = eval.Alerting
+	case ngModels.ErrorErrState:
+		if a.State != eval.Error {
+			a.StartsAt = result.EvaluatedAt
+		}
+		a.State = eval.Error
 	}
 }
 
 func (a *State) resultNoData(alertRule *ngModels.AlertRule, result eval.Result) {
+	if alertRule.NoDataState == ngModels.KeepLastState {
+		a.resultKeepLastState(alertRule, result)
+		return
+	}
+
 	if a.StartsAt.IsZero() {
 		a.StartsAt = result.EvaluatedAt
 	}
@@ -112,11 +144,45 @@ func (a *State) resultNoData(alertRule *ngModels.AlertRule, result eval.Result)
 	}
 }
 
+// resultKeepLastState handles a result that failed or has no data for an alert rule
+// that keeps the last state of its alert instances in that case.
+func (a *State) resultKeepLastState(alertRule *ngModels.AlertRule, result eval.Result) {
+	a.Error = result.Error
+	if a.StartsAt.IsZero() {
+		a.StartsAt = result.EvaluatedAt
+		a.EndsAt = result.EvaluatedAt
+	}
+	if a.State == eval.Alerting {
+		a.setEndsAt(alertRule, result)
+	}
+}
+
+// setSuppressedBy suppresses the alert instance while it is firing by the alert rule with
+// the UID, or stops suppressing it if the UID is empty. Suppressed alert instances end at
+// the evaluation, so they are resolved in the Alertmanager if they were sent as firing.
+func (a *State) setSuppressedBy(uid string, evaluatedAt time.Time) {
+	if a.State != eval.Alerting {
+		uid = ""
+	}
+	if uid != "" {
+		a.EndsAt = evaluatedAt
+	} else if a.SuppressedBy != "" {
+		// send the alert instance again as soon as it is not suppressed anymore
+		a.LastSentAt = time.Time{}
+	}
+	a.SuppressedBy = uid
+}
+
 func (a *State) NeedsSending(resendDelay time.Duration) bool {
-	if a.State != eval.Alerting && a.State != eval.Normal {
+	if a.State != eval.Alerting && a.State != eval.Error && a.State != eval.Normal {
 		return false
 	}
 
+	if a.SuppressedBy != "" {
+		// a firing alert instance that becomes suppressed is sent once more, as resolved
+		return a.LastSentAsFiring
+	}
+
 	if a.State == eval.Normal && !a.Resolved {
 		return false
 	}
@@ -125,6 +191,12 @@ func (a *State) NeedsSending(resendDelay time.Duration) bool {
 		a.LastSentAt.Add(resendDelay).Equal(a.LastEvaluationTime)
 }
 
+// NeedsResolvingError returns true if the alert instance was last sent as an alert for a
+// failed evaluation and its evaluation does not fail anymore.
+func (a *State) NeedsResolvingError() bool {
+	return a.LastSentAsError && a.State != eval.Error
+}
+
 func (a *State) Equals(b *State) bool {
 	return a.AlertRuleUID == b.AlertRuleUID &&
 		a.OrgID == b.OrgID &&
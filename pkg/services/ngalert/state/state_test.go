@@ -56,6 +56,16 @@ func TestNeedsSending(t *testing.T) {
 				State: eval.Pending,
 			},
 		},
+		{
+			name:        "state: error and LastSentAt before LastEvaluationTime + ResendDelay",
+			resendDelay: 1 * time.Minute,
+			expected:    true,
+			testState: &State{
+				State:              eval.Error,
+				LastEvaluationTime: evaluationTime,
+				LastSentAt:         evaluationTime.Add(-2 * time.Minute),
+			},
+		},
 		{
 			name:        "state: alerting and ResendDelay is zero",
 			resendDelay: 0 * time.Minute,
@@ -26,15 +26,23 @@ const AlertRuleMaxTitleLength = 190
 // AlertRuleMaxRuleGroupNameLength is the maximum length of the alert rule group name
 const AlertRuleMaxRuleGroupNameLength = 190
 
+// AlertRuleMaxRecordLength is the maximum length of the name of the time series of a recording rule
+const AlertRuleMaxRecordLength = 190
+
 type UpdateRuleGroupCmd struct {
 	OrgID           int64
 	NamespaceUID    string
 	RuleGroupConfig apimodels.PostableRuleGroupConfig
+	// KeepUIDs creates the rules whose UID does not exist with that UID instead of
+	// failing, such as the provisioned rules whose UIDs are set in the files.
+	KeepUIDs bool
 }
 
 type UpsertRule struct {
 	Existing *ngmodels.AlertRule
 	New      ngmodels.AlertRule
+	// KeepUID creates the rule with the UID of New if no rule has that UID.
+	KeepUID bool
 }
 
 // Store is the interface for persisting alert rules and instances
@@ -86,6 +94,11 @@ func (st DBstore) DeleteAlertRuleByUID(orgID int64, ruleUID string) error {
 		if err != nil {
 			return err
 		}
+
+		_, err = sess.Exec("DELETE FROM provenance_type WHERE org_id = ? AND record_type = ? AND record_key = ?", orgID, ngmodels.ProvisionedAlertRule, ruleUID)
+		if err != nil {
+			return err
+		}
 		return nil
 	})
 }
@@ -117,6 +130,10 @@ func (st DBstore) DeleteNamespaceAlertRules(orgID int64, namespaceUID string) ([
 			return err
 		}
 
+		if err := deleteOrphanedRuleProvenance(sess, orgID); err != nil {
+			return err
+		}
+
 		return nil
 	})
 	return ruleUIDs, err
@@ -154,6 +171,10 @@ func (st DBstore) DeleteRuleGroupAlertRules(orgID int64, namespaceUID string, ru
 			return err
 		}
 
+		if err := deleteOrphanedRuleProvenance(sess, orgID); err != nil {
+			return err
+		}
+
 		return nil
 	})
 
@@ -193,23 +214,26 @@ func (st DBstore) UpsertAlertRules(rules []UpsertRule) error {
 			if r.Existing == nil && r.New.UID != "" {
 				// check by UID
 				existingAlertRule, err := getAlertRuleByUID(sess, r.New.UID, r.New.OrgID)
-				if err != nil {
-					if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
-						return fmt.Errorf("failed to get alert rule %s: %w", r.New.UID, err)
-					}
+				switch {
+				case err == nil:
+					r.Existing = existingAlertRule
+				case !errors.Is(err, ngmodels.ErrAlertRuleNotFound):
 					return err
+				case !r.KeepUID:
+					return fmt.Errorf("failed to get alert rule %s: %w", r.New.UID, err)
 				}
-				r.Existing = existingAlertRule
 			}
 
 			var parentVersion int64
 			switch r.Existing {
 			case nil: // new rule
-				uid, err := GenerateNewAlertRuleUID(sess, r.New.OrgID, r.New.Title)
-				if err != nil {
-					return fmt.Errorf("failed to generate UID for alert rule %q: %w", r.New.Title, err)
+				if !r.KeepUID || r.New.UID == "" {
+					uid, err := GenerateNewAlertRuleUID(sess, r.New.OrgID, r.New.Title)
+					if err != nil {
+						return fmt.Errorf("failed to generate UID for alert rule %q: %w", r.New.Title, err)
+					}
+					r.New.UID = uid
 				}
-				r.New.UID = uid
 
 				if r.New.IntervalSeconds == 0 {
 					r.New.IntervalSeconds = int64(st.DefaultInterval.Seconds())
@@ -282,22 +306,28 @@ func (st DBstore) UpsertAlertRules(rules []UpsertRule) error {
 			}
 
 			ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
-				RuleOrgID:        r.New.OrgID,
-				RuleUID:          r.New.UID,
-				RuleNamespaceUID: r.New.NamespaceUID,
-				RuleGroup:        r.New.RuleGroup,
-				ParentVersion:    parentVersion,
-				Version:          r.New.Version,
-				Created:          r.New.Updated,
-				Condition:        r.New.Condition,
-				Title:            r.New.Title,
-				Data:             r.New.Data,
-				IntervalSeconds:  r.New.IntervalSeconds,
-				NoDataState:      r.New.NoDataState,
-				ExecErrState:     r.New.ExecErrState,
-				For:              r.New.For,
-				Annotations:      r.New.Annotations,
-				Labels:           r.New.Labels,
+				RuleOrgID:            r.New.OrgID,
+				RuleUID:              r.New.UID,
+				RuleNamespaceUID:     r.New.NamespaceUID,
+				RuleGroup:            r.New.RuleGroup,
+				ParentVersion:        parentVersion,
+				Version:              r.New.Version,
+				Created:              r.New.Updated,
+				Condition:            r.New.Condition,
+				Title:                r.New.Title,
+				Data:                 r.New.Data,
+				IntervalSeconds:      r.New.IntervalSeconds,
+				NoDataState:          r.New.NoDataState,
+				ExecErrState:         r.New.ExecErrState,
+				For:                  r.New.For,
+				Annotations:          r.New.Annotations,
+				Labels:               r.New.Labels,
+				IsPaused:             r.New.IsPaused,
+				IsGroupPaused:        r.New.IsGroupPaused,
+				Record:               r.New.Record,
+				RecordTarget:         r.New.RecordTarget,
+				SuppressedByRuleUIDs: r.New.SuppressedByRuleUIDs,
+				SuppressedByLabels:   r.New.SuppressedByLabels,
 			})
 		}
 
@@ -438,12 +468,12 @@ func (st DBstore) GetNamespaceByTitle(ctx context.Context, namespace string, org
 	return folder, nil
 }
 
-// GetAlertRulesForScheduling returns alert rule info (identifier, interval, version state)
+// GetAlertRulesForScheduling returns alert rule info (identifier, interval, version, paused state)
 // that is useful for it's scheduling.
 func (st DBstore) GetAlertRulesForScheduling(query *ngmodels.ListAlertRulesQuery) error {
 	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
 		alerts := make([]*ngmodels.AlertRule, 0)
-		q := "SELECT uid, org_id, interval_seconds, version FROM alert_rule"
+		q := "SELECT uid, org_id, interval_seconds, version, is_paused, is_group_paused FROM alert_rule"
 		if len(query.ExcludeOrgs) > 0 {
 			q = fmt.Sprintf("%s WHERE org_id NOT IN (%s)", q, strings.Join(strings.Split(strings.Trim(fmt.Sprint(query.ExcludeOrgs), "[]"), " "), ","))
 		}
@@ -503,6 +533,11 @@ func (st DBstore) validateAlertRule(alertRule ngmodels.AlertRule) error {
 		return fmt.Errorf("%w: no organisation is found", ngmodels.ErrAlertRuleFailedValidation)
 	}
 
+	// enfore max record length in SQLite
+	if len(alertRule.Record) > AlertRuleMaxRecordLength {
+		return fmt.Errorf("%w: record length should not be greater than %d", ngmodels.ErrAlertRuleFailedValidation, AlertRuleMaxRecordLength)
+	}
+
 	if alertRule.DashboardUID == nil && alertRule.PanelID != nil {
 		return fmt.Errorf("%w: cannot have Panel ID without a Dashboard UID", ngmodels.ErrAlertRuleFailedValidation)
 	}
@@ -546,6 +581,18 @@ func (st DBstore) UpdateRuleGroup(cmd UpdateRuleGroupCmd) error {
 				RuleGroup:       ruleGroup,
 				NoDataState:     ngmodels.NoDataState(r.GrafanaManagedAlert.NoDataState),
 				ExecErrState:    ngmodels.ExecutionErrorState(r.GrafanaManagedAlert.ExecErrState),
+				IsPaused:        r.GrafanaManagedAlert.IsPaused,
+				IsGroupPaused:   cmd.RuleGroupConfig.IsPaused,
+			}
+
+			if record := r.GrafanaManagedAlert.Record; record != nil {
+				newAlertRule.Record = record.Metric
+				newAlertRule.RecordTarget = ngmodels.RecordTarget(record.Target)
+			}
+
+			if suppressedBy := r.GrafanaManagedAlert.SuppressedBy; suppressedBy != nil {
+				newAlertRule.SuppressedByRuleUIDs = suppressedBy.RuleUIDs
+				newAlertRule.SuppressedByLabels = suppressedBy.Labels
 			}
 
 			if r.ApiRuleNode != nil {
@@ -567,7 +614,8 @@ func (st DBstore) UpdateRuleGroup(cmd UpdateRuleGroupCmd) error {
 			}
 
 			upsertRule := UpsertRule{
-				New: newAlertRule,
+				New:     newAlertRule,
+				KeepUID: cmd.KeepUIDs,
 			}
 
 			if existingGroupRule, ok := existingGroupRulesUIDs[r.GrafanaManagedAlert.UID]; ok {
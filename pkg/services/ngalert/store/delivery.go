@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// DeliveryStore is the interface for persisting the attempts of contact points to send
+// notifications.
+type DeliveryStore interface {
+	SaveNotificationDelivery(ctx context.Context, delivery *models.NotificationDelivery) error
+	ListNotificationDeliveries(ctx context.Context, query *models.ListNotificationDeliveriesQuery) error
+	DeleteNotificationDeliveries(ctx context.Context, olderThan time.Time) (int64, error)
+}
+
+// SaveNotificationDelivery saves an attempt of a contact point to send a notification.
+func (st DBstore) SaveNotificationDelivery(ctx context.Context, delivery *models.NotificationDelivery) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		delivery.SentAt = delivery.SentAt.UTC()
+		_, err := sess.Table("alert_notification_delivery").Insert(delivery)
+		return err
+	})
+}
+
+// ListNotificationDeliveries returns the attempts of a contact point of an organization to
+// send notifications that match the query, from the most recent.
+func (st DBstore) ListNotificationDeliveries(ctx context.Context, query *models.ListNotificationDeliveriesQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		q := sess.Table("alert_notification_delivery").Where("org_id = ? AND receiver = ?", query.OrgID, query.Receiver)
+
+		if query.IntegrationUID != "" {
+			q = q.And("integration_uid = ?", query.IntegrationUID)
+		}
+
+		if query.Status != "" {
+			q = q.And("status = ?", query.Status)
+		}
+
+		if !query.From.IsZero() {
+			q = q.And("sent_at >= ?", query.From.UTC())
+		}
+
+		if !query.To.IsZero() {
+			q = q.And("sent_at <= ?", query.To.UTC())
+		}
+
+		q = q.Desc("sent_at", "id")
+		if query.Limit > 0 {
+			q = q.Limit(query.Limit)
+		}
+
+		deliveries := make([]*models.NotificationDelivery, 0)
+		if err := q.Find(&deliveries); err != nil {
+			return err
+		}
+
+		query.Result = deliveries
+		return nil
+	})
+}
+
+// DeleteNotificationDeliveries deletes the attempts to send notifications that are older than olderThan,
+// and returns the number of deleted attempts.
+func (st DBstore) DeleteNotificationDeliveries(ctx context.Context, olderThan time.Time) (int64, error) {
+	var affected int64
+	err := st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_notification_delivery WHERE sent_at < ?", olderThan.UTC())
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
@@ -0,0 +1,91 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestNotificationDeliveryOperations(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	deliveries := []*models.NotificationDelivery{
+		{OrgID: 1, Receiver: "team-a", IntegrationType: "slack", IntegrationUID: "slack", GroupKey: "{}:{}", Alerts: 1, Attempt: 1, Status: models.NotificationFailed, StatusCode: 500, Error: "failed", SentAt: now.Add(-2 * time.Hour)},
+		{OrgID: 1, Receiver: "team-a", IntegrationType: "slack", IntegrationUID: "slack", GroupKey: "{}:{}", Alerts: 1, Attempt: 2, Status: models.NotificationDelivered, StatusCode: 200, SentAt: now.Add(-time.Hour)},
+		{OrgID: 1, Receiver: "team-a", IntegrationType: "email", IntegrationUID: "email", GroupKey: "{}:{}", Alerts: 1, Attempt: 1, Status: models.NotificationDelivered, SentAt: now.Add(-time.Hour)},
+		{OrgID: 1, Receiver: "team-b", IntegrationType: "email", IntegrationUID: "email-b", GroupKey: "{}:{}", Alerts: 2, Attempt: 1, Status: models.NotificationDelivered, SentAt: now},
+		{OrgID: 2, Receiver: "team-a", IntegrationType: "email", IntegrationUID: "email-2", GroupKey: "{}:{}", Alerts: 2, Attempt: 1, Status: models.NotificationDelivered, SentAt: now},
+	}
+	for _, d := range deliveries {
+		require.NoError(t, dbstore.SaveNotificationDelivery(ctx, d))
+	}
+
+	cases := []struct {
+		desc     string
+		query    models.ListNotificationDeliveriesQuery
+		expected []*models.NotificationDelivery
+	}{
+		{
+			desc:     "attempts of a contact point from the most recent",
+			query:    models.ListNotificationDeliveriesQuery{OrgID: 1, Receiver: "team-a"},
+			expected: []*models.NotificationDelivery{deliveries[2], deliveries[1], deliveries[0]},
+		},
+		{
+			desc:     "attempts of an integration",
+			query:    models.ListNotificationDeliveriesQuery{OrgID: 1, Receiver: "team-a", IntegrationUID: "slack"},
+			expected: []*models.NotificationDelivery{deliveries[1], deliveries[0]},
+		},
+		{
+			desc:     "attempts with a status",
+			query:    models.ListNotificationDeliveriesQuery{OrgID: 1, Receiver: "team-a", Status: models.NotificationFailed},
+			expected: []*models.NotificationDelivery{deliveries[0]},
+		},
+		{
+			desc:     "attempts in a time range",
+			query:    models.ListNotificationDeliveriesQuery{OrgID: 1, Receiver: "team-a", From: now.Add(-90 * time.Minute), To: now},
+			expected: []*models.NotificationDelivery{deliveries[2], deliveries[1]},
+		},
+		{
+			desc:     "limited number of attempts",
+			query:    models.ListNotificationDeliveriesQuery{OrgID: 1, Receiver: "team-a", Limit: 1},
+			expected: []*models.NotificationDelivery{deliveries[2]},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.NoError(t, dbstore.ListNotificationDeliveries(ctx, &tc.query))
+			ids := make([]int64, 0, len(tc.query.Result))
+			for _, d := range tc.query.Result {
+				ids = append(ids, d.ID)
+			}
+			expected := make([]int64, 0, len(tc.expected))
+			for _, d := range tc.expected {
+				expected = append(expected, d.ID)
+			}
+			require.Equal(t, expected, ids)
+		})
+	}
+
+	t.Run("old attempts are deleted", func(t *testing.T) {
+		deleted, err := dbstore.DeleteNotificationDeliveries(ctx, now.Add(-90*time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), deleted)
+
+		q := models.ListNotificationDeliveriesQuery{OrgID: 1, Receiver: "team-a"}
+		require.NoError(t, dbstore.ListNotificationDeliveries(ctx, &q))
+		require.Len(t, q.Result, 2)
+		require.Equal(t, 200, q.Result[1].StatusCode)
+		require.Equal(t, "slack", q.Result[1].IntegrationType)
+		require.Equal(t, models.NotificationDelivered, q.Result[1].Status)
+	})
+}
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// ImageStore is the interface for persisting the images of alert rules.
+type ImageStore interface {
+	// GetImage returns the image with the token, or models.ErrImageNotFound if it does
+	// not exist or has expired.
+	GetImage(ctx context.Context, token string) (*models.Image, error)
+	// SaveImage saves a new image, and sets its token.
+	SaveImage(ctx context.Context, image *models.Image) error
+	// DeleteExpiredImages deletes the expired images and returns the paths of their files.
+	DeleteExpiredImages(ctx context.Context) ([]string, error)
+}
+
+// GetImage returns the image with the token, or models.ErrImageNotFound if it does not exist or has expired.
+func (st DBstore) GetImage(ctx context.Context, token string) (*models.Image, error) {
+	var image models.Image
+	err := st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		exists, err := sess.Table("alert_image").Where("token = ? AND expires_at > ?", token, TimeNow().UTC()).Get(&image)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return models.ErrImageNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+// SaveImage saves a new image, and sets its token.
+func (st DBstore) SaveImage(ctx context.Context, image *models.Image) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		image.Token = util.GenerateShortUID()
+		image.CreatedAt = image.CreatedAt.UTC()
+		image.ExpiresAt = image.ExpiresAt.UTC()
+		_, err := sess.Table("alert_image").Insert(image)
+		return err
+	})
+}
+
+// DeleteExpiredImages deletes the expired images and returns the paths of their files.
+func (st DBstore) DeleteExpiredImages(ctx context.Context) ([]string, error) {
+	var paths []string
+	err := st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		now := TimeNow().UTC()
+		var images []*models.Image
+		if err := sess.Table("alert_image").Where("expires_at <= ?", now).Find(&images); err != nil {
+			return err
+		}
+		if len(images) == 0 {
+			return nil
+		}
+		if _, err := sess.Exec("DELETE FROM alert_image WHERE expires_at <= ?", now); err != nil {
+			return err
+		}
+		for _, image := range images {
+			paths = append(paths, image.Path)
+		}
+		return nil
+	})
+	return paths, err
+}
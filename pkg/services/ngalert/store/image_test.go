@@ -0,0 +1,52 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestImageOperations(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	ctx := context.Background()
+	now := time.Now()
+
+	image := &models.Image{Path: "/tmp/image.png", URL: "http://images/image.png", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	require.NoError(t, dbstore.SaveImage(ctx, image))
+	require.NotEmpty(t, image.Token)
+
+	expired := &models.Image{Path: "/tmp/expired.png", CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)}
+	require.NoError(t, dbstore.SaveImage(ctx, expired))
+
+	t.Run("images are returned by token", func(t *testing.T) {
+		saved, err := dbstore.GetImage(ctx, image.Token)
+		require.NoError(t, err)
+		require.Equal(t, image.Path, saved.Path)
+		require.Equal(t, image.URL, saved.URL)
+		require.True(t, saved.HasURL())
+	})
+
+	t.Run("expired and unknown images are not found", func(t *testing.T) {
+		_, err := dbstore.GetImage(ctx, expired.Token)
+		require.ErrorIs(t, err, models.ErrImageNotFound)
+		_, err = dbstore.GetImage(ctx, "unknown")
+		require.ErrorIs(t, err, models.ErrImageNotFound)
+	})
+
+	t.Run("expired images are deleted", func(t *testing.T) {
+		paths, err := dbstore.DeleteExpiredImages(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []string{expired.Path}, paths)
+
+		_, err = dbstore.GetImage(ctx, image.Token)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// ProvisioningStore is the interface for persisting where resources are managed from.
+type ProvisioningStore interface {
+	GetProvenance(orgID int64, recordType models.ProvisionedResourceType, recordKey string) (models.Provenance, error)
+	GetProvenances(orgID int64, recordType models.ProvisionedResourceType) (map[string]models.Provenance, error)
+	SetProvenance(orgID int64, recordType models.ProvisionedResourceType, recordKey string, provenance models.Provenance) error
+}
+
+// GetProvenance returns the provenance of a resource, or ProvenanceNone if it is not recorded.
+func (st DBstore) GetProvenance(orgID int64, recordType models.ProvisionedResourceType, recordKey string) (models.Provenance, error) {
+	provenance := models.ProvenanceNone
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		record := models.ProvenanceRecord{}
+		has, err := sess.Table("provenance_type").Where("org_id = ? AND record_type = ? AND record_key = ?", orgID, recordType, recordKey).Get(&record)
+		if err != nil {
+			return err
+		}
+		if has {
+			provenance = record.Provenance
+		}
+		return nil
+	})
+	return provenance, err
+}
+
+// GetProvenances returns the provenance of the resources of a type of an organization by key.
+func (st DBstore) GetProvenances(orgID int64, recordType models.ProvisionedResourceType) (map[string]models.Provenance, error) {
+	provenances := make(map[string]models.Provenance)
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		var records []*models.ProvenanceRecord
+		if err := sess.Table("provenance_type").Where("org_id = ? AND record_type = ?", orgID, recordType).Find(&records); err != nil {
+			return err
+		}
+		for _, record := range records {
+			provenances[record.RecordKey] = record.Provenance
+		}
+		return nil
+	})
+	return provenances, err
+}
+
+// SetProvenance records the provenance of a resource. Setting ProvenanceNone deletes the record.
+func (st DBstore) SetProvenance(orgID int64, recordType models.ProvisionedResourceType, recordKey string, provenance models.Provenance) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		if _, err := sess.Exec("DELETE FROM provenance_type WHERE org_id = ? AND record_type = ? AND record_key = ?", orgID, recordType, recordKey); err != nil {
+			return err
+		}
+		if provenance == models.ProvenanceNone {
+			return nil
+		}
+		_, err := sess.Table("provenance_type").Insert(&models.ProvenanceRecord{
+			OrgID:      orgID,
+			RecordKey:  recordKey,
+			RecordType: recordType,
+			Provenance: provenance,
+		})
+		return err
+	})
+}
+
+// deleteOrphanedRuleProvenance deletes the provenance of the alert rules of an organization
+// that do not exist anymore.
+func deleteOrphanedRuleProvenance(sess *sqlstore.DBSession, orgID int64) error {
+	_, err := sess.Exec(`DELETE FROM provenance_type WHERE org_id = ? AND record_type = ? AND record_key NOT IN (
+		SELECT uid FROM alert_rule where org_id = ?
+	)`, orgID, models.ProvisionedAlertRule, orgID)
+	return err
+}
@@ -0,0 +1,65 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestProvenanceOperations(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	const mainOrgID int64 = 1
+
+	alertRule1 := tests.CreateTestAlertRule(t, dbstore, 60, mainOrgID)
+	alertRule2 := tests.CreateTestAlertRule(t, dbstore, 60, mainOrgID)
+
+	t.Run("resources without a record are not provisioned", func(t *testing.T) {
+		provenance, err := dbstore.GetProvenance(mainOrgID, models.ProvisionedAlertRule, alertRule1.UID)
+		require.NoError(t, err)
+		require.Equal(t, models.ProvenanceNone, provenance)
+	})
+
+	t.Run("can set and read the provenance of resources", func(t *testing.T) {
+		require.NoError(t, dbstore.SetProvenance(mainOrgID, models.ProvisionedAlertRule, alertRule1.UID, models.ProvenanceFile))
+		require.NoError(t, dbstore.SetProvenance(mainOrgID, models.ProvisionedAlertRule, alertRule1.UID, models.ProvenanceFile))
+		require.NoError(t, dbstore.SetProvenance(mainOrgID, models.ProvisionedAlertRule, alertRule2.UID, models.ProvenanceFile))
+		require.NoError(t, dbstore.SetProvenance(mainOrgID, models.ProvisionedAlertmanagerConfiguration, "", models.ProvenanceFile))
+
+		provenance, err := dbstore.GetProvenance(mainOrgID, models.ProvisionedAlertRule, alertRule1.UID)
+		require.NoError(t, err)
+		require.Equal(t, models.ProvenanceFile, provenance)
+
+		provenances, err := dbstore.GetProvenances(mainOrgID, models.ProvisionedAlertRule)
+		require.NoError(t, err)
+		require.Equal(t, map[string]models.Provenance{alertRule1.UID: models.ProvenanceFile, alertRule2.UID: models.ProvenanceFile}, provenances)
+
+		provenances, err = dbstore.GetProvenances(mainOrgID+1, models.ProvisionedAlertRule)
+		require.NoError(t, err)
+		require.Empty(t, provenances)
+	})
+
+	t.Run("setting no provenance deletes the record", func(t *testing.T) {
+		require.NoError(t, dbstore.SetProvenance(mainOrgID, models.ProvisionedAlertmanagerConfiguration, "", models.ProvenanceNone))
+
+		provenance, err := dbstore.GetProvenance(mainOrgID, models.ProvisionedAlertmanagerConfiguration, "")
+		require.NoError(t, err)
+		require.Equal(t, models.ProvenanceNone, provenance)
+	})
+
+	t.Run("the provenance of deleted alert rules is deleted", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteAlertRuleByUID(mainOrgID, alertRule1.UID))
+		_, err := dbstore.DeleteRuleGroupAlertRules(mainOrgID, alertRule2.NamespaceUID, alertRule2.RuleGroup)
+		require.NoError(t, err)
+
+		provenances, err := dbstore.GetProvenances(mainOrgID, models.ProvisionedAlertRule)
+		require.NoError(t, err)
+		require.Empty(t, provenances)
+	})
+}
@@ -0,0 +1,116 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// StateHistoryStore is the interface for persisting the state transitions of alert instances.
+type StateHistoryStore interface {
+	SaveAlertStateHistory(entries []models.AlertStateHistoryEntry) error
+	ListAlertStateHistory(query *models.ListAlertStateHistoryQuery) error
+	DeleteAlertStateHistory(olderThan time.Time) (int64, error)
+}
+
+// SaveAlertStateHistory saves state transitions of alert instances.
+func (st DBstore) SaveAlertStateHistory(entries []models.AlertStateHistoryEntry) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		for _, entry := range entries {
+			labelTupleJSON, labelsHash, err := entry.Labels.StringAndHash()
+			if err != nil {
+				return err
+			}
+			values, err := entry.EvaluationValues.ToDB()
+			if err != nil {
+				return err
+			}
+
+			sql := `INSERT INTO alert_state_history
+				(rule_org_id, rule_uid, labels, labels_hash, previous_state, current_state, reason, evaluation_values, evaluated_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+			params := append(make([]interface{}, 0), sql, entry.RuleOrgID, entry.RuleUID, labelTupleJSON, labelsHash, entry.PreviousState, entry.CurrentState, entry.Reason, string(values), entry.EvaluatedAt.Unix())
+			if _, err := sess.Exec(params...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListAlertStateHistory returns the state transitions of the alert instances of an organization
+// that match the query, from the most recent.
+func (st DBstore) ListAlertStateHistory(query *models.ListAlertStateHistoryQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		s := strings.Builder{}
+		params := make([]interface{}, 0)
+
+		addToQuery := func(stmt string, p ...interface{}) {
+			s.WriteString(stmt)
+			params = append(params, p...)
+		}
+
+		addToQuery("SELECT rule_uid, labels, labels_hash, previous_state, current_state, reason, evaluation_values, evaluated_at"+
+			" FROM alert_state_history WHERE rule_org_id = ?", query.OrgID)
+
+		if query.RuleUID != "" {
+			addToQuery(" AND rule_uid = ?", query.RuleUID)
+		}
+
+		if !query.From.IsZero() {
+			addToQuery(" AND evaluated_at >= ?", query.From.Unix())
+		}
+
+		if !query.To.IsZero() {
+			addToQuery(" AND evaluated_at <= ?", query.To.Unix())
+		}
+
+		addToQuery(" ORDER BY evaluated_at DESC, id DESC")
+
+		rows, err := sess.SQL(s.String(), params...).Rows(&models.AlertStateHistoryEntry{})
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = rows.Close()
+		}()
+
+		// the labels are filtered here as they are stored as json
+		entries := make([]*models.AlertStateHistoryEntry, 0)
+		for rows.Next() {
+			entry := &models.AlertStateHistoryEntry{}
+			if err := rows.Scan(entry); err != nil {
+				return err
+			}
+			if !query.Matches(entry) {
+				continue
+			}
+			entry.RuleOrgID = query.OrgID
+			entries = append(entries, entry)
+			if query.Limit > 0 && len(entries) >= query.Limit {
+				break
+			}
+		}
+
+		query.Result = entries
+		return nil
+	})
+}
+
+// DeleteAlertStateHistory deletes the state transitions of alert instances that are older than olderThan,
+// and returns the number of deleted transitions.
+func (st DBstore) DeleteAlertStateHistory(olderThan time.Time) (int64, error) {
+	var affected int64
+	err := st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_state_history WHERE evaluated_at < ?", olderThan.Unix())
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
@@ -34,7 +34,7 @@ func SetupTestEnv(t *testing.T, baseInterval time.Duration) (*ngalert.AlertNG, *
 	m := metrics.NewNGAlert(prometheus.NewRegistry())
 	ng, err := ngalert.ProvideService(
 		cfg, nil, routing.NewRouteRegister(), sqlstore.InitTestDB(t),
-		nil, nil, nil, nil, ossencryption.ProvideService(), m,
+		nil, nil, nil, nil, ossencryption.ProvideService(), nil, nil, m,
 	)
 	require.NoError(t, err)
 	return ng, &store.DBstore{
@@ -131,3 +131,34 @@ func UpdateTestAlertRuleIntervalSeconds(t *testing.T, dbstore *store.DBstore, ex
 	t.Logf("alert definition: %v with interval: %d created", rule.GetKey(), rule.IntervalSeconds)
 	return rule
 }
+
+// UpdateTestAlertRuleGroupIsPaused pauses or resumes the rule group of a dummy alert definition to be used by the tests.
+func UpdateTestAlertRuleGroupIsPaused(t *testing.T, dbstore *store.DBstore, existingRule *models.AlertRule, isPaused bool) *models.AlertRule {
+	cmd := store.UpdateRuleGroupCmd{
+		OrgID:        existingRule.OrgID,
+		NamespaceUID: existingRule.NamespaceUID,
+		RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+			Name:     existingRule.RuleGroup,
+			Interval: model.Duration(time.Duration(existingRule.IntervalSeconds) * time.Second),
+			Rules: []apimodels.PostableExtendedRuleNode{
+				{
+					GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+						UID: existingRule.UID,
+					},
+				},
+			},
+			IsPaused: isPaused,
+		},
+	}
+
+	err := dbstore.UpdateRuleGroup(cmd)
+	require.NoError(t, err)
+
+	q := models.GetAlertRuleByUIDQuery{OrgID: existingRule.OrgID, UID: existingRule.UID}
+	err = dbstore.GetAlertRuleByUID(&q)
+	require.NoError(t, err)
+	require.Equal(t, isPaused, q.Result.IsGroupPaused)
+
+	t.Logf("alert definition: %v with group paused: %t updated", q.Result.GetKey(), q.Result.IsGroupPaused)
+	return q.Result
+}
@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"net"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/net/context/ctxhttp"
@@ -41,6 +42,26 @@ var netClient = &http.Client{
 	Transport: netTransport,
 }
 
+type responseStatusCodeKey struct{}
+
+// WithResponseStatusCode returns a context that records the HTTP status code of the
+// responses to the webhooks sent with it, and a function that returns the status code of
+// the last response, or 0 if there was none.
+func WithResponseStatusCode(ctx context.Context) (context.Context, func() int) {
+	code := new(int64)
+	return context.WithValue(ctx, responseStatusCodeKey{}, code), func() int {
+		return int(atomic.LoadInt64(code))
+	}
+}
+
+// SetResponseStatusCode records the HTTP status code of a response in a context created
+// with WithResponseStatusCode. It does nothing for other contexts.
+func SetResponseStatusCode(ctx context.Context, code int) {
+	if c, ok := ctx.Value(responseStatusCodeKey{}).(*int64); ok {
+		atomic.StoreInt64(c, int64(code))
+	}
+}
+
 func (ns *NotificationService) sendWebRequestSync(ctx context.Context, webhook *Webhook) error {
 	ns.log.Debug("Sending webhook", "url", webhook.Url, "http method", webhook.HttpMethod)
 
@@ -81,6 +102,7 @@ func (ns *NotificationService) sendWebRequestSync(ctx context.Context, webhook *
 			ns.log.Warn("Failed to close response body", "err", err)
 		}
 	}()
+	SetResponseStatusCode(ctx, resp.StatusCode)
 
 	if resp.StatusCode/100 == 2 {
 		ns.log.Debug("Webhook succeeded", "url", webhook.Url, "statuscode", resp.Status)
@@ -0,0 +1,37 @@
+package notifications
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+func TestSendWebRequestSyncStatusCode(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	ns := &NotificationService{log: log.New("notifications.test")}
+
+	t.Run("the status code of the response is recorded in the context", func(t *testing.T) {
+		ctx, statusCode := WithResponseStatusCode(context.Background())
+		require.NoError(t, ns.sendWebRequestSync(ctx, &Webhook{Url: server.URL}))
+		require.Equal(t, http.StatusOK, statusCode())
+
+		status = http.StatusServiceUnavailable
+		require.Error(t, ns.sendWebRequestSync(ctx, &Webhook{Url: server.URL}))
+		require.Equal(t, http.StatusServiceUnavailable, statusCode())
+	})
+
+	t.Run("contexts without a status code are ignored", func(t *testing.T) {
+		status = http.StatusOK
+		require.NoError(t, ns.sendWebRequestSync(context.Background(), &Webhook{Url: server.URL}))
+	})
+}
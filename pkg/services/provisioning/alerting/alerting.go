@@ -0,0 +1,21 @@
+package alerting
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngprovisioning "github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+)
+
+// Provision the alert rules and the Alertmanager configurations of unified alerting
+func Provision(ctx context.Context, configDirectory string, service *ngprovisioning.Service) error {
+	cr := &configReader{log: log.New("provisioning.alerting")}
+	f, err := cr.readConfig(configDirectory)
+	if err != nil {
+		return err
+	}
+	if f == nil {
+		return nil
+	}
+	return service.Apply(ctx, f)
+}
@@ -0,0 +1,101 @@
+package alerting
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngprovisioning "github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+)
+
+type configReader struct {
+	log log.Logger
+}
+
+// readConfig reads the alerting provisioning files of the directory, and merges them in
+// a single file. It returns nil if the directory has no files.
+func (cr *configReader) readConfig(path string) (*ngprovisioning.File, error) {
+	cr.log.Debug("Looking for alerting provisioning files", "path", path)
+
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		cr.log.Error("Can't read alerting provisioning files from directory", "path", path, "error", err)
+		return nil, nil
+	}
+
+	var merged *ngprovisioning.File
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".yaml") && !strings.HasSuffix(file.Name(), ".yml") {
+			continue
+		}
+		cr.log.Debug("Parsing alerting provisioning file", "path", path, "file.Name", file.Name())
+		f, err := cr.parseConfig(path, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file.Name(), err)
+		}
+		if merged == nil {
+			merged = f
+		} else {
+			merged.Merge(f)
+		}
+	}
+	if merged == nil {
+		return nil, nil
+	}
+
+	cr.log.Debug("Validating alerting provisioning files")
+	if err := checkOrgs(merged); err != nil {
+		return nil, err
+	}
+	if err := merged.Validate(); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+func (cr *configReader) parseConfig(path string, file os.FileInfo) (*ngprovisioning.File, error) {
+	filename, _ := filepath.Abs(filepath.Join(path, file.Name()))
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `filename` comes from ps.Cfg.ProvisioningPath
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ngprovisioning.ParseFile(data)
+}
+
+// checkOrgs sets the resources without an organization to the main organization, and
+// checks that the organizations exist.
+func checkOrgs(f *ngprovisioning.File) error {
+	orgIDs := make(map[int64]struct{})
+	orgID := func(id *int64) {
+		if *id < 1 {
+			*id = 1
+		}
+		orgIDs[*id] = struct{}{}
+	}
+	for _, g := range f.Groups {
+		orgID(&g.OrgID)
+	}
+	for _, g := range f.DeleteGroups {
+		orgID(&g.OrgID)
+	}
+	for _, c := range f.AlertmanagerConfigs {
+		orgID(&c.OrgID)
+	}
+	for _, c := range f.DeleteAlertmanagerConfigs {
+		orgID(&c.OrgID)
+	}
+
+	for id := range orgIDs {
+		if err := utils.CheckOrgExists(id); err != nil {
+			return fmt.Errorf("failed to provision alerting resources of organization %d: %w", id, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package alerting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+const (
+	correctProperties = "testdata/correct-properties"
+	brokenYaml        = "testdata/broken-yaml"
+	unknownOrg        = "testdata/unknown-org"
+	emptyFolder       = "testdata/empty_folder"
+)
+
+func TestConfigReader(t *testing.T) {
+	bus.ClearBusHandlers()
+	bus.AddHandler("test", func(q *models.GetOrgByIdQuery) error {
+		if q.Id != 1 {
+			return models.ErrOrgNotFound
+		}
+		return nil
+	})
+
+	cr := &configReader{log: log.New("test logger")}
+
+	t.Run("can read correct properties", func(t *testing.T) {
+		f, err := cr.readConfig(correctProperties)
+		require.NoError(t, err)
+		require.NotNil(t, f)
+
+		require.Len(t, f.Groups, 1)
+		require.Equal(t, int64(1), f.Groups[0].OrgID)
+		require.Equal(t, "cpu-high", f.Groups[0].Rules[0].UID)
+		require.Len(t, f.DeleteGroups, 1)
+		require.Equal(t, int64(1), f.DeleteGroups[0].OrgID)
+		require.Len(t, f.AlertmanagerConfigs, 1)
+		require.Equal(t, "ops", f.AlertmanagerConfigs[0].Policies.Receiver)
+	})
+
+	t.Run("broken yaml should return error", func(t *testing.T) {
+		_, err := cr.readConfig(brokenYaml)
+		require.Error(t, err)
+	})
+
+	t.Run("unknown organization should return error", func(t *testing.T) {
+		_, err := cr.readConfig(unknownOrg)
+		require.ErrorIs(t, err, models.ErrOrgNotFound)
+	})
+
+	t.Run("empty folder should return nothing", func(t *testing.T) {
+		f, err := cr.readConfig(emptyFolder)
+		require.NoError(t, err)
+		require.Nil(t, f)
+	})
+
+	t.Run("missing folder should return nothing", func(t *testing.T) {
+		f, err := cr.readConfig("testdata/missing")
+		require.NoError(t, err)
+		require.Nil(t, f)
+	})
+}
@@ -9,6 +9,9 @@ import (
 	plugifaces "github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/services/ngalert"
+	ngprovisioning "github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/provisioning/alerting"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
 	"github.com/grafana/grafana/pkg/services/provisioning/notifiers"
@@ -19,17 +22,19 @@ import (
 )
 
 func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, pluginManager plugifaces.Manager,
-	encryptionService encryption.Service) (*ProvisioningServiceImpl, error) {
+	encryptionService encryption.Service, alertNG *ngalert.AlertNG) (*ProvisioningServiceImpl, error) {
 	s := &ProvisioningServiceImpl{
 		Cfg:                     cfg,
 		SQLStore:                sqlStore,
 		PluginManager:           pluginManager,
 		EncryptionService:       encryptionService,
+		AlertNG:                 alertNG,
 		log:                     log.New("provisioning"),
 		newDashboardProvisioner: dashboards.New,
 		provisionNotifiers:      notifiers.Provision,
 		provisionDatasources:    datasources.Provision,
 		provisionPlugins:        plugins.Provision,
+		provisionAlerting:       alerting.Provision,
 	}
 	return s, nil
 }
@@ -40,6 +45,7 @@ type ProvisioningService interface {
 	ProvisionDatasources(ctx context.Context) error
 	ProvisionPlugins() error
 	ProvisionNotifications() error
+	ProvisionAlerting(ctx context.Context) error
 	ProvisionDashboards(ctx context.Context) error
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
@@ -53,6 +59,7 @@ func NewProvisioningServiceImpl() *ProvisioningServiceImpl {
 		provisionNotifiers:      notifiers.Provision,
 		provisionDatasources:    datasources.Provision,
 		provisionPlugins:        plugins.Provision,
+		provisionAlerting:       alerting.Provision,
 	}
 }
 
@@ -62,6 +69,7 @@ func newProvisioningServiceImpl(
 	provisionNotifiers func(string, encryption.Service) error,
 	provisionDatasources func(context.Context, string) error,
 	provisionPlugins func(string, plugifaces.Manager) error,
+	provisionAlerting func(context.Context, string, *ngprovisioning.Service) error,
 ) *ProvisioningServiceImpl {
 	return &ProvisioningServiceImpl{
 		log:                     log.New("provisioning"),
@@ -69,6 +77,7 @@ func newProvisioningServiceImpl(
 		provisionNotifiers:      provisionNotifiers,
 		provisionDatasources:    provisionDatasources,
 		provisionPlugins:        provisionPlugins,
+		provisionAlerting:       provisionAlerting,
 	}
 }
 
@@ -77,6 +86,7 @@ type ProvisioningServiceImpl struct {
 	SQLStore                *sqlstore.SQLStore
 	PluginManager           plugifaces.Manager
 	EncryptionService       encryption.Service
+	AlertNG                 *ngalert.AlertNG
 	log                     log.Logger
 	pollingCtxCancel        context.CancelFunc
 	newDashboardProvisioner dashboards.DashboardProvisionerFactory
@@ -84,6 +94,7 @@ type ProvisioningServiceImpl struct {
 	provisionNotifiers      func(string, encryption.Service) error
 	provisionDatasources    func(context.Context, string) error
 	provisionPlugins        func(string, plugifaces.Manager) error
+	provisionAlerting       func(context.Context, string, *ngprovisioning.Service) error
 	mutex                   sync.Mutex
 }
 
@@ -103,6 +114,11 @@ func (ps *ProvisioningServiceImpl) RunInitProvisioners(ctx context.Context) erro
 		return err
 	}
 
+	err = ps.ProvisionAlerting(ctx)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -153,6 +169,16 @@ func (ps *ProvisioningServiceImpl) ProvisionNotifications() error {
 	return errutil.Wrap("Alert notification provisioning error", err)
 }
 
+func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error {
+	// the provisioning service of unified alerting is nil when it is disabled
+	if ps.AlertNG == nil || ps.AlertNG.ProvisioningService == nil {
+		return nil
+	}
+	alertingPath := filepath.Join(ps.Cfg.ProvisioningPath, "alerting")
+	err := ps.provisionAlerting(ctx, alertingPath, ps.AlertNG.ProvisioningService)
+	return errutil.Wrap("Alerting provisioning error", err)
+}
+
 func (ps *ProvisioningServiceImpl) ProvisionDashboards(ctx context.Context) error {
 	dashboardPath := filepath.Join(ps.Cfg.ProvisioningPath, "dashboards")
 	dashProvisioner, err := ps.newDashboardProvisioner(dashboardPath, ps.SQLStore)
@@ -98,6 +98,7 @@ func setup() *serviceTestStruct {
 		nil,
 		nil,
 		nil,
+		nil,
 	)
 	serviceTest.service.Cfg = setting.NewCfg()
 
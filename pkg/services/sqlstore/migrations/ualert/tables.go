@@ -22,6 +22,18 @@ func AddTablesMigrations(mg *migrator.Migrator) {
 
 	// Create Admin Configuration
 	AddAlertAdminConfigMigrations(mg)
+
+	// Create alert_state_history table
+	AddAlertStateHistoryMigrations(mg)
+
+	// Create provenance_type table
+	AddProvisioningMigrations(mg)
+
+	// Create alert_image table
+	AddAlertImageMigrations(mg)
+
+	// Create alert_notification_delivery table
+	AddNotificationDeliveryMigrations(mg)
 }
 
 // AddAlertDefinitionMigrations should not be modified.
@@ -231,6 +243,20 @@ func AddAlertRuleMigrations(mg *migrator.Migrator, defaultIntervalSeconds int64)
 			Cols: []string{"org_id", "dashboard_uid", "panel_id"},
 		},
 	))
+
+	// add is_paused column
+	mg.AddMigration("add column is_paused to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "is_paused", Type: migrator.DB_Bool, Nullable: false, Default: "0"}))
+
+	// add record columns
+	mg.AddMigration("add column record to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "record", Type: migrator.DB_NVarchar, Length: 190, Nullable: false, Default: "''"}))
+	mg.AddMigration("add column record_target to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "record_target", Type: migrator.DB_NVarchar, Length: 40, Nullable: false, Default: "''"}))
+
+	// add suppression columns
+	mg.AddMigration("add column suppressed_by_rule_uids to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "suppressed_by_rule_uids", Type: migrator.DB_Text, Nullable: true}))
+	mg.AddMigration("add column suppressed_by_labels to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "suppressed_by_labels", Type: migrator.DB_Text, Nullable: true}))
+
+	// add is_group_paused column
+	mg.AddMigration("add column is_group_paused to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "is_group_paused", Type: migrator.DB_Bool, Nullable: false, Default: "0"}))
 }
 
 func AddAlertRuleVersionMigrations(mg *migrator.Migrator) {
@@ -274,6 +300,20 @@ func AddAlertRuleVersionMigrations(mg *migrator.Migrator) {
 
 	// add labels column
 	mg.AddMigration("add column labels to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "labels", Type: migrator.DB_Text, Nullable: true}))
+
+	// add is_paused column
+	mg.AddMigration("add column is_paused to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "is_paused", Type: migrator.DB_Bool, Nullable: false, Default: "0"}))
+
+	// add record columns
+	mg.AddMigration("add column record to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "record", Type: migrator.DB_NVarchar, Length: 190, Nullable: false, Default: "''"}))
+	mg.AddMigration("add column record_target to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "record_target", Type: migrator.DB_NVarchar, Length: 40, Nullable: false, Default: "''"}))
+
+	// add suppression columns
+	mg.AddMigration("add column suppressed_by_rule_uids to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "suppressed_by_rule_uids", Type: migrator.DB_Text, Nullable: true}))
+	mg.AddMigration("add column suppressed_by_labels to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "suppressed_by_labels", Type: migrator.DB_Text, Nullable: true}))
+
+	// add is_group_paused column
+	mg.AddMigration("add column is_group_paused to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "is_group_paused", Type: migrator.DB_Bool, Nullable: false, Default: "0"}))
 }
 
 func AddAlertmanagerConfigMigrations(mg *migrator.Migrator) {
@@ -324,3 +364,102 @@ func AddAlertAdminConfigMigrations(mg *migrator.Migrator) {
 	mg.AddMigration("create_ngalert_configuration_table", migrator.NewAddTableMigration(adminConfiguration))
 	mg.AddMigration("add index in ngalert_configuration on org_id column", migrator.NewAddIndexMigration(adminConfiguration, adminConfiguration.Indices[0]))
 }
+
+func AddAlertStateHistoryMigrations(mg *migrator.Migrator) {
+	alertStateHistory := migrator.Table{
+		Name: "alert_state_history",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "rule_org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "labels_hash", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "previous_state", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "current_state", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "reason", Type: migrator.DB_Text, Nullable: true},
+			{Name: "evaluation_values", Type: migrator.DB_Text, Nullable: true},
+			{Name: "evaluated_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"rule_org_id", "rule_uid", "evaluated_at"}, Type: migrator.IndexType},
+			{Cols: []string{"rule_org_id", "evaluated_at"}, Type: migrator.IndexType},
+			{Cols: []string{"evaluated_at"}, Type: migrator.IndexType},
+		},
+	}
+
+	// create table
+	mg.AddMigration("create alert_state_history table", migrator.NewAddTableMigration(alertStateHistory))
+	mg.AddMigration("add index in alert_state_history on rule_org_id, rule_uid and evaluated_at columns", migrator.NewAddIndexMigration(alertStateHistory, alertStateHistory.Indices[0]))
+	mg.AddMigration("add index in alert_state_history on rule_org_id and evaluated_at columns", migrator.NewAddIndexMigration(alertStateHistory, alertStateHistory.Indices[1]))
+	mg.AddMigration("add index in alert_state_history on evaluated_at column", migrator.NewAddIndexMigration(alertStateHistory, alertStateHistory.Indices[2]))
+}
+
+func AddProvisioningMigrations(mg *migrator.Migrator) {
+	provenanceType := migrator.Table{
+		Name: "provenance_type",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "record_key", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "record_type", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "provenance", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"record_type", "record_key", "org_id"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create provenance_type table", migrator.NewAddTableMigration(provenanceType))
+	mg.AddMigration("add index in provenance_type on record_type, record_key and org_id columns", migrator.NewAddIndexMigration(provenanceType, provenanceType.Indices[0]))
+}
+
+func AddAlertImageMigrations(mg *migrator.Migrator) {
+	alertImage := migrator.Table{
+		Name: "alert_image",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "token", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "path", Type: migrator.DB_Text, Nullable: false},
+			{Name: "url", Type: migrator.DB_Text, Nullable: false},
+			{Name: "created_at", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "expires_at", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"token"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"expires_at"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_image table", migrator.NewAddTableMigration(alertImage))
+	mg.AddMigration("add unique index in alert_image on token column", migrator.NewAddIndexMigration(alertImage, alertImage.Indices[0]))
+	mg.AddMigration("add index in alert_image on expires_at column", migrator.NewAddIndexMigration(alertImage, alertImage.Indices[1]))
+}
+
+func AddNotificationDeliveryMigrations(mg *migrator.Migrator) {
+	notificationDelivery := migrator.Table{
+		Name: "alert_notification_delivery",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "receiver", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "integration_type", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "integration_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "group_key", Type: migrator.DB_Text, Nullable: false},
+			{Name: "alerts", Type: migrator.DB_Int, Nullable: false},
+			{Name: "attempt", Type: migrator.DB_Int, Nullable: false},
+			{Name: "status", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "status_code", Type: migrator.DB_Int, Nullable: false},
+			{Name: "error", Type: migrator.DB_Text, Nullable: true},
+			{Name: "duration_ms", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "sent_at", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "receiver", "sent_at"}, Type: migrator.IndexType},
+			{Cols: []string{"sent_at"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_notification_delivery table", migrator.NewAddTableMigration(notificationDelivery))
+	mg.AddMigration("add index in alert_notification_delivery on org_id, receiver and sent_at columns", migrator.NewAddIndexMigration(notificationDelivery, notificationDelivery.Indices[0]))
+	mg.AddMigration("add index in alert_notification_delivery on sent_at column", migrator.NewAddIndexMigration(notificationDelivery, notificationDelivery.Indices[1]))
+}
@@ -474,15 +474,15 @@ func (m *migration) validateAlertmanagerConfig(orgID int64, config *PostableUser
 
 			switch gr.Type {
 			case "email":
-				_, err = channels.NewEmailNotifier(cfg, nil) // Email notifier already has a default template.
+				_, err = channels.NewEmailNotifier(cfg, nil, nil) // Email notifier already has a default template.
 			case "pagerduty":
 				_, err = channels.NewPagerdutyNotifier(cfg, nil, decryptFunc)
 			case "pushover":
-				_, err = channels.NewPushoverNotifier(cfg, nil, decryptFunc)
+				_, err = channels.NewPushoverNotifier(cfg, nil, nil, decryptFunc)
 			case "slack":
-				_, err = channels.NewSlackNotifier(cfg, nil, decryptFunc)
+				_, err = channels.NewSlackNotifier(cfg, nil, decryptFunc, nil)
 			case "telegram":
-				_, err = channels.NewTelegramNotifier(cfg, nil, decryptFunc)
+				_, err = channels.NewTelegramNotifier(cfg, nil, nil, decryptFunc)
 			case "victorops":
 				_, err = channels.NewVictoropsNotifier(cfg, nil)
 			case "teams":
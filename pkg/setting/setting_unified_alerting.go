@@ -47,6 +47,13 @@ const (
 	schedulerDefaultMaxAttempts             = 3
 	schedulerDefaultLegacyMinInterval       = 1
 	schedulerDefaultMinInterval             = 10 * time.Second
+	evaluatorDefaultQueryCacheTTL           = time.Duration(0)
+	stateHistoryDefaultEnabled              = true
+	stateHistoryDefaultMaxAge               = 30 * 24 * time.Hour
+	screenshotsDefaultCaptureTimeout        = 10 * time.Second
+	screenshotsDefaultMaxConcurrent         = 5
+	deliveryLogDefaultEnabled               = true
+	deliveryLogDefaultMaxAge                = 7 * 24 * time.Hour
 )
 
 type UnifiedAlertingSettings struct {
@@ -58,13 +65,40 @@ type UnifiedAlertingSettings struct {
 	HAPeerTimeout                  time.Duration
 	HAGossipInterval               time.Duration
 	HAPushPullInterval             time.Duration
+	HAShardRuleEvaluation          bool
 	MaxAttempts                    int64
 	MinInterval                    time.Duration
 	EvaluationTimeout              time.Duration
+	QueryCacheTTL                  time.Duration
 	ExecuteAlerts                  bool
 	DefaultConfiguration           string
 	Enabled                        bool
 	DisabledOrgs                   map[int64]struct{}
+	StateHistoryEnabled            bool
+	StateHistoryMaxAge             time.Duration
+	// RecordingRulesRemoteWriteURL is the Prometheus remote write endpoint that
+	// recording rules write their time series to.
+	RecordingRulesRemoteWriteURL      string
+	RecordingRulesRemoteWriteUser     string
+	RecordingRulesRemoteWritePassword string
+	// EvaluationJitter spreads the evaluations of alert rules over their interval.
+	EvaluationJitter bool
+	// MaxConcurrentEvaluations limits the alert rules evaluated at the same time, in
+	// total, per organization and per data source. Zero means no limit.
+	MaxConcurrentEvaluations              int
+	MaxConcurrentEvaluationsPerOrg        int
+	MaxConcurrentEvaluationsPerDatasource int
+	// ScreenshotsCapture takes a screenshot of the panel of an alert rule when its alerts
+	// start firing, which is attached to their notifications. It requires the image
+	// renderer.
+	ScreenshotsCapture                    bool
+	ScreenshotsCaptureTimeout             time.Duration
+	ScreenshotsMaxConcurrent              int
+	ScreenshotsUploadExternalImageStorage bool
+	// DeliveryLogEnabled records each attempt of the contact points to send a
+	// notification, which can be queried with the delivery log API.
+	DeliveryLogEnabled bool
+	DeliveryLogMaxAge  time.Duration
 }
 
 // ReadUnifiedAlertingSettings reads both the `unified_alerting` and `alerting` sections of the configuration while preferring configuration the `alerting` section.
@@ -126,6 +160,7 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 			uaCfg.HAPeers = append(uaCfg.HAPeers, peer)
 		}
 	}
+	uaCfg.HAShardRuleEvaluation = ua.Key("ha_shard_rule_evaluation").MustBool(false)
 
 	// TODO load from ini file
 	uaCfg.DefaultConfiguration = alertmanagerDefaultConfiguration
@@ -153,6 +188,11 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	}
 	uaCfg.EvaluationTimeout = uaEvaluationTimeout
 
+	uaCfg.QueryCacheTTL, err = gtime.ParseDuration(valueAsString(ua, "query_cache_ttl", evaluatorDefaultQueryCacheTTL.String()))
+	if err != nil {
+		return err
+	}
+
 	uaMaxAttempts := ua.Key("max_attempts").MustInt64(schedulerDefaultMaxAttempts)
 	if uaMaxAttempts == schedulerDefaultMaxAttempts { // unified option or equals the default
 		legacyMaxAttempts := alerting.Key("max_attempts").MustInt64(schedulerDefaultMaxAttempts)
@@ -174,6 +214,35 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	}
 	uaCfg.MinInterval = uaMinInterval
 
+	uaCfg.EvaluationJitter = ua.Key("evaluation_jitter").MustBool(false)
+	uaCfg.MaxConcurrentEvaluations = ua.Key("max_concurrent_evaluations").MustInt(0)
+	uaCfg.MaxConcurrentEvaluationsPerOrg = ua.Key("max_concurrent_evaluations_per_org").MustInt(0)
+	uaCfg.MaxConcurrentEvaluationsPerDatasource = ua.Key("max_concurrent_evaluations_per_datasource").MustInt(0)
+
+	uaCfg.StateHistoryEnabled = ua.Key("state_history_enabled").MustBool(stateHistoryDefaultEnabled)
+	uaCfg.StateHistoryMaxAge, err = gtime.ParseDuration(valueAsString(ua, "state_history_max_age", stateHistoryDefaultMaxAge.String()))
+	if err != nil {
+		return err
+	}
+
+	uaCfg.RecordingRulesRemoteWriteURL = valueAsString(ua, "recording_rules_remote_write_url", "")
+	uaCfg.RecordingRulesRemoteWriteUser = valueAsString(ua, "recording_rules_remote_write_user", "")
+	uaCfg.RecordingRulesRemoteWritePassword = valueAsString(ua, "recording_rules_remote_write_password", "")
+
+	uaCfg.ScreenshotsCapture = ua.Key("screenshots_capture").MustBool(false)
+	uaCfg.ScreenshotsCaptureTimeout, err = gtime.ParseDuration(valueAsString(ua, "screenshots_capture_timeout", screenshotsDefaultCaptureTimeout.String()))
+	if err != nil {
+		return err
+	}
+	uaCfg.ScreenshotsMaxConcurrent = ua.Key("screenshots_max_concurrent").MustInt(screenshotsDefaultMaxConcurrent)
+	uaCfg.ScreenshotsUploadExternalImageStorage = ua.Key("screenshots_upload_external_image_storage").MustBool(false)
+
+	uaCfg.DeliveryLogEnabled = ua.Key("delivery_log_enabled").MustBool(deliveryLogDefaultEnabled)
+	uaCfg.DeliveryLogMaxAge, err = gtime.ParseDuration(valueAsString(ua, "delivery_log_max_age", deliveryLogDefaultMaxAge.String()))
+	if err != nil {
+		return err
+	}
+
 	cfg.UnifiedAlerting = uaCfg
 	return nil
 }
@@ -27,6 +27,16 @@ func stringsFallback(vals ...string) string {
 	return ""
 }
 
+// StringsContain returns true if values contains value.
+func StringsContain(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 // SplitString splits a string by commas or empty spaces.
 func SplitString(str string) []string {
 	if len(str) == 0 {
@@ -44,6 +44,22 @@ func TestStringsFallback3(t *testing.T) {
 	}
 }
 
+func TestStringsContain(t *testing.T) {
+	tests := []struct {
+		values   []string
+		value    string
+		expected bool
+	}{
+		{nil, "a", false},
+		{[]string{"a", "b"}, "b", true},
+		{[]string{"a", "b"}, "c", false},
+		{[]string{"a", ""}, "", true},
+	}
+	for _, testcase := range tests {
+		assert.Equal(t, testcase.expected, StringsContain(testcase.values, testcase.value))
+	}
+}
+
 func TestSplitString(t *testing.T) {
 	tests := map[string][]string{
 		"":                    {},
@@ -12,12 +12,14 @@ const options: SelectableValue[] = [
   { value: GrafanaAlertStateDecision.Alerting, label: 'Alerting' },
   { value: GrafanaAlertStateDecision.NoData, label: 'No Data' },
   { value: GrafanaAlertStateDecision.OK, label: 'OK' },
+  { value: GrafanaAlertStateDecision.Error, label: 'Error' },
+  { value: GrafanaAlertStateDecision.KeepLastState, label: 'Keep Last State' },
 ];
 
 export const GrafanaAlertStatePicker: FC<Props> = ({ includeNoData, ...props }) => {
   const opts = useMemo(() => {
     if (includeNoData) {
-      return options;
+      return options.filter((opt) => opt.value !== GrafanaAlertStateDecision.Error);
     }
     return options.filter((opt) => opt.value !== GrafanaAlertStateDecision.NoData);
   }, [includeNoData]);
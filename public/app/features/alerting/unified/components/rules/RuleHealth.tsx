@@ -11,8 +11,9 @@ interface Prom {
 export const RuleHealth: FC<Prom> = ({ rule }) => {
   const style = useStyles2(getStyle);
   if (rule.health === 'err' || rule.health === 'error') {
+    const message = rule.lastError || 'No error message provided.';
     return (
-      <Tooltip theme="error" content={rule.lastError || 'No error message provided.'}>
+      <Tooltip theme="error" content={rule.lastErrorRefId ? `Query ${rule.lastErrorRefId}: ${message}` : message}>
         <div className={style.warn}>
           <Icon name="exclamation-triangle" />
           <span>error</span>
@@ -30,8 +30,11 @@ export function useIsRuleEditable(rulesSourceName: string, rule?: RulerRuleDTO):
     return { isEditable: false, loading: false };
   }
 
-  // grafana rules can be edited if user can edit the folder they're in
+  // grafana rules can be edited if user can edit the folder they're in, unless they are provisioned from files
   if (isGrafanaRulerRule(rule)) {
+    if (rule.grafana_alert.provenance) {
+      return { isEditable: false, loading: false };
+    }
     if (!folderUID) {
       throw new Error(
         `Rule ${rule.grafana_alert.title} does not have a folder uid, cannot determine if it is editable.`
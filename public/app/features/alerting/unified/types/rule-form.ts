@@ -23,6 +23,7 @@ export interface RuleFormValues {
   folder: { title: string; id: number } | null;
   evaluateEvery: string;
   evaluateFor: string;
+  isPaused?: boolean;
 
   // cortex / loki rules
   namespace: string;
@@ -105,6 +105,7 @@ export type Route = {
   group_interval?: string;
   repeat_interval?: string;
   routes?: Route[];
+  mute_time_intervals?: string[];
 };
 
 export type InhibitRule = {
@@ -141,6 +142,25 @@ export type AlertmanagerConfig = {
   route?: Route;
   inhibit_rules?: InhibitRule[];
   receivers?: Receiver[];
+  mute_time_intervals?: MuteTimeInterval[];
+};
+
+export type TimeOfDayRange = {
+  start_time: string;
+  end_time: string;
+};
+
+export type TimeInterval = {
+  times?: TimeOfDayRange[];
+  weekdays?: string[];
+  days_of_month?: string[];
+  months?: string[];
+  years?: string[];
+};
+
+export type MuteTimeInterval = {
+  name: string;
+  time_intervals: TimeInterval[];
 };
 
 export type Matcher = {
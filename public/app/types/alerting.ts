@@ -51,6 +51,7 @@ export type GrafanaNotifierType =
   | 'opsgenie'
   | 'dingding'
   | 'webhook'
+  | 'http'
   | 'victorops'
   | 'pushover'
   | 'LINE'
@@ -64,6 +65,7 @@ export type CloudNotifierType =
   | 'opsgenie'
   | 'victorops'
   | 'webhook'
+  | 'http'
   | 'wechat';
 
 export type NotifierType = GrafanaNotifierType | CloudNotifierType;
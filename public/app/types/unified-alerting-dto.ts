@@ -31,6 +31,9 @@ interface PromRuleDTOBase {
   evaluationTime?: number;
   lastEvaluation?: string;
   lastError?: string;
+  lastErrorRefId?: string; // Grafana managed rules only
+  evaluationAttempts?: number;
+  consecutiveFailures?: number;
 }
 
 export interface PromAlertingRuleDTO extends PromRuleDTOBase {
@@ -40,6 +43,7 @@ export interface PromAlertingRuleDTO extends PromRuleDTOBase {
     state: Exclude<PromAlertingRuleState | GrafanaAlertState, PromAlertingRuleState.Inactive>;
     activeAt: string;
     value: string;
+    suppressedBy?: string;
   }>;
   labels: Labels;
   annotations?: Annotations;
@@ -99,6 +103,7 @@ export enum GrafanaAlertStateDecision {
   NoData = 'NoData',
   KeepLastState = 'KeepLastState',
   OK = 'OK',
+  Error = 'Error',
 }
 
 interface AlertDataQuery extends DataQuery {
@@ -121,11 +126,25 @@ export interface PostableGrafanaRuleDefinition {
   no_data_state: GrafanaAlertStateDecision;
   exec_err_state: GrafanaAlertStateDecision;
   data: AlertQuery[];
+  is_paused?: boolean;
+  record?: GrafanaRecordDefinition;
+  suppressed_by?: GrafanaSuppressedByDefinition;
+}
+
+export interface GrafanaRecordDefinition {
+  metric: string;
+  target: 'remote_write' | 'live';
+}
+
+export interface GrafanaSuppressedByDefinition {
+  rule_uids?: string[];
+  labels?: Labels;
 }
 export interface GrafanaRuleDefinition extends PostableGrafanaRuleDefinition {
   uid: string;
   namespace_uid: string;
   namespace_id: number;
+  provenance?: string;
 }
 
 export interface RulerGrafanaRuleDTO {
@@ -150,6 +169,7 @@ export type RulerRuleGroupDTO<R = RulerRuleDTO> = {
   name: string;
   interval?: string;
   rules: R[];
+  is_paused?: boolean;
 };
 
 export type PostableRulerRuleGroupDTO = RulerRuleGroupDTO<PostableRuleDTO>;
@@ -17,6 +17,7 @@ export type Alert = {
   labels: { [key: string]: string };
   state: PromAlertingRuleState | GrafanaAlertState;
   value: string;
+  suppressedBy?: string;
 };
 interface RuleBase {
   health: string;
@@ -25,6 +26,9 @@ interface RuleBase {
   lastEvaluation?: string;
   evaluationTime?: number;
   lastError?: string;
+  lastErrorRefId?: string; // Grafana managed rules only
+  evaluationAttempts?: number;
+  consecutiveFailures?: number;
 }
 
 export interface AlertingRule extends RuleBase {